@@ -8,38 +8,55 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // fusos de QUOTA_TIMEZONE disponíveis em imagens sem zoneinfo
 
+	"github.com/marfebr/go_ratelimit/internal/admin"
 	"github.com/marfebr/go_ratelimit/internal/config"
+	"github.com/marfebr/go_ratelimit/internal/jwtauth"
 	"github.com/marfebr/go_ratelimit/internal/limiter"
 	"github.com/marfebr/go_ratelimit/internal/middleware"
+	"github.com/marfebr/go_ratelimit/internal/registry"
 )
 
 func main() {
 	// Carrega configuração
-	cfg, err := config.LoadConfig()
+	cfg, err := config.LoadConfigArgs(os.Args[1:])
 	if err != nil {
 		log.Fatalf("Erro ao carregar configuração: %v", err)
 	}
 
 	log.Printf("Configuração carregada:")
 	log.Printf("  Redis: %s", cfg.RedisAddr)
-	log.Printf("  Rate Limit IP: %d req/s", cfg.DefaultRateLimitIP)
+	log.Printf("  Rate Limit IP: %d req/%s", cfg.DefaultRateLimitIP, cfg.DefaultWindowIP)
 	log.Printf("  Block Duration IP: %d segundos", cfg.DefaultBlockDurationIP)
 	log.Printf("  Tokens configurados: %d", len(cfg.TokenLimits))
+	log.Printf("  Planos configurados: %d", len(cfg.Plans))
 
 	// Inicializa Redis Store
 	redisStore, err := limiter.NewRedisStore(cfg.RedisAddr)
 	if err != nil {
 		log.Fatalf("Erro ao conectar ao Redis: %v", err)
 	}
-	defer redisStore.Close()
 
 	log.Println("Conectado ao Redis com sucesso")
 
 	// Cria Core Limiter
 	coreLimiter := limiter.NewCoreLimiter(redisStore)
+	// Close do limiter fecha o redisStore, compartilhado com as cotas
 	defer coreLimiter.Close()
 
+	// Comportamento com o Redis indisponível
+	if err := coreLimiter.SetFailurePolicy(limiter.FailurePolicy(cfg.StoreFailure.Policy), cfg.StoreFailure.LocalPercent); err != nil {
+		log.Fatalf("Erro ao configurar política de falha: %v", err)
+	}
+	log.Printf("  Política de falha do store: %s", cfg.StoreFailure.Policy)
+
+	// Cotas de longo prazo com reinício no calendário do fuso configurado
+	quotaManager := limiter.NewQuotaManager(redisStore, cfg.QuotaTimezone)
+	if err := quotaManager.SetFailurePolicy(limiter.FailurePolicy(cfg.StoreFailure.Policy), cfg.StoreFailure.LocalPercent); err != nil {
+		log.Fatalf("Erro ao configurar política de falha das cotas: %v", err)
+	}
+
 	// Configura rotas
 	mux := http.NewServeMux()
 	
@@ -57,8 +74,57 @@ func main() {
 		w.Write([]byte(`{"status": "healthy"}`))
 	})
 
+	middlewareOpts := []middleware.Option{middleware.WithQuotaManager(quotaManager)}
+
+	// Limites por claims de tokens JWT
+	if cfg.JWT.Enabled() {
+		verifier, err := jwtauth.LoadVerifier(cfg.JWT.Secret, cfg.JWT.PublicKeyFile, cfg.JWT.JWKSFile)
+		if err != nil {
+			log.Fatalf("Erro ao carregar chaves JWT: %v", err)
+		}
+		verifier.RequireIssuer(cfg.JWT.Issuer)
+		verifier.RequireAudience(cfg.JWT.Audience)
+		verifier.RequireExpiration(cfg.JWT.RequireExp)
+		middlewareOpts = append(middlewareOpts, middleware.WithJWTVerifier(verifier))
+		log.Printf("  Chaves JWT: %d, planos: %d", verifier.Len(), len(cfg.JWT.Plans))
+	}
+
+	// Recarga a quente dos limites: SIGHUP ou alteração do arquivo de configuração
+	reloader := config.NewReloader(cfg, func() (*config.Config, error) {
+		return config.LoadConfigArgs(os.Args[1:])
+	})
+	reloadCtx, stopReload := context.WithCancel(context.Background())
+	defer stopReload()
+	go reloader.WatchSignals(reloadCtx, syscall.SIGHUP)
+	if cfg.ConfigFile != "" {
+		go reloader.WatchFile(reloadCtx, cfg.ConfigFile, 2*time.Second)
+	}
+	middlewareOpts = append(middlewareOpts, middleware.WithConfigSource(reloader.Current))
+
+	// Limites por token criados em tempo de execução (hash no Redis), antes de API_KEY_<TOKEN>
+	tokenRegistry := registry.New(registry.NewRedisStore(redisStore.Client()), reloader.Current)
+	if err := tokenRegistry.Load(reloadCtx); err != nil {
+		log.Printf("Erro ao carregar tokens dinâmicos: %v", err)
+	}
+	go tokenRegistry.Watch(reloadCtx)
+	middlewareOpts = append(middlewareOpts, middleware.WithTokenRegistry(tokenRegistry))
+	log.Printf("  Tokens dinâmicos: %d", len(tokenRegistry.List()))
+
 	// Aplica middleware de rate limiting
-	handler := middleware.RateLimitMiddleware(coreLimiter, cfg)(mux)
+	handler := middleware.RateLimitMiddleware(coreLimiter, cfg, middlewareOpts...)(mux)
+
+	// API administrativa fora do rate limiting, habilitada apenas com ADMIN_TOKEN
+	if cfg.AdminToken != "" {
+		root := http.NewServeMux()
+		root.Handle("/admin/", admin.NewHandler(coreLimiter, cfg.AdminToken, func(key string) ([]limiter.Rule, error) {
+			return middleware.KeyRules(reloader.Current(), tokenRegistry, key)
+		}, admin.WithTokenRegistry(tokenRegistry), admin.WithStateRules(func(key string) []limiter.Rule {
+			return middleware.KeyStateRules(reloader.Current(), tokenRegistry, key)
+		})))
+		root.Handle("/", handler)
+		handler = root
+		log.Println("API administrativa habilitada em /admin/")
+	}
 
 	// Configura servidor
 	server := &http.Server{
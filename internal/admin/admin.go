@@ -0,0 +1,206 @@
+package admin
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"expvar"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/marfebr/go_ratelimit/internal/limiter"
+	"github.com/marfebr/go_ratelimit/internal/registry"
+)
+
+// RuleResolver retorna as regras aplicadas a uma chave de rate limit (ex.: ip:1.2.3.4, token:abc123)
+// Um erro indica que as regras da chave não podem ser determinadas (ex.: o plano de uma chave jwt:)
+type RuleResolver func(key string) ([]limiter.Rule, error)
+
+// StateRuleResolver retorna todas as regras que podem ter estado para a chave, usadas no reinício
+type StateRuleResolver func(key string) []limiter.Rule
+
+// Handler expõe a API administrativa do rate limiter sob /admin/
+// Todas as rotas exigem o header Authorization: Bearer <ADMIN_TOKEN>
+type Handler struct {
+	limiter  *limiter.CoreLimiter
+	rules    RuleResolver
+	token    string
+	mux      *http.ServeMux
+	registry *registry.Registry
+	// stateRules, se definido, substitui rules no reinício
+	stateRules StateRuleResolver
+}
+
+// Option configura recursos opcionais da API administrativa
+type Option func(*Handler)
+
+// WithTokenRegistry habilita a gestão dos limites dinâmicos por token em /admin/tokens
+func WithTokenRegistry(tokenRegistry *registry.Registry) Option {
+	return func(h *Handler) {
+		h.registry = tokenRegistry
+	}
+}
+
+// WithStateRules define as regras zeradas por /admin/reset, permitindo reiniciar
+// chaves cujas regras não podem ser determinadas pelo RuleResolver
+func WithStateRules(stateRules StateRuleResolver) Option {
+	return func(h *Handler) {
+		h.stateRules = stateRules
+	}
+}
+
+// NewHandler cria a API administrativa autenticada pelo token informado
+func NewHandler(coreLimiter *limiter.CoreLimiter, token string, rules RuleResolver, opts ...Option) *Handler {
+	h := &Handler{
+		limiter: coreLimiter,
+		rules:   rules,
+		token:   token,
+		mux:     http.NewServeMux(),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	h.mux.HandleFunc("GET /admin/status", h.status)
+	h.mux.HandleFunc("POST /admin/unblock", h.unblock)
+	h.mux.HandleFunc("POST /admin/reset", h.reset)
+	h.mux.HandleFunc("POST /admin/block", h.block)
+	h.mux.HandleFunc("GET /admin/blocked", h.blocked)
+	// Métricas expvar do rate limiter (ex.: recargas de configuração)
+	h.mux.HandleFunc("GET /admin/vars", h.vars)
+	if h.registry != nil {
+		h.mux.HandleFunc("GET /admin/tokens", h.listTokens)
+		h.mux.HandleFunc("GET /admin/tokens/{token}", h.getToken)
+		h.mux.HandleFunc("PUT /admin/tokens/{token}", h.putToken)
+		h.mux.HandleFunc("DELETE /admin/tokens/{token}", h.deleteToken)
+	}
+	return h
+}
+
+// ServeHTTP autentica a requisição e a encaminha para a rota administrativa
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(r) {
+		writeJSON(w, http.StatusUnauthorized, message{Message: "unauthorized"})
+		return
+	}
+	h.mux.ServeHTTP(w, r)
+}
+
+// authorized compara o bearer token em tempo constante
+func (h *Handler) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && h.token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) == 1
+}
+
+// message é o corpo JSON de respostas de erro
+type message struct {
+	Message string `json:"message"`
+}
+
+// statusResponse é o estado de uma chave em todas as suas regras
+type statusResponse struct {
+	Key          string     `json:"key"`
+	Blocked      bool       `json:"blocked"`
+	BlockedUntil *time.Time `json:"blocked_until,omitempty"`
+	// BlockTTL é o tempo restante do bloqueio em segundos
+	BlockTTL int64         `json:"block_ttl_seconds,omitempty"`
+	Limits   []limitStatus `json:"limits"`
+	// Offences são as infrações recentes contadas pelo bloqueio progressivo
+	Offences int64 `json:"offences,omitempty"`
+}
+
+// limitStatus é o estado de uma chave em uma regra
+type limitStatus struct {
+	Rule      string     `json:"rule"`
+	Algorithm string     `json:"algorithm"`
+	Count     int64      `json:"count"`
+	Remaining int64      `json:"remaining"`
+	ResetAt   *time.Time `json:"reset_at,omitempty"`
+}
+
+// status consulta o estado de uma chave sem consumir capacidade
+// GET /admin/status?key=ip:1.2.3.4
+func (h *Handler) status(w http.ResponseWriter, r *http.Request) {
+	key, ok := requireKey(w, r)
+	if !ok {
+		return
+	}
+
+	rules, err := h.rules(key)
+	if err != nil {
+		writeJSON(w, http.StatusUnprocessableEntity, message{Message: err.Error()})
+		return
+	}
+
+	statuses, err := h.limiter.StatusRules(r.Context(), key, rules)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, message{Message: err.Error()})
+		return
+	}
+
+	offences, err := h.limiter.Offences(r.Context(), key)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, message{Message: err.Error()})
+		return
+	}
+
+	resp := statusResponse{Key: key, Offences: offences, Limits: make([]limitStatus, 0, len(statuses))}
+	for _, s := range statuses {
+		// O bloqueio é compartilhado por todas as regras da chave
+		resp.Blocked = s.Blocked
+		resp.BlockedUntil = optionalTime(s.BlockedUntil)
+		if !s.BlockedUntil.IsZero() {
+			resp.BlockTTL = int64(math.Ceil(time.Until(s.BlockedUntil).Seconds()))
+		}
+		resp.Limits = append(resp.Limits, limitStatus{
+			Rule:      s.Rule.String(),
+			Algorithm: string(s.Rule.Algorithm),
+			Count:     s.Count,
+			Remaining: s.Remaining,
+			ResetAt:   optionalTime(s.ResetAt),
+		})
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// metricVars são as métricas expvar expostas em /admin/vars. As demais variáveis
+// do processo ficam de fora: cmdline, por exemplo, inclui segredos passados com -set.
+var metricVars = []string{"config_reload", "access_list", "store_failure"}
+
+// vars responde as métricas expvar do rate limiter
+// GET /admin/vars
+func (h *Handler) vars(w http.ResponseWriter, r *http.Request) {
+	body := make(map[string]json.RawMessage, len(metricVars))
+	for _, name := range metricVars {
+		if v := expvar.Get(name); v != nil {
+			body[name] = json.RawMessage(v.String())
+		}
+	}
+	writeJSON(w, http.StatusOK, body)
+}
+
+// requireKey lê o parâmetro key, respondendo 400 se ausente
+func requireKey(w http.ResponseWriter, r *http.Request) (string, bool) {
+	key := r.URL.Query().Get("key")
+	if key == "" {
+		writeJSON(w, http.StatusBadRequest, message{Message: "query parameter key is required"})
+		return "", false
+	}
+	return key, true
+}
+
+// optionalTime omite instantes zero no JSON
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// writeJSON escreve a resposta JSON com o status informado
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,126 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marfebr/go_ratelimit/internal/limiter"
+)
+
+func newTestHandler(t *testing.T) (*Handler, *limiter.CoreLimiter) {
+	t.Helper()
+	coreLimiter := limiter.NewCoreLimiter(limiter.NewMemoryStore())
+	rules := func(key string) ([]limiter.Rule, error) {
+		// O plano de chaves jwt: vem do token
+		if strings.HasPrefix(key, "jwt:") {
+			return nil, errors.New("regras da chave não podem ser determinadas")
+		}
+		return []limiter.Rule{{Limit: 2, Window: time.Minute, BlockDuration: time.Minute}}, nil
+	}
+	return NewHandler(coreLimiter, "secret", rules), coreLimiter
+}
+
+func doRequest(h http.Handler, target, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", target, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func TestHandler_Unauthorized(t *testing.T) {
+	// Setup
+	h, _ := newTestHandler(t)
+
+	// Execute / Assert
+	for _, token := range []string{"", "wrong"} {
+		w := doRequest(h, "/admin/status?key=ip:1.2.3.4", token)
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("Token %q: status esperado 401, obtido %d", token, w.Code)
+		}
+	}
+}
+
+func TestHandler_StatusMissingKey(t *testing.T) {
+	// Setup
+	h, _ := newTestHandler(t)
+
+	// Execute
+	w := doRequest(h, "/admin/status", "secret")
+
+	// Assert
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Status esperado 400, obtido %d", w.Code)
+	}
+}
+
+func TestHandler_Status(t *testing.T) {
+	// Setup
+	h, coreLimiter := newTestHandler(t)
+	ctx := context.Background()
+	rule := limiter.Rule{Limit: 2, Window: time.Minute, Escalation: limiter.Escalation{Steps: []time.Duration{time.Minute}}}
+	for i := 0; i < 3; i++ {
+		coreLimiter.AllowRule(ctx, "ip:1.2.3.4", rule)
+	}
+
+	// Execute
+	w := doRequest(h, "/admin/status?key=ip:1.2.3.4", "secret")
+
+	// Assert
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status esperado 200, obtido %d", w.Code)
+	}
+	var resp statusResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Erro ao decodificar resposta: %v", err)
+	}
+	if resp.Key != "ip:1.2.3.4" || !resp.Blocked || resp.BlockedUntil == nil || resp.BlockTTL != 60 || resp.Offences != 1 {
+		t.Errorf("Esperava chave bloqueada com expiração, obtido %+v", resp)
+	}
+	if len(resp.Limits) != 1 || resp.Limits[0].Count != 3 || resp.Limits[0].Remaining != 0 {
+		t.Errorf("Limites inesperados: %+v", resp.Limits)
+	}
+
+	// O status não consome capacidade
+	w = doRequest(h, "/admin/status?key=ip:1.2.3.4", "secret")
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.Limits[0].Count != 3 {
+		t.Errorf("Consulta não deveria consumir, contagem %d", resp.Limits[0].Count)
+	}
+}
+
+func TestHandler_Vars(t *testing.T) {
+	// Setup
+	h, _ := newTestHandler(t)
+
+	// Execute
+	w := doRequest(h, "/admin/vars", "secret")
+
+	// Assert
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status esperado 200, obtido %d", w.Code)
+	}
+	var vars map[string]json.RawMessage
+	if err := json.Unmarshal(w.Body.Bytes(), &vars); err != nil {
+		t.Fatalf("Erro ao decodificar resposta: %v", err)
+	}
+	for _, name := range []string{"config_reload", "store_failure"} {
+		if _, ok := vars[name]; !ok {
+			t.Errorf("Métrica %s ausente: %s", name, w.Body.String())
+		}
+	}
+	// A linha de comando pode conter segredos (-set JWT_SECRET=...)
+	for _, name := range []string{"cmdline", "memstats"} {
+		if _, ok := vars[name]; ok {
+			t.Errorf("Variável %s não deveria ser exposta", name)
+		}
+	}
+}
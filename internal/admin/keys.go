@@ -0,0 +1,154 @@
+package admin
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/marfebr/go_ratelimit/internal/limiter"
+)
+
+// keyResponse é o resultado de uma operação sobre uma chave
+type keyResponse struct {
+	Key          string     `json:"key"`
+	Unblocked    *bool      `json:"unblocked,omitempty"`
+	BlockedUntil *time.Time `json:"blocked_until,omitempty"`
+}
+
+// unblock remove o bloqueio de uma chave, mantendo seus contadores
+// POST /admin/unblock?key=ip:1.2.3.4
+func (h *Handler) unblock(w http.ResponseWriter, r *http.Request) {
+	key, ok := requireKey(w, r)
+	if !ok {
+		return
+	}
+
+	unblocked, err := h.limiter.Unblock(r.Context(), key)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, message{Message: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, keyResponse{Key: key, Unblocked: &unblocked})
+}
+
+// reset zera os contadores de uma chave em todas as suas regras e remove o bloqueio
+// POST /admin/reset?key=ip:1.2.3.4
+func (h *Handler) reset(w http.ResponseWriter, r *http.Request) {
+	key, ok := requireKey(w, r)
+	if !ok {
+		return
+	}
+
+	var rules []limiter.Rule
+	if h.stateRules != nil {
+		rules = h.stateRules(key)
+	} else {
+		var err error
+		if rules, err = h.rules(key); err != nil {
+			writeJSON(w, http.StatusUnprocessableEntity, message{Message: err.Error()})
+			return
+		}
+	}
+
+	if err := h.limiter.Reset(r.Context(), key, rules); err != nil {
+		writeJSON(w, http.StatusInternalServerError, message{Message: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, keyResponse{Key: key})
+}
+
+// block bloqueia uma chave manualmente pela duração informada (ex.: 30s, 10m, 2h)
+// POST /admin/block?key=ip:1.2.3.4&duration=10m
+func (h *Handler) block(w http.ResponseWriter, r *http.Request) {
+	key, ok := requireKey(w, r)
+	if !ok {
+		return
+	}
+	duration, err := time.ParseDuration(r.URL.Query().Get("duration"))
+	if err != nil || duration <= 0 {
+		writeJSON(w, http.StatusBadRequest, message{Message: "query parameter duration must be a positive duration (e.g. 10m)"})
+		return
+	}
+
+	if err := h.limiter.Block(r.Context(), key, duration); err != nil {
+		writeJSON(w, http.StatusInternalServerError, message{Message: err.Error()})
+		return
+	}
+	blockedUntil := time.Now().Add(duration)
+	writeJSON(w, http.StatusOK, keyResponse{Key: key, BlockedUntil: &blockedUntil})
+}
+
+// keyTypes são os tipos de chave aceitos no filtro de /admin/blocked
+var keyTypes = map[string]bool{"ip": true, "token": true, "route": true, "jwt": true}
+
+const (
+	defaultPageSize = 100
+	maxPageSize     = 1000
+)
+
+// blockedKey é uma chave bloqueada no momento
+type blockedKey struct {
+	Key          string     `json:"key"`
+	Type         string     `json:"type"`
+	Rule         string     `json:"rule,omitempty"`
+	BlockedUntil *time.Time `json:"blocked_until,omitempty"`
+	TTL          int64      `json:"ttl_seconds,omitempty"`
+}
+
+// blockedResponse é uma página da listagem de chaves bloqueadas
+type blockedResponse struct {
+	Blocked []blockedKey `json:"blocked"`
+	// NextCursor continua a listagem (vazio: fim); é opaco e específico do store
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// blocked lista as chaves bloqueadas, com o limite que causou o bloqueio e o tempo restante
+// Uma página pode vir vazia antes do fim: a listagem termina quando next_cursor está ausente
+// GET /admin/blocked?type=ip&cursor=<next_cursor>&limit=100
+func (h *Handler) blocked(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	prefix := ""
+	if keyType := query.Get("type"); keyType != "" {
+		if !keyTypes[keyType] {
+			writeJSON(w, http.StatusBadRequest, message{Message: "query parameter type must be ip, token, route or jwt"})
+			return
+		}
+		prefix = keyType + ":"
+	}
+
+	limit := defaultPageSize
+	if value := query.Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > maxPageSize {
+			writeJSON(w, http.StatusBadRequest, message{Message: "query parameter limit must be between 1 and 1000"})
+			return
+		}
+		limit = n
+	}
+
+	keys, next, err := h.limiter.BlockedKeys(r.Context(), prefix, query.Get("cursor"), int64(limit))
+	if errors.Is(err, limiter.ErrInvalidCursor) {
+		writeJSON(w, http.StatusBadRequest, message{Message: "query parameter cursor is invalid"})
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, message{Message: err.Error()})
+		return
+	}
+
+	resp := blockedResponse{Blocked: make([]blockedKey, 0, len(keys))}
+	for _, k := range keys {
+		keyType, _, _ := strings.Cut(k.Key, ":")
+		entry := blockedKey{Key: k.Key, Type: keyType, Rule: k.Rule, BlockedUntil: optionalTime(k.BlockedUntil)}
+		if !k.BlockedUntil.IsZero() {
+			entry.TTL = int64(math.Ceil(time.Until(k.BlockedUntil).Seconds()))
+		}
+		resp.Blocked = append(resp.Blocked, entry)
+	}
+	resp.NextCursor = next
+	writeJSON(w, http.StatusOK, resp)
+}
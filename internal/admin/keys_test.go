@@ -0,0 +1,166 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marfebr/go_ratelimit/internal/limiter"
+)
+
+func TestHandler_Unblock(t *testing.T) {
+	// Setup - chave bloqueada por exceder o limite
+	h, coreLimiter := newTestHandler(t)
+	ctx := context.Background()
+	rule := limiter.Rule{Limit: 2, Window: time.Minute, BlockDuration: time.Minute}
+	for i := 0; i < 3; i++ {
+		coreLimiter.AllowRule(ctx, "ip:1.2.3.4", rule)
+	}
+
+	// Execute
+	w := doTokenRequest(h, "POST", "/admin/unblock?key=ip:1.2.3.4", "")
+
+	// Assert
+	var resp keyResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+	if w.Code != http.StatusOK || resp.Unblocked == nil || !*resp.Unblocked {
+		t.Fatalf("Esperado 200 com unblocked=true, obtido %d %+v", w.Code, resp)
+	}
+	status, _ := coreLimiter.Status(ctx, "ip:1.2.3.4", rule)
+	if status.Blocked {
+		t.Error("Chave deveria estar desbloqueada")
+	}
+	if status.Count != 3 {
+		t.Errorf("Unblock deveria manter o contador, obtido %d", status.Count)
+	}
+}
+
+func TestHandler_Reset(t *testing.T) {
+	// Setup
+	h, coreLimiter := newTestHandler(t)
+	ctx := context.Background()
+	rule := limiter.Rule{Limit: 2, Window: time.Minute, BlockDuration: time.Minute}
+	for i := 0; i < 3; i++ {
+		coreLimiter.AllowRule(ctx, "ip:1.2.3.4", rule)
+	}
+
+	// Execute
+	w := doTokenRequest(h, "POST", "/admin/reset?key=ip:1.2.3.4", "")
+
+	// Assert
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status esperado 200, obtido %d", w.Code)
+	}
+	status, _ := coreLimiter.Status(ctx, "ip:1.2.3.4", rule)
+	if status.Blocked || status.Count != 0 {
+		t.Errorf("Chave deveria estar zerada, obtido %+v", status)
+	}
+}
+
+func TestHandler_JWTKey(t *testing.T) {
+	// Setup - chave jwt: cujo plano (10/1h) não faz parte do nome
+	coreLimiter := limiter.NewCoreLimiter(limiter.NewMemoryStore())
+	ctx := context.Background()
+	ipRule := limiter.Rule{Limit: 2, Window: time.Second, BlockDuration: time.Minute}
+	planRule := limiter.Rule{Limit: 10, Window: time.Hour, BlockDuration: time.Minute}
+	rules := func(key string) ([]limiter.Rule, error) {
+		if strings.HasPrefix(key, "jwt:") {
+			return nil, errors.New("regras da chave não podem ser determinadas")
+		}
+		return []limiter.Rule{ipRule}, nil
+	}
+	stateRules := func(string) []limiter.Rule {
+		return []limiter.Rule{ipRule, planRule}
+	}
+	h := NewHandler(coreLimiter, "secret", rules, WithStateRules(stateRules))
+	for i := 0; i < 3; i++ {
+		coreLimiter.AllowRule(ctx, "jwt:alice", planRule)
+	}
+
+	// Execute / Assert - o status não aplica as regras por IP à chave
+	if w := doTokenRequest(h, "GET", "/admin/status?key=jwt:alice", ""); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Status esperado 422 para chave jwt:, obtido %d", w.Code)
+	}
+
+	// O reinício zera as regras de todos os planos possíveis
+	if w := doTokenRequest(h, "POST", "/admin/reset?key=jwt:alice", ""); w.Code != http.StatusOK {
+		t.Fatalf("Status esperado 200, obtido %d", w.Code)
+	}
+	if status, _ := coreLimiter.Status(ctx, "jwt:alice", planRule); status.Count != 0 {
+		t.Errorf("Contador do plano deveria ser zerado, obtido %d", status.Count)
+	}
+
+	// Sem WithStateRules, o reinício também recusa a chave
+	h = NewHandler(coreLimiter, "secret", rules)
+	if w := doTokenRequest(h, "POST", "/admin/reset?key=jwt:alice", ""); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Status esperado 422 sem regras de reinício, obtido %d", w.Code)
+	}
+}
+
+func TestHandler_Block(t *testing.T) {
+	// Setup
+	h, coreLimiter := newTestHandler(t)
+	rule := limiter.Rule{Limit: 2, Window: time.Minute}
+
+	// Execute
+	w := doTokenRequest(h, "POST", "/admin/block?key=ip:1.2.3.4&duration=10m", "")
+
+	// Assert
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status esperado 200, obtido %d", w.Code)
+	}
+	status, _ := coreLimiter.Status(context.Background(), "ip:1.2.3.4", rule)
+	if !status.Blocked || time.Until(status.BlockedUntil) < 9*time.Minute {
+		t.Errorf("Chave deveria estar bloqueada por 10m, obtido %+v", status)
+	}
+
+	for _, target := range []string{"/admin/block?key=ip:1.2.3.4", "/admin/block?key=ip:1.2.3.4&duration=-1s", "/admin/block?duration=1m"} {
+		if w := doTokenRequest(h, "POST", target, ""); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status esperado 400, obtido %d", target, w.Code)
+		}
+	}
+}
+
+func TestHandler_Blocked(t *testing.T) {
+	// Setup
+	h, coreLimiter := newTestHandler(t)
+	ctx := context.Background()
+	rule := limiter.Rule{Limit: 1, Window: time.Minute, BlockDuration: time.Minute}
+	for i := 0; i < 2; i++ {
+		coreLimiter.AllowRule(ctx, "ip:1.2.3.4", rule)
+	}
+	coreLimiter.Block(ctx, "token:abc123", time.Hour)
+
+	// Execute
+	w := doTokenRequest(h, "GET", "/admin/blocked?type=ip", "")
+
+	// Assert
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status esperado 200, obtido %d", w.Code)
+	}
+	var resp blockedResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+	if len(resp.Blocked) != 1 || resp.NextCursor != "" {
+		t.Fatalf("Esperado 1 chave e fim da listagem, obtido %+v", resp)
+	}
+	if b := resp.Blocked[0]; b.Key != "ip:1.2.3.4" || b.Type != "ip" || b.Rule != "1/1m" || b.TTL != 60 {
+		t.Errorf("Chave bloqueada inesperada: %+v", b)
+	}
+
+	// Paginação: uma chave por página
+	w = doTokenRequest(h, "GET", "/admin/blocked?limit=1", "")
+	json.NewDecoder(w.Body).Decode(&resp)
+	if len(resp.Blocked) != 1 || resp.NextCursor == "" {
+		t.Errorf("Esperado 1 chave e próximo cursor, obtido %+v", resp)
+	}
+
+	for _, target := range []string{"/admin/blocked?type=user", "/admin/blocked?limit=0", "/admin/blocked?cursor=abc"} {
+		if w := doTokenRequest(h, "GET", target, ""); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status esperado 400, obtido %d", target, w.Code)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+
+	"github.com/marfebr/go_ratelimit/internal/registry"
+)
+
+// tokenEntry é um token do registro dinâmico com seus limites no formato de API_KEY_<TOKEN>
+type tokenEntry struct {
+	Token string `json:"token"`
+	Value string `json:"value"`
+}
+
+// listTokens lista os tokens do registro dinâmico
+// GET /admin/tokens
+func (h *Handler) listTokens(w http.ResponseWriter, r *http.Request) {
+	values := h.registry.List()
+	entries := make([]tokenEntry, 0, len(values))
+	for token, value := range values {
+		entries = append(entries, tokenEntry{Token: token, Value: value})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Token < entries[j].Token })
+	writeJSON(w, http.StatusOK, entries)
+}
+
+// getToken retorna os limites de um token do registro dinâmico
+// GET /admin/tokens/{token}
+func (h *Handler) getToken(w http.ResponseWriter, r *http.Request) {
+	token := r.PathValue("token")
+	value, exists := h.registry.Value(token)
+	if !exists {
+		writeJSON(w, http.StatusNotFound, message{Message: "token not found"})
+		return
+	}
+	writeJSON(w, http.StatusOK, tokenEntry{Token: token, Value: value})
+}
+
+// putToken cria ou atualiza os limites de um token
+// PUT /admin/tokens/{token} {"value": "100/1m,60"}
+func (h *Handler) putToken(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Value string `json:"value"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Value == "" {
+		writeJSON(w, http.StatusBadRequest, message{Message: `body must be {"value": "<limit>"}`})
+		return
+	}
+
+	token := r.PathValue("token")
+	if err := h.registry.Set(r.Context(), token, body.Value); err != nil {
+		// Valores inválidos são rejeitados antes de gravar no store
+		if errors.Is(err, registry.ErrInvalidLimit) {
+			writeJSON(w, http.StatusBadRequest, message{Message: err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusInternalServerError, message{Message: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, tokenEntry{Token: token, Value: body.Value})
+}
+
+// deleteToken remove um token do registro dinâmico
+// DELETE /admin/tokens/{token}
+func (h *Handler) deleteToken(w http.ResponseWriter, r *http.Request) {
+	deleted, err := h.registry.Delete(r.Context(), r.PathValue("token"))
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, message{Message: err.Error()})
+		return
+	}
+	if !deleted {
+		writeJSON(w, http.StatusNotFound, message{Message: "token not found"})
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
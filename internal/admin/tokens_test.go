@@ -0,0 +1,90 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/marfebr/go_ratelimit/internal/config"
+	"github.com/marfebr/go_ratelimit/internal/limiter"
+	"github.com/marfebr/go_ratelimit/internal/registry"
+)
+
+func newTokensHandler(t *testing.T) (*Handler, *registry.Registry) {
+	t.Helper()
+	cfg := &config.Config{Plans: map[string]config.TokenLimit{"pro": {Limit: 100, BlockDurationSecs: 60}}}
+	tokenRegistry := registry.New(registry.NewMemoryStore(), func() *config.Config { return cfg })
+	h := NewHandler(limiter.NewCoreLimiter(limiter.NewMemoryStore()), "secret", func(string) ([]limiter.Rule, error) { return nil, nil },
+		WithTokenRegistry(tokenRegistry))
+	return h, tokenRegistry
+}
+
+func doTokenRequest(h http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer secret")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func TestHandler_PutToken(t *testing.T) {
+	// Setup
+	h, tokenRegistry := newTokensHandler(t)
+
+	// Execute
+	w := doTokenRequest(h, "PUT", "/admin/tokens/abc123", `{"value": "plan=pro,burst=50"}`)
+
+	// Assert
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status esperado 200, obtido %d: %s", w.Code, w.Body.String())
+	}
+	if limit, exists := tokenRegistry.Get("abc123"); !exists || limit.Plan != "pro" || limit.Overrides.Burst != 50 {
+		t.Errorf("Token não registrado como esperado: %+v", limit)
+	}
+}
+
+func TestHandler_PutTokenInvalid(t *testing.T) {
+	// Setup
+	h, _ := newTokensHandler(t)
+
+	// Execute / Assert
+	for _, body := range []string{`{"value": "abc"}`, `{"value": "plan=gold"}`, `{}`, `not json`} {
+		w := doTokenRequest(h, "PUT", "/admin/tokens/abc123", body)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Body %s: status esperado 400, obtido %d", body, w.Code)
+		}
+	}
+}
+
+func TestHandler_GetListDeleteToken(t *testing.T) {
+	// Setup
+	h, _ := newTokensHandler(t)
+	doTokenRequest(h, "PUT", "/admin/tokens/abc123", `{"value": "10/1m,60"}`)
+
+	// Execute / Assert
+	w := doTokenRequest(h, "GET", "/admin/tokens/abc123", "")
+	var entry tokenEntry
+	json.NewDecoder(w.Body).Decode(&entry)
+	if w.Code != http.StatusOK || entry.Value != "10/1m,60" {
+		t.Errorf("GET esperado 200 com '10/1m,60', obtido %d %+v", w.Code, entry)
+	}
+
+	w = doTokenRequest(h, "GET", "/admin/tokens", "")
+	var entries []tokenEntry
+	json.NewDecoder(w.Body).Decode(&entries)
+	if len(entries) != 1 || entries[0].Token != "abc123" {
+		t.Errorf("Lista inesperada: %+v", entries)
+	}
+
+	if w := doTokenRequest(h, "DELETE", "/admin/tokens/abc123", ""); w.Code != http.StatusNoContent {
+		t.Errorf("DELETE: status esperado 204, obtido %d", w.Code)
+	}
+	if w := doTokenRequest(h, "DELETE", "/admin/tokens/abc123", ""); w.Code != http.StatusNotFound {
+		t.Errorf("DELETE repetido: status esperado 404, obtido %d", w.Code)
+	}
+	if w := doTokenRequest(h, "GET", "/admin/tokens/abc123", ""); w.Code != http.StatusNotFound {
+		t.Errorf("GET após remoção: status esperado 404, obtido %d", w.Code)
+	}
+}
@@ -2,59 +2,342 @@ package config
 
 import (
 	"fmt"
-	"os"
+	"net/http"
+	"net/netip"
+	"path"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 // Config armazena as configurações da aplicação
 type Config struct {
+	// ConfigFile é o arquivo de configuração carregado (vazio: apenas variáveis de ambiente)
+	ConfigFile             string
 	RedisAddr              string
 	DefaultRateLimitIP     int
+	DefaultWindowIP        time.Duration
+	DefaultTiersIP         []Rate
 	DefaultBlockDurationIP int
+	DefaultAlgorithmIP     string
+	DefaultBurstIP         int
 	TokenLimits            map[string]TokenLimit
+	// DefaultBlockEscalationIP são os bloqueios progressivos por IP (vazio: sempre DefaultBlockDurationIP)
+	DefaultBlockEscalationIP []time.Duration
+	// EscalationLookback é o período sem infrações após o qual a contagem do escalonamento é zerada
+	EscalationLookback time.Duration
+	// Plans são os planos nomeados (ex.: free, pro), referenciados pelos tokens com plan=
+	Plans map[string]TokenLimit
+	// QuotaTimezone é o fuso usado no reinício das cotas (padrão: UTC)
+	QuotaTimezone *time.Location
+	// QuotaExceededStatus é o status HTTP retornado com a cota esgotada (403 ou 429)
+	QuotaExceededStatus int
+	// RouteCosts são os custos por prefixo de rota (padrão: 1 unidade por requisição)
+	RouteCosts []RouteCost
+	// AdminToken autentica a API administrativa (vazio: API desabilitada)
+	AdminToken string
+	// RateLimitHeaders é o estilo dos headers de rate limit nas respostas (vazio: legacy)
+	RateLimitHeaders string
+	// TrustedProxies são as redes dos proxies reversos confiáveis. Os headers de
+	// encaminhamento (Forwarded, X-Forwarded-For, X-Real-IP) só são considerados em
+	// conexões vindas dessas redes; vazio: os headers são ignorados.
+	TrustedProxies []netip.Prefix
+	// IPv4PrefixLength e IPv6PrefixLength agrupam os clientes por rede no limite por IP
+	// (ex.: 64 conta todos os endereços de uma /64 IPv6 como um único cliente).
+	// O tamanho total do endereço (32 ou 128) limita cada endereço individualmente.
+	// As variáveis e o arquivo aceitam de 1 ao tamanho total; zero só ocorre em uma
+	// Config montada em código e também equivale ao tamanho total.
+	IPv4PrefixLength int
+	IPv6PrefixLength int
+	// JWT configura os limites por claims de tokens JWT
+	JWT JWTConfig
+	// RouteLimits são limites próprios por rota, avaliados em ordem (vale a primeira que casar)
+	RouteLimits []RouteLimit
+	// RateLimitExclude são as rotas que não passam pelo rate limiting (padrão: /health)
+	RateLimitExclude []RoutePattern
+	// TokenKeySource e DefaultKeySource são globais; apenas RouteLimit tem fonte própria (KeySource)
+	// TokenKeySource é de onde vem o token procurado em TokenLimits (vazio: header API_KEY)
+	TokenKeySource KeySpec
+	// DefaultKeySource identifica os clientes sujeitos aos limites padrão (vazio: IP)
+	DefaultKeySource KeySpec
+	// AllowList são os clientes que nunca são limitados (ex.: monitoramento, parceiros)
+	AllowList AccessList
+	// DenyList são os clientes rejeitados com 403 antes do rate limiting; prevalece sobre AllowList
+	DenyList AccessList
+	// StoreFailure define o comportamento do rate limiting com o store indisponível
+	StoreFailure StoreFailureConfig
+}
+
+// StoreFailureConfig define a política aplicada quando o store (Redis) falha
+type StoreFailureConfig struct {
+	// Policy é a política: open (permite), closed (503) ou local (limiter em memória)
+	Policy string
+	// LocalPercent é o percentual dos limites aplicado por instância na política local
+	LocalPercent int
+}
+
+// Políticas aceitas em STORE_FAILURE_POLICY
+const (
+	StoreFailureOpen   = "open"
+	StoreFailureClosed = "closed"
+	StoreFailureLocal  = "local"
+)
+
+var validStoreFailurePolicies = map[string]bool{
+	StoreFailureOpen:   true,
+	StoreFailureClosed: true,
+	StoreFailureLocal:  true,
+}
+
+// AccessList identifica clientes por rede do IP ou pelo token exato
+type AccessList struct {
+	Networks []netip.Prefix
+	Tokens   []string
+}
+
+// RoutePattern identifica requisições por método e caminho
+// O padrão segue o http.ServeMux (/login exato, /api/ prefixo, /users/{id} curinga)
+// ou, se contiver *, ? ou [, um glob de path.Match (ex.: /files/*.zip)
+type RoutePattern struct {
+	// Method restringe o método HTTP (vazio: qualquer método)
+	Method  string
+	Pattern string
+}
+
+// String retorna o padrão no formato [MÉTODO ]CAMINHO
+func (p RoutePattern) String() string {
+	if p.Method == "" {
+		return p.Pattern
+	}
+	return p.Method + " " + p.Pattern
+}
+
+// IsGlob informa se o padrão é um glob de path.Match
+func (p RoutePattern) IsGlob() bool {
+	return strings.ContainsAny(p.Pattern, "*?[")
+}
+
+// RouteLimit é um limite próprio para as requisições que casam com um padrão de rota
+type RouteLimit struct {
+	RoutePattern
+	// Limits são os limites, o bloqueio e as opções (algorithm=, burst=) da rota
+	Limits TokenLimit
+	// KeySource identifica os clientes da rota (vazio: a chave padrão)
+	KeySource KeySpec
+}
+
+// JWTConfig configura a identificação de clientes por tokens JWT (Authorization: Bearer)
+type JWTConfig struct {
+	// Secret é o segredo HS256
+	Secret string
+	// PublicKeyFile é uma chave pública PEM RSA (RS256) ou ECDSA P-256 (ES256)
+	PublicKeyFile string
+	// JWKSFile é um arquivo JWKS local com chaves de qualquer algoritmo suportado
+	JWKSFile string
+	// KeyClaims são as claims que formam a chave de rate limit (padrão: sub)
+	KeyClaims []string
+	// PlanClaim é a claim com o plano do cliente (padrão: plan)
+	PlanClaim string
+	// Plans são os limites exclusivos dos planos JWT; planos ausentes aqui são
+	// procurados nos planos gerais (Config.Plans) e, por fim, usam os limites padrão
+	Plans map[string]TokenLimit
+	// InvalidTokenAction é a resposta a tokens inválidos: fallback (limite por IP) ou reject (401)
+	InvalidTokenAction string
+	// Issuer, quando definido, precisa ser igual à claim iss
+	Issuer string
+	// Audience, quando definida, precisa estar na claim aud
+	Audience string
+	// RequireExp rejeita tokens sem a claim exp
+	RequireExp bool
+}
+
+// Ações aceitas em JWT_INVALID_TOKEN_ACTION
+const (
+	JWTInvalidFallback = "fallback"
+	JWTInvalidReject   = "reject"
+)
+
+// Enabled informa se há alguma chave JWT configurada
+func (j JWTConfig) Enabled() bool {
+	return j.Secret != "" || j.PublicKeyFile != "" || j.JWKSFile != ""
+}
+
+// KeySource é uma fonte de identidade da requisição
+type KeySource struct {
+	// Kind é o tipo da fonte: ip, route, bearer, header, query ou cookie
+	Kind string
+	// Name é o nome do header, parâmetro de query ou cookie
+	Name string
+}
+
+// KeySpec descreve como extrair a chave de rate limit: alternativas tentadas
+// em ordem, cada uma combinando uma ou mais fontes (todas precisam estar presentes)
+type KeySpec [][]KeySource
+
+// RouteCost é o número de unidades consumidas por requisições a um prefixo de rota
+type RouteCost struct {
+	PathPrefix string
+	Cost       int
 }
 
 // TokenLimit define limite e duração de bloqueio para um token específico
 type TokenLimit struct {
 	Limit             int
+	Window            time.Duration // janela do limite (zero: 1 segundo)
 	BlockDurationSecs int
+	// Tiers são limites adicionais aplicados simultaneamente (ex.: por minuto e por dia)
+	Tiers []Rate
+	// Algorithm é a estratégia de contagem (vazio: fixed_window)
+	Algorithm string
+	// Burst é a capacidade do token bucket (zero: igual a Limit)
+	Burst int
+	// Quota é a cota de longo prazo do token (Limit zero: sem cota)
+	Quota Quota
+	// Escalation são os bloqueios progressivos da 1ª, 2ª, ... infração (vazio: sempre BlockDurationSecs)
+	Escalation []time.Duration
+	// Plan é o plano de onde o token herda os limites (vazio: limites próprios)
+	// GetTokenLimit resolve o plano e aplica Overrides
+	Plan string
+	// Overrides são os campos do plano redefinidos pelo token
+	Overrides TokenOverrides
+}
+
+// TokenOverrides são campos de um plano redefinidos por um token
+// Campos zero (ou nil) herdam o valor do plano
+type TokenOverrides struct {
+	Rates             []Rate
+	BlockDurationSecs *int
+	Algorithm         string
+	Burst             int
+	Quota             Quota
+	Escalation        []time.Duration
+}
+
+// Quota é uma cota de longo prazo com reinício alinhado ao calendário
+type Quota struct {
+	Limit  int64
+	Period string // day ou month
+}
+
+// Rate é um limite de requisições por janela
+type Rate struct {
+	Limit  int
+	Window time.Duration
+}
+
+// Rates retorna todos os limites do token: o principal seguido dos adicionais
+func (t TokenLimit) Rates() []Rate {
+	return append([]Rate{{Limit: t.Limit, Window: t.Window}}, t.Tiers...)
+}
+
+// DefaultRatesIP retorna todos os limites padrão por IP
+func (c *Config) DefaultRatesIP() []Rate {
+	return append([]Rate{{Limit: c.DefaultRateLimitIP, Window: c.DefaultWindowIP}}, c.DefaultTiersIP...)
 }
 
-// LoadConfig carrega configurações de variáveis de ambiente e .env
+// Algoritmos aceitos em DEFAULT_ALGORITHM_IP e na opção algorithm= dos tokens
+var validAlgorithms = map[string]bool{
+	"fixed_window":           true,
+	"token_bucket":           true,
+	"sliding_window_counter": true,
+	"sliding_window_log":     true,
+	"gcra":                   true,
+}
+
+// Estilos de headers de rate limit aceitos em RATELIMIT_HEADERS
+const (
+	HeadersLegacy = "legacy" // X-RateLimit-Limit, X-RateLimit-Remaining e X-RateLimit-Reset
+	HeadersIETF   = "ietf"   // RateLimit-Policy e RateLimit (draft IETF)
+	HeadersBoth   = "both"   // legacy e ietf
+	HeadersNone   = "none"   // nenhum (Retry-After continua no 429)
+)
+
+var validHeaderStyles = map[string]bool{
+	HeadersLegacy: true,
+	HeadersIETF:   true,
+	HeadersBoth:   true,
+	HeadersNone:   true,
+}
+
+// Fontes de chave aceitas em TOKEN_KEY_SOURCE e DEFAULT_KEY_SOURCE (true: exige nome)
+var validKeySources = map[string]bool{
+	"ip":     false,
+	"route":  false,
+	"bearer": false,
+	"header": true,
+	"query":  true,
+	"cookie": true,
+}
+
+// defaultConfig retorna a configuração padrão, antes do arquivo e das variáveis de ambiente
+func defaultConfig() *Config {
+	return &Config{
+		DefaultRateLimitIP:     5,
+		DefaultWindowIP:        time.Second,
+		DefaultBlockDurationIP: 300, // 5 minutos
+		EscalationLookback:     24 * time.Hour,
+		TokenLimits:            make(map[string]TokenLimit),
+		Plans:                  make(map[string]TokenLimit),
+		QuotaTimezone:          time.UTC,
+		QuotaExceededStatus:    403,
+		RateLimitHeaders:       HeadersLegacy,
+		IPv4PrefixLength:       32,
+		IPv6PrefixLength:       64,
+		JWT: JWTConfig{
+			KeyClaims:          []string{"sub"},
+			PlanClaim:          "plan",
+			Plans:              make(map[string]TokenLimit),
+			InvalidTokenAction: JWTInvalidFallback,
+		},
+		RateLimitExclude: []RoutePattern{{Pattern: "/health"}},
+		StoreFailure:     StoreFailureConfig{Policy: StoreFailureOpen, LocalPercent: 50},
+	}
+}
+
+// LoadConfig carrega configurações do arquivo CONFIG_FILE (opcional), de variáveis de ambiente e .env
+// Precedência: variáveis de ambiente > arquivo > padrões
 func LoadConfig() (*Config, error) {
+	return load(osEnvironment{})
+}
+
+// load carrega a configuração lendo as variáveis do ambiente informado
+func load(env environment) (*Config, error) {
 	// Tenta carregar .env (ignora erro se não existir)
 	_ = godotenv.Load()
 
-	cfg := &Config{
-		TokenLimits: make(map[string]TokenLimit),
+	cfg := defaultConfig()
+
+	// Arquivo de configuração (YAML ou JSON); as variáveis de ambiente têm prioridade
+	if path := env.Get("CONFIG_FILE"); path != "" {
+		if err := applyFile(cfg, path); err != nil {
+			return nil, err
+		}
+		cfg.ConfigFile = path
 	}
 
 	// Redis Address (obrigatório)
-	cfg.RedisAddr = os.Getenv("REDIS_ADDR")
+	if redisAddr := env.Get("REDIS_ADDR"); redisAddr != "" {
+		cfg.RedisAddr = redisAddr
+	}
 	if cfg.RedisAddr == "" {
 		return nil, fmt.Errorf("REDIS_ADDR não configurado")
 	}
 
-	// Default Rate Limit IP
-	rateLimitStr := os.Getenv("DEFAULT_RATE_LIMIT_IP")
-	if rateLimitStr == "" {
-		cfg.DefaultRateLimitIP = 5 // padrão
-	} else {
-		limit, err := strconv.Atoi(rateLimitStr)
+	// Default Rate Limit IP (LIMITE[/JANELA], múltiplos limites separados por +)
+	if rateLimitStr := env.Get("DEFAULT_RATE_LIMIT_IP"); rateLimitStr != "" {
+		rates, err := ParseRates(rateLimitStr)
 		if err != nil {
 			return nil, fmt.Errorf("DEFAULT_RATE_LIMIT_IP inválido: %w", err)
 		}
-		cfg.DefaultRateLimitIP = limit
+		cfg.DefaultRateLimitIP = rates[0].Limit
+		cfg.DefaultWindowIP = rates[0].Window
+		cfg.DefaultTiersIP = rates[1:]
 	}
 
 	// Default Block Duration IP
-	blockDurationStr := os.Getenv("DEFAULT_BLOCK_DURATION_SECONDS")
-	if blockDurationStr == "" {
-		cfg.DefaultBlockDurationIP = 300 // padrão 5 minutos
-	} else {
+	if blockDurationStr := env.Get("DEFAULT_BLOCK_DURATION_SECONDS"); blockDurationStr != "" {
 		duration, err := strconv.Atoi(blockDurationStr)
 		if err != nil {
 			return nil, fmt.Errorf("DEFAULT_BLOCK_DURATION_SECONDS inválido: %w", err)
@@ -62,42 +345,740 @@ func LoadConfig() (*Config, error) {
 		cfg.DefaultBlockDurationIP = duration
 	}
 
-	// Carrega limites de tokens (API_KEY_<TOKEN>=LIMIT,BLOCK_SECONDS)
-	for _, env := range os.Environ() {
-		if strings.HasPrefix(env, "API_KEY_") {
-			parts := strings.SplitN(env, "=", 2)
-			if len(parts) != 2 {
-				continue
+	// Default Algorithm IP
+	if algorithm := env.Get("DEFAULT_ALGORITHM_IP"); algorithm != "" {
+		if !validAlgorithms[algorithm] {
+			return nil, fmt.Errorf("DEFAULT_ALGORITHM_IP inválido: %s", algorithm)
+		}
+		cfg.DefaultAlgorithmIP = algorithm
+	}
+
+	// Default Burst IP (capacidade do token bucket)
+	if burstStr := env.Get("DEFAULT_BURST_IP"); burstStr != "" {
+		burst, err := strconv.Atoi(burstStr)
+		if err != nil {
+			return nil, fmt.Errorf("DEFAULT_BURST_IP inválido: %w", err)
+		}
+		cfg.DefaultBurstIP = burst
+	}
+
+	// Bloqueios progressivos por IP (ex.: 1m+5m+1h+24h) e período de decaimento das infrações
+	if escalationStr := env.Get("DEFAULT_BLOCK_ESCALATION_IP"); escalationStr != "" {
+		steps, err := ParseEscalation(escalationStr)
+		if err != nil {
+			return nil, fmt.Errorf("DEFAULT_BLOCK_ESCALATION_IP inválido: %w", err)
+		}
+		cfg.DefaultBlockEscalationIP = steps
+	}
+	if lookbackStr := env.Get("BLOCK_ESCALATION_LOOKBACK"); lookbackStr != "" {
+		lookback, err := ParseWindow(lookbackStr)
+		if err != nil {
+			return nil, fmt.Errorf("BLOCK_ESCALATION_LOOKBACK inválido: %w", err)
+		}
+		cfg.EscalationLookback = lookback
+	}
+
+	// Fuso das cotas (ex.: America/Sao_Paulo)
+	if tz := env.Get("QUOTA_TIMEZONE"); tz != "" {
+		location, err := time.LoadLocation(tz)
+		if err != nil {
+			return nil, fmt.Errorf("QUOTA_TIMEZONE inválido: %w", err)
+		}
+		cfg.QuotaTimezone = location
+	}
+
+	// Status HTTP da cota esgotada
+	if statusStr := env.Get("QUOTA_EXCEEDED_STATUS"); statusStr != "" {
+		status, err := strconv.Atoi(statusStr)
+		if err != nil || (status != 403 && status != 429) {
+			return nil, fmt.Errorf("QUOTA_EXCEEDED_STATUS inválido (esperado 403 ou 429): %s", statusStr)
+		}
+		cfg.QuotaExceededStatus = status
+	}
+
+	// Custos por rota (ex.: /export=50,/search=10)
+	if costsStr := env.Get("REQUEST_COSTS"); costsStr != "" {
+		costs, err := ParseRouteCosts(costsStr)
+		if err != nil {
+			return nil, fmt.Errorf("REQUEST_COSTS inválido: %w", err)
+		}
+		cfg.RouteCosts = costs
+	}
+
+	// Estilo dos headers de rate limit
+	if style := env.Get("RATELIMIT_HEADERS"); style != "" {
+		if !validHeaderStyles[style] {
+			return nil, fmt.Errorf("RATELIMIT_HEADERS inválido (esperado legacy, ietf, both ou none): %s", style)
+		}
+		cfg.RateLimitHeaders = style
+	}
+
+	// Proxies confiáveis (ex.: 10.0.0.0/8,172.16.0.0/12,127.0.0.1)
+	if proxiesStr := env.Get("TRUSTED_PROXIES"); proxiesStr != "" {
+		proxies, err := ParseTrustedProxies(proxiesStr)
+		if err != nil {
+			return nil, fmt.Errorf("TRUSTED_PROXIES inválido: %w", err)
+		}
+		cfg.TrustedProxies = proxies
+	}
+
+	// Listas de permissão e bloqueio (ex.: ALLOWLIST_IPS=10.0.0.0/8, DENYLIST_TOKENS=leaked1,leaked2)
+	if err := loadAccessList(env, "ALLOWLIST", &cfg.AllowList); err != nil {
+		return nil, err
+	}
+	if err := loadAccessList(env, "DENYLIST", &cfg.DenyList); err != nil {
+		return nil, err
+	}
+
+	// Política com o store indisponível (ex.: STORE_FAILURE_POLICY=local, STORE_FAILURE_LOCAL_PERCENT=25)
+	if policy := env.Get("STORE_FAILURE_POLICY"); policy != "" {
+		if !validStoreFailurePolicies[policy] {
+			return nil, fmt.Errorf("STORE_FAILURE_POLICY inválido (esperado open, closed ou local): %s", policy)
+		}
+		cfg.StoreFailure.Policy = policy
+	}
+	if percentStr := env.Get("STORE_FAILURE_LOCAL_PERCENT"); percentStr != "" {
+		percent, err := strconv.Atoi(percentStr)
+		if err != nil || percent < 1 || percent > 100 {
+			return nil, fmt.Errorf("STORE_FAILURE_LOCAL_PERCENT inválido (esperado 1 a 100): %s", percentStr)
+		}
+		cfg.StoreFailure.LocalPercent = percent
+	}
+
+	// Agrupamento de clientes por prefixo de rede
+	if lengthStr := env.Get("IPV4_PREFIX_LENGTH"); lengthStr != "" {
+		length, err := strconv.Atoi(lengthStr)
+		if err != nil || length < 1 || length > 32 {
+			return nil, fmt.Errorf("IPV4_PREFIX_LENGTH inválido (esperado 1 a 32): %s", lengthStr)
+		}
+		cfg.IPv4PrefixLength = length
+	}
+	if lengthStr := env.Get("IPV6_PREFIX_LENGTH"); lengthStr != "" {
+		length, err := strconv.Atoi(lengthStr)
+		if err != nil || length < 1 || length > 128 {
+			return nil, fmt.Errorf("IPV6_PREFIX_LENGTH inválido (esperado 1 a 128): %s", lengthStr)
+		}
+		cfg.IPv6PrefixLength = length
+	}
+
+	// Fontes das chaves de rate limit (ex.: bearer|header:API_KEY, header:X-Tenant-ID+route|ip)
+	if sourceStr := env.Get("TOKEN_KEY_SOURCE"); sourceStr != "" {
+		spec, err := ParseKeySpec(sourceStr)
+		if err != nil {
+			return nil, fmt.Errorf("TOKEN_KEY_SOURCE inválido: %w", err)
+		}
+		cfg.TokenKeySource = spec
+	}
+	if sourceStr := env.Get("DEFAULT_KEY_SOURCE"); sourceStr != "" {
+		spec, err := ParseKeySpec(sourceStr)
+		if err != nil {
+			return nil, fmt.Errorf("DEFAULT_KEY_SOURCE inválido: %w", err)
+		}
+		cfg.DefaultKeySource = spec
+	}
+
+	// Tokens JWT (chaves em segredo HS256, PEM ou JWKS local)
+	if secret := env.Get("JWT_SECRET"); secret != "" {
+		cfg.JWT.Secret = secret
+	}
+	if publicKeyFile := env.Get("JWT_PUBLIC_KEY_FILE"); publicKeyFile != "" {
+		cfg.JWT.PublicKeyFile = publicKeyFile
+	}
+	if jwksFile := env.Get("JWT_JWKS_FILE"); jwksFile != "" {
+		cfg.JWT.JWKSFile = jwksFile
+	}
+	if claimsStr := env.Get("JWT_KEY_CLAIMS"); claimsStr != "" {
+		cfg.JWT.KeyClaims = nil
+		for _, claim := range strings.Split(claimsStr, ",") {
+			if claim = strings.TrimSpace(claim); claim == "" {
+				return nil, fmt.Errorf("JWT_KEY_CLAIMS inválido: %s", claimsStr)
 			}
+			cfg.JWT.KeyClaims = append(cfg.JWT.KeyClaims, claim)
+		}
+	}
+	if planClaim := env.Get("JWT_PLAN_CLAIM"); planClaim != "" {
+		cfg.JWT.PlanClaim = planClaim
+	}
+	if action := env.Get("JWT_INVALID_TOKEN_ACTION"); action != "" {
+		if action != JWTInvalidFallback && action != JWTInvalidReject {
+			return nil, fmt.Errorf("JWT_INVALID_TOKEN_ACTION inválido (esperado fallback ou reject): %s", action)
+		}
+		cfg.JWT.InvalidTokenAction = action
+	}
+	if issuer := env.Get("JWT_ISSUER"); issuer != "" {
+		cfg.JWT.Issuer = issuer
+	}
+	if audience := env.Get("JWT_AUDIENCE"); audience != "" {
+		cfg.JWT.Audience = audience
+	}
+	if requireExpStr := env.Get("JWT_REQUIRE_EXP"); requireExpStr != "" {
+		requireExp, err := strconv.ParseBool(requireExpStr)
+		if err != nil {
+			return nil, fmt.Errorf("JWT_REQUIRE_EXP inválido (esperado true ou false): %s", requireExpStr)
+		}
+		cfg.JWT.RequireExp = requireExp
+	}
 
-			tokenKey := strings.TrimPrefix(parts[0], "API_KEY_")
-			valueParts := strings.Split(parts[1], ",")
-			if len(valueParts) != 2 {
-				return nil, fmt.Errorf("formato inválido para %s (esperado: LIMIT,BLOCK_SECONDS)", parts[0])
+	// Limites por rota (ex.: POST /login=5/1m,300;/api/=100,60,key=header:X-Tenant-ID)
+	if routesStr := env.Get("ROUTE_LIMITS"); routesStr != "" {
+		routes, err := ParseRouteLimits(routesStr)
+		if err != nil {
+			return nil, fmt.Errorf("ROUTE_LIMITS inválido: %w", err)
+		}
+		cfg.RouteLimits = routes
+	}
+
+	// Rotas fora do rate limiting (definida e vazia: nenhuma)
+	if excludeStr, ok := env.Lookup("RATE_LIMIT_EXCLUDE"); ok {
+		cfg.RateLimitExclude = nil
+		for _, part := range strings.Split(excludeStr, ",") {
+			if strings.TrimSpace(part) == "" {
+				continue
 			}
+			pattern, err := ParseRoutePattern(part)
+			if err != nil {
+				return nil, fmt.Errorf("RATE_LIMIT_EXCLUDE inválido: %w", err)
+			}
+			cfg.RateLimitExclude = append(cfg.RateLimitExclude, pattern)
+		}
+	}
 
-			limit, err := strconv.Atoi(strings.TrimSpace(valueParts[0]))
+	// Token da API administrativa
+	if adminToken := env.Get("ADMIN_TOKEN"); adminToken != "" {
+		cfg.AdminToken = adminToken
+	}
+
+	// Carrega planos (PLAN_<PLANO>=LIMIT[/JANELA][+LIMIT/JANELA...],BLOCK_SECONDS[,opção=valor...])
+	// e os planos JWT (JWT_PLAN_<PLANO>=..., mesmo formato)
+	for _, env := range env.Environ() {
+		name, value, ok := strings.Cut(env, "=")
+		if !ok {
+			continue
+		}
+
+		if plan, isPlan := strings.CutPrefix(name, "PLAN_"); isPlan {
+			planLimit, err := parseTokenLimit(value)
 			if err != nil {
-				return nil, fmt.Errorf("limite inválido para %s: %w", parts[0], err)
+				return nil, fmt.Errorf("%s: %w", name, err)
 			}
+			cfg.Plans[plan] = planLimit
+		}
 
-			blockDuration, err := strconv.Atoi(strings.TrimSpace(valueParts[1]))
+		if plan, isPlan := strings.CutPrefix(name, "JWT_PLAN_"); isPlan {
+			planLimit, err := parseTokenLimit(value)
 			if err != nil {
-				return nil, fmt.Errorf("block duration inválido para %s: %w", parts[0], err)
+				return nil, fmt.Errorf("%s: %w", name, err)
 			}
+			cfg.JWT.Plans[plan] = planLimit
+		}
+	}
+
+	// Carrega limites de tokens: limites próprios (API_KEY_<TOKEN>=LIMIT[/JANELA],BLOCK_SECONDS[,opção=valor...])
+	// ou um plano com redefinições (API_KEY_<TOKEN>=plan=<PLANO>[,limit=...,block=...,opção=valor...])
+	for _, env := range env.Environ() {
+		name, value, ok := strings.Cut(env, "=")
+		if !ok {
+			continue
+		}
 
-			cfg.TokenLimits[tokenKey] = TokenLimit{
-				Limit:             limit,
-				BlockDurationSecs: blockDuration,
+		if tokenKey, isToken := strings.CutPrefix(name, "API_KEY_"); isToken {
+			tokenLimit, err := ParseTokenEntry(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
 			}
+			if _, exists := cfg.Plans[tokenLimit.Plan]; tokenLimit.Plan != "" && !exists {
+				return nil, fmt.Errorf("%s: plano desconhecido: %s", name, tokenLimit.Plan)
+			}
+			cfg.TokenLimits[tokenKey] = tokenLimit
 		}
 	}
 
+	if err := validateRouteCosts(cfg); err != nil {
+		return nil, fmt.Errorf("REQUEST_COSTS inválido: %w", err)
+	}
+
 	return cfg, nil
 }
 
+// ParseTokenEntry interpreta o valor de API_KEY_<TOKEN>: limites próprios ou plan=<PLANO>
+// seguido de redefinições (limit=, block= e as opções dos tokens)
+func ParseTokenEntry(value string) (TokenLimit, error) {
+	if !strings.HasPrefix(strings.TrimSpace(value), "plan=") {
+		return parseTokenLimit(value)
+	}
+
+	var tokenLimit TokenLimit
+	var options []string
+	for _, option := range strings.Split(value, ",") {
+		name, optionValue, _ := strings.Cut(strings.TrimSpace(option), "=")
+		switch name {
+		case "plan":
+			if optionValue == "" {
+				return TokenLimit{}, fmt.Errorf("plano vazio")
+			}
+			tokenLimit.Plan = optionValue
+		case "limit":
+			rates, err := ParseRates(optionValue)
+			if err != nil {
+				return TokenLimit{}, fmt.Errorf("limite inválido: %w", err)
+			}
+			tokenLimit.Overrides.Rates = rates
+		case "block":
+			blockDuration, err := strconv.Atoi(optionValue)
+			if err != nil {
+				return TokenLimit{}, fmt.Errorf("block duration inválido: %w", err)
+			}
+			tokenLimit.Overrides.BlockDurationSecs = &blockDuration
+		default:
+			options = append(options, option)
+		}
+	}
+
+	// As demais opções são interpretadas como nos limites próprios
+	var parsed TokenLimit
+	if err := parseTokenOptions(&parsed, options); err != nil {
+		return TokenLimit{}, fmt.Errorf("opção inválida: %w", err)
+	}
+	tokenLimit.Overrides.Algorithm = parsed.Algorithm
+	tokenLimit.Overrides.Burst = parsed.Burst
+	tokenLimit.Overrides.Quota = parsed.Quota
+	tokenLimit.Overrides.Escalation = parsed.Escalation
+	return tokenLimit, nil
+}
+
+// parseTokenLimit interpreta um limite no formato LIMIT[/JANELA][+LIMIT/JANELA...],BLOCK_SECONDS[,opção=valor...]
+func parseTokenLimit(value string) (TokenLimit, error) {
+	valueParts := strings.Split(value, ",")
+	if len(valueParts) < 2 {
+		return TokenLimit{}, fmt.Errorf("formato inválido (esperado: LIMIT,BLOCK_SECONDS)")
+	}
+
+	rates, err := ParseRates(valueParts[0])
+	if err != nil {
+		return TokenLimit{}, fmt.Errorf("limite inválido: %w", err)
+	}
+
+	blockDuration, err := strconv.Atoi(strings.TrimSpace(valueParts[1]))
+	if err != nil {
+		return TokenLimit{}, fmt.Errorf("block duration inválido: %w", err)
+	}
+
+	tokenLimit := TokenLimit{
+		Limit:             rates[0].Limit,
+		Window:            rates[0].Window,
+		BlockDurationSecs: blockDuration,
+		Tiers:             rates[1:],
+	}
+	if err := parseTokenOptions(&tokenLimit, valueParts[2:]); err != nil {
+		return TokenLimit{}, fmt.Errorf("opção inválida: %w", err)
+	}
+	return tokenLimit, nil
+}
+
+// ParseRates interpreta um ou mais limites separados por + (ex.: 10/s+300/1m+10000/d)
+func ParseRates(value string) ([]Rate, error) {
+	var rates []Rate
+	for _, part := range strings.Split(value, "+") {
+		limit, window, err := ParseRate(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		for _, existing := range rates {
+			if existing.Window == window {
+				return nil, fmt.Errorf("janela %v repetida", window)
+			}
+		}
+		rates = append(rates, Rate{Limit: limit, Window: window})
+	}
+	return rates, nil
+}
+
+// ParseRate interpreta um limite no formato LIMITE[/JANELA]
+// A janela aceita s, m, h e d com multiplicador opcional (ex.: 100/1m, 50000/d, 10/30s)
+// ou qualquer duração Go (ex.: 10/1m30s). Sem janela, o limite é por segundo.
+func ParseRate(value string) (int, time.Duration, error) {
+	limitStr, windowStr, hasWindow := strings.Cut(value, "/")
+
+	limit, err := strconv.Atoi(strings.TrimSpace(limitStr))
+	if err != nil {
+		return 0, 0, err
+	}
+	if limit < 0 {
+		return 0, 0, fmt.Errorf("limite negativo: %d", limit)
+	}
+	if !hasWindow {
+		return limit, time.Second, nil
+	}
+
+	window, err := ParseWindow(windowStr)
+	if err != nil {
+		return 0, 0, err
+	}
+	return limit, window, nil
+}
+
+// ParseWindow interpreta uma janela (ex.: s, 1m, 30s, d, 1h30m)
+func ParseWindow(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	units := map[byte]time.Duration{
+		's': time.Second,
+		'm': time.Minute,
+		'h': time.Hour,
+		'd': 24 * time.Hour,
+	}
+
+	if value != "" {
+		if unit, ok := units[value[len(value)-1]]; ok {
+			multiplier := 1
+			if n := value[:len(value)-1]; n != "" {
+				parsed, err := strconv.Atoi(n)
+				if err != nil {
+					// Não é LIMITE/Nunidade: tenta como duração Go
+					return parseGoDuration(value)
+				}
+				multiplier = parsed
+			}
+			if multiplier <= 0 {
+				return 0, fmt.Errorf("janela deve ser positiva: %s", value)
+			}
+			return time.Duration(multiplier) * unit, nil
+		}
+	}
+
+	return parseGoDuration(value)
+}
+
+// parseGoDuration interpreta uma duração Go positiva
+func parseGoDuration(value string) (time.Duration, error) {
+	window, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("janela inválida: %s", value)
+	}
+	if window <= 0 {
+		return 0, fmt.Errorf("janela deve ser positiva: %s", value)
+	}
+	return window, nil
+}
+
+// ParseEscalation interpreta bloqueios progressivos separados por + (ex.: 1m+5m+1h+24h)
+// Cada duração aceita o formato das janelas; a última é o teto das infrações seguintes
+func ParseEscalation(value string) ([]time.Duration, error) {
+	var steps []time.Duration
+	for _, part := range strings.Split(value, "+") {
+		step, err := ParseWindow(part)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// ParseQuota interpreta uma cota no formato LIMITE/PERÍODO (ex.: 50000/month, 1000/day)
+func ParseQuota(value string) (Quota, error) {
+	limitStr, period, ok := strings.Cut(strings.TrimSpace(value), "/")
+	if !ok {
+		return Quota{}, fmt.Errorf("esperado LIMITE/PERÍODO, obtido %q", value)
+	}
+
+	limit, err := strconv.ParseInt(limitStr, 10, 64)
+	if err != nil || limit <= 0 {
+		return Quota{}, fmt.Errorf("limite de cota inválido: %s", limitStr)
+	}
+	if period != "day" && period != "month" {
+		return Quota{}, fmt.Errorf("período de cota inválido (esperado day ou month): %s", period)
+	}
+
+	return Quota{Limit: limit, Period: period}, nil
+}
+
+// ParseRouteCosts interpreta custos por rota no formato PREFIXO=CUSTO separados por vírgula
+func ParseRouteCosts(value string) ([]RouteCost, error) {
+	var costs []RouteCost
+	for _, part := range strings.Split(value, ",") {
+		prefix, costStr, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok || !strings.HasPrefix(prefix, "/") {
+			return nil, fmt.Errorf("esperado /PREFIXO=CUSTO, obtido %q", part)
+		}
+		cost, err := strconv.Atoi(costStr)
+		if err != nil || cost < 1 {
+			return nil, fmt.Errorf("custo inválido para %s: %s", prefix, costStr)
+		}
+		costs = append(costs, RouteCost{PathPrefix: prefix, Cost: cost})
+	}
+	return costs, nil
+}
+
+// validateRouteCosts recusa custos que nunca caberiam no menor limite aplicável ao prefixo:
+// o das rotas com limite próprio que o cobrem ou, sem rota sem método que o cubra, o padrão por IP
+func validateRouteCosts(cfg *Config) error {
+	for _, cost := range cfg.RouteCosts {
+		smallest, replaced := 0, false
+		for _, route := range cfg.RouteLimits {
+			if route.IsGlob() || !coversPrefix(route.Pattern, cost.PathPrefix) {
+				continue
+			}
+			smallest = minCapacity(smallest, route.Limits.Rates(), route.Limits.Algorithm, route.Limits.Burst)
+			replaced = replaced || route.Method == ""
+		}
+		if !replaced {
+			smallest = minCapacity(smallest, cfg.DefaultRatesIP(), cfg.DefaultAlgorithmIP, cfg.DefaultBurstIP)
+		}
+		if cost.Cost > smallest {
+			return fmt.Errorf("custo %d de %s excede o menor limite aplicável (%d)", cost.Cost, cost.PathPrefix, smallest)
+		}
+	}
+	return nil
+}
+
+// coversPrefix informa se o padrão de rota (exato ou terminado em /) casa com todo o prefixo
+func coversPrefix(pattern, prefix string) bool {
+	if strings.HasSuffix(pattern, "/") {
+		return strings.HasPrefix(prefix, pattern)
+	}
+	return pattern == prefix
+}
+
+// minCapacity retorna a menor capacidade entre current (zero: nenhuma) e os limites informados
+// A rajada vale para o limite principal do token bucket e do GCRA
+func minCapacity(current int, rates []Rate, algorithm string, burst int) int {
+	for i, rate := range rates {
+		capacity := rate.Limit
+		if i == 0 && burst > 0 && (algorithm == "token_bucket" || algorithm == "gcra") {
+			capacity = burst
+		}
+		if current == 0 || capacity < current {
+			current = capacity
+		}
+	}
+	return current
+}
+
+// ParseTrustedProxies interpreta redes CIDR ou IPs isolados separados por vírgula
+func ParseTrustedProxies(value string) ([]netip.Prefix, error) {
+	return ParseNetworks(value)
+}
+
+// ParseNetworks interpreta redes CIDR e IPs separados por vírgula (ex.: 10.0.0.0/8,2001:db8::/32,127.0.0.1)
+// IPs isolados equivalem a uma rede de um único endereço
+func ParseNetworks(value string) ([]netip.Prefix, error) {
+	var networks []netip.Prefix
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if strings.Contains(part, "/") {
+			prefix, err := netip.ParsePrefix(part)
+			if err != nil {
+				return nil, fmt.Errorf("rede inválida %q: %w", part, err)
+			}
+			networks = append(networks, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(part)
+		if err != nil {
+			return nil, fmt.Errorf("IP inválido %q: %w", part, err)
+		}
+		addr = addr.Unmap()
+		networks = append(networks, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return networks, nil
+}
+
+// loadAccessList lê <NOME>_IPS e <NOME>_TOKENS, separados por vírgula
+func loadAccessList(env environment, name string, list *AccessList) error {
+	if ips := env.Get(name + "_IPS"); ips != "" {
+		networks, err := ParseNetworks(ips)
+		if err != nil {
+			return fmt.Errorf("%s_IPS inválido: %w", name, err)
+		}
+		list.Networks = networks
+	}
+	if tokens := env.Get(name + "_TOKENS"); tokens != "" {
+		list.Tokens = nil
+		for _, token := range strings.Split(tokens, ",") {
+			if token = strings.TrimSpace(token); token != "" {
+				list.Tokens = append(list.Tokens, token)
+			}
+		}
+	}
+	return nil
+}
+
+// ParseKeySpec interpreta uma especificação de chave: alternativas separadas por |,
+// cada uma combinando fontes separadas por + (ex.: header:X-Tenant-ID+route|ip)
+func ParseKeySpec(value string) (KeySpec, error) {
+	var spec KeySpec
+	for _, alternative := range strings.Split(value, "|") {
+		var sources []KeySource
+		for _, part := range strings.Split(alternative, "+") {
+			kind, name, _ := strings.Cut(strings.TrimSpace(part), ":")
+			needsName, ok := validKeySources[kind]
+			if !ok {
+				return nil, fmt.Errorf("fonte de chave desconhecida: %q", part)
+			}
+			if needsName != (name != "") {
+				if needsName {
+					return nil, fmt.Errorf("fonte %s exige nome (ex.: %s:nome)", kind, kind)
+				}
+				return nil, fmt.Errorf("fonte %s não aceita nome", kind)
+			}
+			sources = append(sources, KeySource{Kind: kind, Name: name})
+		}
+		spec = append(spec, sources)
+	}
+	return spec, nil
+}
+
+// ParseRoutePattern interpreta um padrão de rota no formato [MÉTODO ]CAMINHO
+func ParseRoutePattern(value string) (RoutePattern, error) {
+	value = strings.TrimSpace(value)
+	var p RoutePattern
+	if method, pattern, ok := strings.Cut(value, " "); ok {
+		p = RoutePattern{Method: method, Pattern: strings.TrimSpace(pattern)}
+	} else {
+		p = RoutePattern{Pattern: value}
+	}
+
+	if !strings.HasPrefix(p.Pattern, "/") {
+		return RoutePattern{}, fmt.Errorf("padrão de rota deve começar com /: %q", value)
+	}
+	if p.IsGlob() {
+		if _, err := path.Match(p.Pattern, ""); err != nil {
+			return RoutePattern{}, fmt.Errorf("glob inválido %q: %w", value, err)
+		}
+		return p, nil
+	}
+	if err := validateMuxPattern(p.String()); err != nil {
+		return RoutePattern{}, fmt.Errorf("padrão inválido %q: %w", value, err)
+	}
+	return p, nil
+}
+
+// validateMuxPattern verifica se o http.ServeMux aceita o padrão (Handle entra em pânico com padrões inválidos)
+func validateMuxPattern(pattern string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	http.NewServeMux().Handle(pattern, http.NotFoundHandler())
+	return nil
+}
+
+// ParseRouteLimits interpreta limites por rota separados por ponto e vírgula
+// Formato de cada rota: [MÉTODO ]CAMINHO=LIMIT[/JANELA],BLOCK_SECONDS[,opção=valor...]
+// Além das opções dos tokens, aceita key=<fontes> com a identidade dos clientes da rota
+func ParseRouteLimits(value string) ([]RouteLimit, error) {
+	var routes []RouteLimit
+	for _, entry := range strings.Split(value, ";") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		patternStr, limitStr, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("esperado [MÉTODO ]CAMINHO=LIMITES, obtido %q", entry)
+		}
+		pattern, err := ParseRoutePattern(patternStr)
+		if err != nil {
+			return nil, err
+		}
+
+		route := RouteLimit{RoutePattern: pattern}
+		var limitParts []string
+		for _, part := range strings.Split(limitStr, ",") {
+			if spec, isKey := strings.CutPrefix(strings.TrimSpace(part), "key="); isKey {
+				if route.KeySource, err = ParseKeySpec(spec); err != nil {
+					return nil, fmt.Errorf("%s: %w", pattern, err)
+				}
+				continue
+			}
+			limitParts = append(limitParts, part)
+		}
+		if route.Limits, err = parseTokenLimit(strings.Join(limitParts, ",")); err != nil {
+			return nil, fmt.Errorf("%s: %w", pattern, err)
+		}
+		routes = append(routes, route)
+	}
+	return routes, nil
+}
+
+// parseTokenOptions aplica opções no formato chave=valor ao limite do token
+// Opções suportadas: algorithm=<nome do algoritmo>, burst=<capacidade>, quota=<LIMITE/PERÍODO>,
+// escalation=<DURAÇÃO+DURAÇÃO...>
+func parseTokenOptions(tokenLimit *TokenLimit, options []string) error {
+	for _, option := range options {
+		name, value, ok := strings.Cut(strings.TrimSpace(option), "=")
+		if !ok {
+			return fmt.Errorf("esperado chave=valor, obtido %q", option)
+		}
+
+		switch name {
+		case "algorithm":
+			if !validAlgorithms[value] {
+				return fmt.Errorf("algoritmo desconhecido: %s", value)
+			}
+			tokenLimit.Algorithm = value
+		case "burst":
+			burst, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("burst inválido: %w", err)
+			}
+			tokenLimit.Burst = burst
+		case "quota":
+			quota, err := ParseQuota(value)
+			if err != nil {
+				return err
+			}
+			tokenLimit.Quota = quota
+		case "escalation":
+			steps, err := ParseEscalation(value)
+			if err != nil {
+				return fmt.Errorf("escalation inválido: %w", err)
+			}
+			tokenLimit.Escalation = steps
+		default:
+			return fmt.Errorf("opção desconhecida: %s", name)
+		}
+	}
+	return nil
+}
+
 // GetTokenLimit retorna o limite configurado para um token específico
+// Tokens associados a um plano recebem os limites do plano com as redefinições do token
 func (c *Config) GetTokenLimit(token string) (TokenLimit, bool) {
 	limit, exists := c.TokenLimits[token]
-	return limit, exists
+	if !exists || limit.Plan == "" {
+		return limit, exists
+	}
+	return c.ResolvePlan(limit.Plan, limit.Overrides)
+}
+
+// ResolvePlan retorna os limites do plano com as redefinições aplicadas
+func (c *Config) ResolvePlan(plan string, overrides TokenOverrides) (TokenLimit, bool) {
+	limit, exists := c.Plans[plan]
+	if !exists {
+		return TokenLimit{}, false
+	}
+
+	limit.Plan = plan
+	if len(overrides.Rates) > 0 {
+		limit.Limit = overrides.Rates[0].Limit
+		limit.Window = overrides.Rates[0].Window
+		limit.Tiers = overrides.Rates[1:]
+	}
+	if overrides.BlockDurationSecs != nil {
+		limit.BlockDurationSecs = *overrides.BlockDurationSecs
+	}
+	if overrides.Algorithm != "" {
+		limit.Algorithm = overrides.Algorithm
+	}
+	if overrides.Burst > 0 {
+		limit.Burst = overrides.Burst
+	}
+	if overrides.Quota.Limit > 0 {
+		limit.Quota = overrides.Quota
+	}
+	if len(overrides.Escalation) > 0 {
+		limit.Escalation = overrides.Escalation
+	}
+	return limit, true
 }
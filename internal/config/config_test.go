@@ -2,7 +2,9 @@ package config
 
 import (
 	"os"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestLoadConfig_Success(t *testing.T) {
@@ -77,6 +79,14 @@ func TestLoadConfig_Defaults(t *testing.T) {
 	if cfg.DefaultBlockDurationIP != 300 {
 		t.Errorf("Padrão DefaultBlockDurationIP esperado 300, obtido %d", cfg.DefaultBlockDurationIP)
 	}
+
+	if cfg.RateLimitHeaders != HeadersLegacy {
+		t.Errorf("Padrão RateLimitHeaders esperado %s, obtido %s", HeadersLegacy, cfg.RateLimitHeaders)
+	}
+
+	if cfg.IPv4PrefixLength != 32 || cfg.IPv6PrefixLength != 64 {
+		t.Errorf("Padrão de prefixos esperado /32 e /64, obtido /%d e /%d", cfg.IPv4PrefixLength, cfg.IPv6PrefixLength)
+	}
 }
 
 func TestLoadConfig_MissingRedisAddr(t *testing.T) {
@@ -150,3 +160,679 @@ func TestGetTokenLimit(t *testing.T) {
 		t.Error("Token inexistente não deveria ser encontrado")
 	}
 }
+
+func TestLoadConfig_TokenBucketOptions(t *testing.T) {
+	// Setup
+	os.Setenv("REDIS_ADDR", "localhost:6379")
+	os.Setenv("DEFAULT_ALGORITHM_IP", "token_bucket")
+	os.Setenv("DEFAULT_BURST_IP", "20")
+	os.Setenv("API_KEY_bursty", "10,60,algorithm=token_bucket,burst=50")
+	defer func() {
+		os.Unsetenv("REDIS_ADDR")
+		os.Unsetenv("DEFAULT_ALGORITHM_IP")
+		os.Unsetenv("DEFAULT_BURST_IP")
+		os.Unsetenv("API_KEY_bursty")
+	}()
+
+	// Execute
+	cfg, err := LoadConfig()
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	if cfg.DefaultAlgorithmIP != "token_bucket" || cfg.DefaultBurstIP != 20 {
+		t.Errorf("Padrão IP esperado token_bucket/20, obtido %s/%d", cfg.DefaultAlgorithmIP, cfg.DefaultBurstIP)
+	}
+
+	limit := cfg.TokenLimits["bursty"]
+	if limit.Algorithm != "token_bucket" {
+		t.Errorf("Algoritmo esperado token_bucket, obtido '%s'", limit.Algorithm)
+	}
+	if limit.Burst != 50 {
+		t.Errorf("Burst esperado 50, obtido %d", limit.Burst)
+	}
+}
+
+func TestLoadConfig_InvalidAlgorithm(t *testing.T) {
+	// Setup
+	os.Setenv("REDIS_ADDR", "localhost:6379")
+	os.Setenv("API_KEY_badalgo", "10,60,algorithm=leaky")
+	defer func() {
+		os.Unsetenv("REDIS_ADDR")
+		os.Unsetenv("API_KEY_badalgo")
+	}()
+
+	// Execute
+	_, err := LoadConfig()
+
+	// Assert
+	if err == nil {
+		t.Error("Esperado erro com algoritmo desconhecido")
+	}
+}
+
+func TestLoadConfig_Window(t *testing.T) {
+	// Setup
+	os.Setenv("REDIS_ADDR", "localhost:6379")
+	os.Setenv("DEFAULT_RATE_LIMIT_IP", "100/1m")
+	os.Setenv("API_KEY_partner", "50000/d,3600")
+	defer func() {
+		os.Unsetenv("REDIS_ADDR")
+		os.Unsetenv("DEFAULT_RATE_LIMIT_IP")
+		os.Unsetenv("API_KEY_partner")
+	}()
+
+	// Execute
+	cfg, err := LoadConfig()
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	if cfg.DefaultRateLimitIP != 100 || cfg.DefaultWindowIP != time.Minute {
+		t.Errorf("Padrão IP esperado 100/1m, obtido %d/%v", cfg.DefaultRateLimitIP, cfg.DefaultWindowIP)
+	}
+
+	limit := cfg.TokenLimits["partner"]
+	if limit.Limit != 50000 || limit.Window != 24*time.Hour {
+		t.Errorf("Token esperado 50000/24h, obtido %d/%v", limit.Limit, limit.Window)
+	}
+}
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		value  string
+		limit  int
+		window time.Duration
+	}{
+		{"10", 10, time.Second},
+		{"10/s", 10, time.Second},
+		{"1000/1m", 1000, time.Minute},
+		{"1000/m", 1000, time.Minute},
+		{"20/30s", 20, 30 * time.Second},
+		{"5/2h", 5, 2 * time.Hour},
+		{"50000/d", 50000, 24 * time.Hour},
+		{"10/1m30s", 10, 90 * time.Second},
+	}
+
+	for _, tt := range tests {
+		limit, window, err := ParseRate(tt.value)
+		if err != nil {
+			t.Errorf("%s: erro inesperado: %v", tt.value, err)
+			continue
+		}
+		if limit != tt.limit || window != tt.window {
+			t.Errorf("%s: esperado %d/%v, obtido %d/%v", tt.value, tt.limit, tt.window, limit, window)
+		}
+	}
+
+	for _, invalid := range []string{"", "abc", "10/", "10/0s", "10/xyz", "-1/s"} {
+		if _, _, err := ParseRate(invalid); err == nil {
+			t.Errorf("%q: esperado erro", invalid)
+		}
+	}
+}
+
+func TestLoadConfig_MultipleRates(t *testing.T) {
+	// Setup
+	os.Setenv("REDIS_ADDR", "localhost:6379")
+	os.Setenv("DEFAULT_RATE_LIMIT_IP", "10/s+300/1m")
+	os.Setenv("API_KEY_tiered", "10/s+300/1m+10000/d,60")
+	defer func() {
+		os.Unsetenv("REDIS_ADDR")
+		os.Unsetenv("DEFAULT_RATE_LIMIT_IP")
+		os.Unsetenv("API_KEY_tiered")
+	}()
+
+	// Execute
+	cfg, err := LoadConfig()
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	if rates := cfg.DefaultRatesIP(); len(rates) != 2 || rates[1] != (Rate{Limit: 300, Window: time.Minute}) {
+		t.Errorf("Limites padrão IP inesperados: %+v", rates)
+	}
+
+	rates := cfg.TokenLimits["tiered"].Rates()
+	expected := []Rate{
+		{Limit: 10, Window: time.Second},
+		{Limit: 300, Window: time.Minute},
+		{Limit: 10000, Window: 24 * time.Hour},
+	}
+	if len(rates) != len(expected) {
+		t.Fatalf("Esperado %d limites, obtido %d", len(expected), len(rates))
+	}
+	for i := range expected {
+		if rates[i] != expected[i] {
+			t.Errorf("Limite %d esperado %+v, obtido %+v", i, expected[i], rates[i])
+		}
+	}
+}
+
+func TestParseRates_DuplicateWindow(t *testing.T) {
+	if _, err := ParseRates("10/s+20/1s"); err == nil {
+		t.Error("Esperado erro com janela repetida")
+	}
+}
+
+func TestLoadConfig_Quota(t *testing.T) {
+	// Setup
+	os.Setenv("REDIS_ADDR", "localhost:6379")
+	os.Setenv("QUOTA_TIMEZONE", "UTC")
+	os.Setenv("QUOTA_EXCEEDED_STATUS", "429")
+	os.Setenv("API_KEY_billing", "10,60,quota=50000/month")
+	defer func() {
+		os.Unsetenv("REDIS_ADDR")
+		os.Unsetenv("QUOTA_TIMEZONE")
+		os.Unsetenv("QUOTA_EXCEEDED_STATUS")
+		os.Unsetenv("API_KEY_billing")
+	}()
+
+	// Execute
+	cfg, err := LoadConfig()
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	if cfg.QuotaExceededStatus != 429 {
+		t.Errorf("QuotaExceededStatus esperado 429, obtido %d", cfg.QuotaExceededStatus)
+	}
+
+	quota := cfg.TokenLimits["billing"].Quota
+	if quota.Limit != 50000 || quota.Period != "month" {
+		t.Errorf("Cota esperada 50000/month, obtido %d/%s", quota.Limit, quota.Period)
+	}
+}
+
+func TestParseQuota_Invalid(t *testing.T) {
+	for _, invalid := range []string{"50000", "0/month", "10/year", "abc/day"} {
+		if _, err := ParseQuota(invalid); err == nil {
+			t.Errorf("%q: esperado erro", invalid)
+		}
+	}
+}
+
+func TestParseRouteCosts(t *testing.T) {
+	// Execute
+	costs, err := ParseRouteCosts("/export=50, /search=10")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if len(costs) != 2 {
+		t.Fatalf("Esperado 2 custos, obtido %d", len(costs))
+	}
+	if costs[0].PathPrefix != "/export" || costs[0].Cost != 50 {
+		t.Errorf("Custo esperado /export=50, obtido %s=%d", costs[0].PathPrefix, costs[0].Cost)
+	}
+	if costs[1].PathPrefix != "/search" || costs[1].Cost != 10 {
+		t.Errorf("Custo esperado /search=10, obtido %s=%d", costs[1].PathPrefix, costs[1].Cost)
+	}
+
+	for _, invalid := range []string{"export=50", "/export=0", "/export", "/export=abc"} {
+		if _, err := ParseRouteCosts(invalid); err == nil {
+			t.Errorf("%q: esperado erro", invalid)
+		}
+	}
+}
+
+func TestLoadConfig_RouteCostsExceedLimit(t *testing.T) {
+	// Setup
+	os.Setenv("REDIS_ADDR", "localhost:6379")
+	os.Setenv("DEFAULT_RATE_LIMIT_IP", "5")
+	defer func() {
+		os.Unsetenv("REDIS_ADDR")
+		os.Unsetenv("DEFAULT_RATE_LIMIT_IP")
+		os.Unsetenv("REQUEST_COSTS")
+		os.Unsetenv("ROUTE_LIMITS")
+	}()
+
+	tests := []struct {
+		name    string
+		costs   string
+		routes  string
+		wantErr bool
+	}{
+		{"dentro do limite padrão", "/export=5", "", false},
+		{"acima do limite padrão", "/export=50", "", true},
+		{"rota com limite próprio", "/export=50", "/export=100,60", false},
+		{"acima do limite da rota", "/api/export=50", "/api/=10,60", true},
+		// Rotas com método não substituem o limite padrão dos demais métodos
+		{"rota com método", "/export=50", "POST /export=100,60", true},
+	}
+
+	for _, tt := range tests {
+		os.Setenv("REQUEST_COSTS", tt.costs)
+		os.Setenv("ROUTE_LIMITS", tt.routes)
+
+		// Execute
+		_, err := LoadConfig()
+
+		// Assert
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: erro esperado %v, obtido %v", tt.name, tt.wantErr, err)
+		}
+	}
+}
+
+func TestLoadConfig_RateLimitHeaders(t *testing.T) {
+	// Setup
+	os.Setenv("REDIS_ADDR", "localhost:6379")
+	defer func() {
+		os.Unsetenv("REDIS_ADDR")
+		os.Unsetenv("RATELIMIT_HEADERS")
+	}()
+
+	// Execute / Assert
+	os.Setenv("RATELIMIT_HEADERS", "ietf")
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if cfg.RateLimitHeaders != HeadersIETF {
+		t.Errorf("RateLimitHeaders esperado %s, obtido %s", HeadersIETF, cfg.RateLimitHeaders)
+	}
+
+	os.Setenv("RATELIMIT_HEADERS", "draft")
+	if _, err := LoadConfig(); err == nil {
+		t.Error("Esperado erro com estilo de headers desconhecido")
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	// Execute
+	proxies, err := ParseTrustedProxies("10.0.0.0/8, 127.0.0.1,2001:db8::/32")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	want := []string{"10.0.0.0/8", "127.0.0.1/32", "2001:db8::/32"}
+	if len(proxies) != len(want) {
+		t.Fatalf("Esperadas %d redes, obtidas %d", len(want), len(proxies))
+	}
+	for i, prefix := range proxies {
+		if prefix.String() != want[i] {
+			t.Errorf("Rede %d esperada %s, obtida %s", i, want[i], prefix)
+		}
+	}
+
+	for _, invalid := range []string{"10.0.0.0/33", "proxy.local", ""} {
+		if _, err := ParseTrustedProxies(invalid); err == nil {
+			t.Errorf("Esperado erro para %q", invalid)
+		}
+	}
+}
+
+func TestLoadConfig_PrefixLengths(t *testing.T) {
+	// Setup
+	os.Setenv("REDIS_ADDR", "localhost:6379")
+	os.Setenv("IPV4_PREFIX_LENGTH", "24")
+	os.Setenv("IPV6_PREFIX_LENGTH", "56")
+	defer func() {
+		os.Unsetenv("REDIS_ADDR")
+		os.Unsetenv("IPV4_PREFIX_LENGTH")
+		os.Unsetenv("IPV6_PREFIX_LENGTH")
+	}()
+
+	// Execute
+	cfg, err := LoadConfig()
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if cfg.IPv4PrefixLength != 24 || cfg.IPv6PrefixLength != 56 {
+		t.Errorf("Prefixos esperados /24 e /56, obtidos /%d e /%d", cfg.IPv4PrefixLength, cfg.IPv6PrefixLength)
+	}
+
+	os.Setenv("IPV6_PREFIX_LENGTH", "129")
+	if _, err := LoadConfig(); err == nil {
+		t.Error("Esperado erro com prefixo IPv6 maior que 128")
+	}
+}
+
+func TestParseKeySpec(t *testing.T) {
+	// Execute
+	spec, err := ParseKeySpec("header:X-Tenant-ID+route|ip")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	want := KeySpec{
+		{{Kind: "header", Name: "X-Tenant-ID"}, {Kind: "route"}},
+		{{Kind: "ip"}},
+	}
+	if len(spec) != len(want) || len(spec[0]) != 2 || spec[0][0] != want[0][0] || spec[0][1] != want[0][1] || spec[1][0] != want[1][0] {
+		t.Errorf("Especificação esperada %+v, obtida %+v", want, spec)
+	}
+
+	for _, invalid := range []string{"", "header", "ip:x", "session:abc", "bearer+", "query:key|"} {
+		if _, err := ParseKeySpec(invalid); err == nil {
+			t.Errorf("Esperado erro para %q", invalid)
+		}
+	}
+}
+
+func TestLoadConfig_JWT(t *testing.T) {
+	// Setup
+	os.Setenv("REDIS_ADDR", "localhost:6379")
+	os.Setenv("JWT_SECRET", "s3cr3t")
+	os.Setenv("JWT_KEY_CLAIMS", "tenant_id, sub")
+	os.Setenv("JWT_INVALID_TOKEN_ACTION", "reject")
+	os.Setenv("JWT_PLAN_pro", "100/1m,60,burst=20")
+	os.Setenv("JWT_ISSUER", "https://auth.example.com")
+	os.Setenv("JWT_AUDIENCE", "ratelimit")
+	os.Setenv("JWT_REQUIRE_EXP", "true")
+	defer func() {
+		os.Unsetenv("REDIS_ADDR")
+		os.Unsetenv("JWT_SECRET")
+		os.Unsetenv("JWT_KEY_CLAIMS")
+		os.Unsetenv("JWT_INVALID_TOKEN_ACTION")
+		os.Unsetenv("JWT_PLAN_pro")
+		os.Unsetenv("JWT_ISSUER")
+		os.Unsetenv("JWT_AUDIENCE")
+		os.Unsetenv("JWT_REQUIRE_EXP")
+	}()
+
+	// Execute
+	cfg, err := LoadConfig()
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if !cfg.JWT.Enabled() {
+		t.Error("JWT deveria estar habilitado com JWT_SECRET")
+	}
+	if len(cfg.JWT.KeyClaims) != 2 || cfg.JWT.KeyClaims[0] != "tenant_id" || cfg.JWT.KeyClaims[1] != "sub" {
+		t.Errorf("KeyClaims inesperadas: %v", cfg.JWT.KeyClaims)
+	}
+	if cfg.JWT.PlanClaim != "plan" || cfg.JWT.InvalidTokenAction != JWTInvalidReject {
+		t.Errorf("Configuração JWT inesperada: %+v", cfg.JWT)
+	}
+	if cfg.JWT.Issuer != "https://auth.example.com" || cfg.JWT.Audience != "ratelimit" || !cfg.JWT.RequireExp {
+		t.Errorf("Claims registradas inesperadas: %+v", cfg.JWT)
+	}
+	plan, ok := cfg.JWT.Plans["pro"]
+	if !ok || plan.Limit != 100 || plan.Window != time.Minute || plan.BlockDurationSecs != 60 || plan.Burst != 20 {
+		t.Errorf("Plano pro inesperado: %+v", plan)
+	}
+
+	os.Setenv("JWT_REQUIRE_EXP", "talvez")
+	if _, err := LoadConfig(); err == nil {
+		t.Error("Esperado erro com JWT_REQUIRE_EXP inválido")
+	}
+
+	os.Setenv("JWT_REQUIRE_EXP", "true")
+	os.Setenv("JWT_INVALID_TOKEN_ACTION", "ignore")
+	if _, err := LoadConfig(); err == nil {
+		t.Error("Esperado erro com ação desconhecida para token inválido")
+	}
+}
+
+func TestParseRouteLimits(t *testing.T) {
+	// Execute
+	routes, err := ParseRouteLimits("POST /login=5/1m,300,key=ip; /api/=100,60,algorithm=token_bucket,key=header:X-Tenant-ID+route;/files/*.zip=2,10")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if len(routes) != 3 {
+		t.Fatalf("Esperadas 3 rotas, obtidas %d", len(routes))
+	}
+
+	login := routes[0]
+	if login.Method != "POST" || login.Pattern != "/login" || login.Limits.Limit != 5 || login.Limits.Window != time.Minute || login.Limits.BlockDurationSecs != 300 {
+		t.Errorf("Rota de login inesperada: %+v", login)
+	}
+	if len(login.KeySource) != 1 || login.KeySource[0][0].Kind != "ip" {
+		t.Errorf("Fonte da chave de login inesperada: %+v", login.KeySource)
+	}
+
+	api := routes[1]
+	if api.Method != "" || api.Pattern != "/api/" || api.Limits.Algorithm != "token_bucket" || len(api.KeySource[0]) != 2 {
+		t.Errorf("Rota da API inesperada: %+v", api)
+	}
+
+	if !routes[2].IsGlob() || routes[2].String() != "/files/*.zip" {
+		t.Errorf("Rota glob inesperada: %+v", routes[2])
+	}
+
+	for _, invalid := range []string{"/login", "login=5,60", "/a/{x=5,60", "/files/[=5,60", "/x=5,60,key=session"} {
+		if _, err := ParseRouteLimits(invalid); err == nil {
+			t.Errorf("Esperado erro para %q", invalid)
+		}
+	}
+}
+
+func TestLoadConfig_RateLimitExclude(t *testing.T) {
+	// Setup
+	os.Setenv("REDIS_ADDR", "localhost:6379")
+	defer func() {
+		os.Unsetenv("REDIS_ADDR")
+		os.Unsetenv("RATE_LIMIT_EXCLUDE")
+	}()
+
+	// Execute / Assert - /health é excluída por padrão
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if len(cfg.RateLimitExclude) != 1 || cfg.RateLimitExclude[0].Pattern != "/health" {
+		t.Errorf("Exclusão padrão inesperada: %+v", cfg.RateLimitExclude)
+	}
+
+	os.Setenv("RATE_LIMIT_EXCLUDE", "/health, GET /metrics")
+	if cfg, err = LoadConfig(); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if len(cfg.RateLimitExclude) != 2 || cfg.RateLimitExclude[1].String() != "GET /metrics" {
+		t.Errorf("Exclusões inesperadas: %+v", cfg.RateLimitExclude)
+	}
+
+	os.Setenv("RATE_LIMIT_EXCLUDE", "")
+	if cfg, err = LoadConfig(); err != nil || len(cfg.RateLimitExclude) != 0 {
+		t.Errorf("RATE_LIMIT_EXCLUDE vazio deveria desabilitar exclusões: %+v, %v", cfg, err)
+	}
+}
+
+func TestLoadConfig_Plans(t *testing.T) {
+	// Setup
+	os.Setenv("REDIS_ADDR", "localhost:6379")
+	os.Setenv("PLAN_pro", "100/1m+5000/1d,60,quota=100000/month")
+	os.Setenv("API_KEY_plain", "plan=pro")
+	os.Setenv("API_KEY_custom", "plan=pro,limit=200/1m,block=0,burst=50")
+	defer func() {
+		os.Unsetenv("REDIS_ADDR")
+		os.Unsetenv("PLAN_pro")
+		os.Unsetenv("API_KEY_plain")
+		os.Unsetenv("API_KEY_custom")
+	}()
+
+	// Execute
+	cfg, err := LoadConfig()
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	plain, ok := cfg.GetTokenLimit("plain")
+	if !ok || plain.Plan != "pro" || plain.Limit != 100 || plain.Window != time.Minute || len(plain.Tiers) != 1 || plain.BlockDurationSecs != 60 {
+		t.Errorf("Token com plano inesperado: %+v", plain)
+	}
+	if plain.Quota.Limit != 100000 {
+		t.Errorf("Token deveria herdar a cota do plano: %+v", plain.Quota)
+	}
+
+	custom, ok := cfg.GetTokenLimit("custom")
+	if !ok || custom.Limit != 200 || len(custom.Tiers) != 0 || custom.BlockDurationSecs != 0 || custom.Burst != 50 {
+		t.Errorf("Redefinições do token não aplicadas: %+v", custom)
+	}
+	if custom.Quota.Limit != 100000 {
+		t.Errorf("Campos não redefinidos deveriam vir do plano: %+v", custom.Quota)
+	}
+
+	// Alterações no plano valem para todos os tokens associados
+	pro := cfg.Plans["pro"]
+	pro.Limit = 150
+	cfg.Plans["pro"] = pro
+	if plain, _ := cfg.GetTokenLimit("plain"); plain.Limit != 150 {
+		t.Errorf("Token deveria resolver o plano na consulta, limite %d", plain.Limit)
+	}
+}
+
+func TestLoadConfig_UnknownPlan(t *testing.T) {
+	// Setup
+	os.Setenv("REDIS_ADDR", "localhost:6379")
+	os.Setenv("API_KEY_orphan", "plan=enterprise")
+	defer func() {
+		os.Unsetenv("REDIS_ADDR")
+		os.Unsetenv("API_KEY_orphan")
+	}()
+
+	// Execute
+	_, err := LoadConfig()
+
+	// Assert
+	if err == nil {
+		t.Error("Esperado erro com plano desconhecido")
+	}
+}
+
+func TestLoadConfig_AccessLists(t *testing.T) {
+	// Setup
+	os.Setenv("REDIS_ADDR", "localhost:6379")
+	os.Setenv("ALLOWLIST_IPS", "10.0.0.0/8, 192.168.1.10")
+	os.Setenv("ALLOWLIST_TOKENS", "monitoring")
+	os.Setenv("DENYLIST_TOKENS", "leaked1, leaked2,")
+	defer func() {
+		os.Unsetenv("REDIS_ADDR")
+		os.Unsetenv("ALLOWLIST_IPS")
+		os.Unsetenv("ALLOWLIST_TOKENS")
+		os.Unsetenv("DENYLIST_TOKENS")
+	}()
+
+	// Execute
+	cfg, err := LoadConfig()
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if len(cfg.AllowList.Networks) != 2 || cfg.AllowList.Networks[1].String() != "192.168.1.10/32" {
+		t.Errorf("Redes permitidas inesperadas: %v", cfg.AllowList.Networks)
+	}
+	if len(cfg.AllowList.Tokens) != 1 || cfg.AllowList.Tokens[0] != "monitoring" {
+		t.Errorf("Tokens permitidos inesperados: %v", cfg.AllowList.Tokens)
+	}
+	if len(cfg.DenyList.Tokens) != 2 || cfg.DenyList.Tokens[1] != "leaked2" {
+		t.Errorf("Tokens bloqueados inesperados: %v", cfg.DenyList.Tokens)
+	}
+
+	os.Setenv("DENYLIST_IPS", "10.0.0.0/33")
+	defer os.Unsetenv("DENYLIST_IPS")
+	if _, err := LoadConfig(); err == nil {
+		t.Error("Esperado erro com rede inválida em DENYLIST_IPS")
+	}
+}
+
+func TestLoadConfig_Escalation(t *testing.T) {
+	// Setup
+	os.Setenv("REDIS_ADDR", "localhost:6379")
+	os.Setenv("DEFAULT_BLOCK_ESCALATION_IP", "1m+5m+1h+1d")
+	os.Setenv("BLOCK_ESCALATION_LOOKBACK", "6h")
+	os.Setenv("PLAN_pro", "100/1m,60,escalation=2m+10m")
+	os.Setenv("API_KEY_plain", "plan=pro")
+	os.Setenv("API_KEY_strict", "plan=pro,escalation=1h")
+	os.Setenv("API_KEY_own", "10,5,escalation=30s+1m")
+	defer func() {
+		os.Unsetenv("REDIS_ADDR")
+		os.Unsetenv("DEFAULT_BLOCK_ESCALATION_IP")
+		os.Unsetenv("BLOCK_ESCALATION_LOOKBACK")
+		os.Unsetenv("PLAN_pro")
+		os.Unsetenv("API_KEY_plain")
+		os.Unsetenv("API_KEY_strict")
+		os.Unsetenv("API_KEY_own")
+	}()
+
+	// Execute
+	cfg, err := LoadConfig()
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	want := []time.Duration{time.Minute, 5 * time.Minute, time.Hour, 24 * time.Hour}
+	if !reflect.DeepEqual(cfg.DefaultBlockEscalationIP, want) {
+		t.Errorf("Escalonamento por IP esperado %v, obtido %v", want, cfg.DefaultBlockEscalationIP)
+	}
+	if cfg.EscalationLookback != 6*time.Hour {
+		t.Errorf("Período esperado 6h, obtido %v", cfg.EscalationLookback)
+	}
+	if plain, _ := cfg.GetTokenLimit("plain"); !reflect.DeepEqual(plain.Escalation, []time.Duration{2 * time.Minute, 10 * time.Minute}) {
+		t.Errorf("Token deveria herdar o escalonamento do plano: %v", plain.Escalation)
+	}
+	if strict, _ := cfg.GetTokenLimit("strict"); !reflect.DeepEqual(strict.Escalation, []time.Duration{time.Hour}) {
+		t.Errorf("Token deveria redefinir o escalonamento do plano: %v", strict.Escalation)
+	}
+	if own, _ := cfg.GetTokenLimit("own"); len(own.Escalation) != 2 || own.Escalation[0] != 30*time.Second {
+		t.Errorf("Escalonamento do token inesperado: %v", own.Escalation)
+	}
+
+	os.Setenv("DEFAULT_BLOCK_ESCALATION_IP", "1m+0s")
+	if _, err := LoadConfig(); err == nil {
+		t.Error("Esperado erro com duração não positiva em DEFAULT_BLOCK_ESCALATION_IP")
+	}
+}
+
+func TestLoadConfig_StoreFailure(t *testing.T) {
+	// Setup
+	os.Setenv("REDIS_ADDR", "localhost:6379")
+	defer os.Unsetenv("REDIS_ADDR")
+
+	// Execute & Assert - padrão fail-open
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if cfg.StoreFailure.Policy != StoreFailureOpen || cfg.StoreFailure.LocalPercent != 50 {
+		t.Errorf("Padrão esperado open com 50%%, obtido %+v", cfg.StoreFailure)
+	}
+
+	os.Setenv("STORE_FAILURE_POLICY", "local")
+	os.Setenv("STORE_FAILURE_LOCAL_PERCENT", "25")
+	defer func() {
+		os.Unsetenv("STORE_FAILURE_POLICY")
+		os.Unsetenv("STORE_FAILURE_LOCAL_PERCENT")
+	}()
+	cfg, err = LoadConfig()
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if cfg.StoreFailure.Policy != StoreFailureLocal || cfg.StoreFailure.LocalPercent != 25 {
+		t.Errorf("Esperado local com 25%%, obtido %+v", cfg.StoreFailure)
+	}
+
+	os.Setenv("STORE_FAILURE_LOCAL_PERCENT", "0")
+	if _, err := LoadConfig(); err == nil {
+		t.Error("Esperado erro com percentual local fora de 1 a 100")
+	}
+	os.Setenv("STORE_FAILURE_LOCAL_PERCENT", "25")
+	os.Setenv("STORE_FAILURE_POLICY", "fail")
+	if _, err := LoadConfig(); err == nil {
+		t.Error("Esperado erro com política desconhecida")
+	}
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// environment é a origem das variáveis de configuração
+type environment interface {
+	Get(name string) string
+	Lookup(name string) (string, bool)
+	// Environ retorna as variáveis no formato NOME=valor
+	Environ() []string
+}
+
+// osEnvironment lê as variáveis de ambiente do processo
+type osEnvironment struct{}
+
+func (osEnvironment) Get(name string) string            { return os.Getenv(name) }
+func (osEnvironment) Lookup(name string) (string, bool) { return os.LookupEnv(name) }
+func (osEnvironment) Environ() []string                 { return os.Environ() }
+
+// flagEnvironment sobrepõe variáveis definidas por flags às do ambiente do processo
+type flagEnvironment struct {
+	values map[string]string
+}
+
+func (e flagEnvironment) Get(name string) string {
+	value, _ := e.Lookup(name)
+	return value
+}
+
+func (e flagEnvironment) Lookup(name string) (string, bool) {
+	if value, ok := e.values[name]; ok {
+		return value, true
+	}
+	return os.LookupEnv(name)
+}
+
+func (e flagEnvironment) Environ() []string {
+	var environ []string
+	for _, env := range os.Environ() {
+		name, _, _ := strings.Cut(env, "=")
+		if _, overridden := e.values[name]; !overridden {
+			environ = append(environ, env)
+		}
+	}
+	for name, value := range e.values {
+		environ = append(environ, name+"="+value)
+	}
+	return environ
+}
+
+// setFlags acumula as flags -set NOME=valor
+type setFlags map[string]string
+
+func (s setFlags) String() string {
+	return fmt.Sprint(map[string]string(s))
+}
+
+func (s setFlags) Set(value string) error {
+	name, v, ok := strings.Cut(value, "=")
+	if !ok || name == "" {
+		return fmt.Errorf("esperado NOME=valor, obtido %q", value)
+	}
+	s[name] = v
+	return nil
+}
+
+// LoadConfigArgs carrega a configuração como LoadConfig, aceitando flags de linha de comando:
+//
+//	-config <arquivo>  arquivo YAML ou JSON (sobrepõe CONFIG_FILE)
+//	-set NOME=valor    define uma variável de configuração, ex.: -set DEFAULT_RATE_LIMIT_IP=20 (repetível)
+//
+// Precedência: flags > variáveis de ambiente > arquivo > padrões
+func LoadConfigArgs(args []string) (*Config, error) {
+	values := setFlags{}
+	flags := flag.NewFlagSet("ratelimiter", flag.ContinueOnError)
+	configFile := flags.String("config", "", "arquivo de configuração YAML ou JSON")
+	flags.Var(values, "set", "define uma variável de configuração (NOME=valor)")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if *configFile != "" {
+		values["CONFIG_FILE"] = *configFile
+	}
+	return load(flagEnvironment{values: values})
+}
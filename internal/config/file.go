@@ -0,0 +1,500 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fileValue é um valor do arquivo de configuração com a linha onde aparece
+type fileValue[T any] struct {
+	Value T
+	Line  int
+	Set   bool
+}
+
+func (v *fileValue[T]) UnmarshalYAML(node *yaml.Node) error {
+	v.Line = node.Line
+	v.Set = true
+	return node.Decode(&v.Value)
+}
+
+// fileLimit são os limites de um bloco do arquivo (default, planos, tokens e rotas)
+type fileLimit struct {
+	Limit        fileValue[string] `yaml:"limit"`
+	BlockSeconds fileValue[int]    `yaml:"block_seconds"`
+	Algorithm    fileValue[string] `yaml:"algorithm"`
+	Burst        fileValue[int]    `yaml:"burst"`
+	Quota        fileValue[string] `yaml:"quota"`
+	Escalation   fileValue[string] `yaml:"escalation"`
+}
+
+type fileToken struct {
+	fileLimit `yaml:",inline"`
+	Plan      fileValue[string] `yaml:"plan"`
+}
+
+type fileRoute struct {
+	fileLimit `yaml:",inline"`
+	Route     fileValue[string] `yaml:"route"`
+	Key       fileValue[string] `yaml:"key"`
+}
+
+type fileJWT struct {
+	Secret             fileValue[string]    `yaml:"secret"`
+	PublicKeyFile      fileValue[string]    `yaml:"public_key_file"`
+	JWKSFile           fileValue[string]    `yaml:"jwks_file"`
+	KeyClaims          fileValue[[]string]  `yaml:"key_claims"`
+	PlanClaim          fileValue[string]    `yaml:"plan_claim"`
+	InvalidTokenAction fileValue[string]    `yaml:"invalid_token_action"`
+	Issuer             fileValue[string]    `yaml:"issuer"`
+	Audience           fileValue[string]    `yaml:"audience"`
+	RequireExp         fileValue[bool]      `yaml:"require_exp"`
+	Plans              map[string]fileLimit `yaml:"plans"`
+}
+
+type fileAccessList struct {
+	IPs    fileValue[[]string] `yaml:"ips"`
+	Tokens fileValue[[]string] `yaml:"tokens"`
+}
+
+type fileStoreFailure struct {
+	Policy       fileValue[string] `yaml:"policy"`
+	LocalPercent fileValue[int]    `yaml:"local_percent"`
+}
+
+type fileEscalation struct {
+	Lookback fileValue[string] `yaml:"lookback"`
+}
+
+type fileQuota struct {
+	Timezone       fileValue[string] `yaml:"timezone"`
+	ExceededStatus fileValue[int]    `yaml:"exceeded_status"`
+}
+
+// fileConfig é o esquema do arquivo de configuração (YAML ou JSON)
+// Os campos equivalem às variáveis de ambiente; campos desconhecidos são rejeitados
+type fileConfig struct {
+	RedisAddr        fileValue[string]         `yaml:"redis_addr"`
+	Default          fileLimit                 `yaml:"default"`
+	Quota            fileQuota                 `yaml:"quota"`
+	Escalation       fileEscalation            `yaml:"escalation"`
+	RequestCosts     fileValue[map[string]int] `yaml:"request_costs"`
+	Headers          fileValue[string]         `yaml:"headers"`
+	TrustedProxies   fileValue[[]string]       `yaml:"trusted_proxies"`
+	IPv4PrefixLength fileValue[int]            `yaml:"ipv4_prefix_length"`
+	IPv6PrefixLength fileValue[int]            `yaml:"ipv6_prefix_length"`
+	TokenKeySource   fileValue[string]         `yaml:"token_key_source"`
+	DefaultKeySource fileValue[string]         `yaml:"default_key_source"`
+	AdminToken       fileValue[string]         `yaml:"admin_token"`
+	JWT              fileJWT                   `yaml:"jwt"`
+	Plans            map[string]fileLimit      `yaml:"plans"`
+	Tokens           map[string]fileToken      `yaml:"tokens"`
+	Routes           []fileRoute               `yaml:"routes"`
+	Exclude          fileValue[[]string]       `yaml:"exclude"`
+	Allowlist        fileAccessList            `yaml:"allowlist"`
+	Denylist         fileAccessList            `yaml:"denylist"`
+	StoreFailure     fileStoreFailure          `yaml:"store_failure"`
+}
+
+// applyFile lê o arquivo de configuração e aplica seus valores sobre cfg
+func applyFile(cfg *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("erro ao ler arquivo de configuração: %w", err)
+	}
+	if err := applyFileData(cfg, data); err != nil {
+		return fmt.Errorf("arquivo de configuração %s: %w", path, err)
+	}
+	return nil
+}
+
+// applyFileData interpreta o conteúdo YAML ou JSON (JSON é um subconjunto de YAML)
+func applyFileData(cfg *Config, data []byte) error {
+	var file fileConfig
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return translateYAMLError(err)
+	}
+	return file.apply(cfg)
+}
+
+// translateYAMLError remove o prefixo do yaml.v3 e padroniza as linhas ("line N" -> "linha N")
+func translateYAMLError(err error) error {
+	msg := strings.TrimPrefix(err.Error(), "yaml: ")
+	msg = strings.TrimPrefix(msg, "unmarshal errors:\n")
+	msg = strings.ReplaceAll(msg, "line ", "linha ")
+	lines := strings.Split(msg, "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+	return errors.New(strings.Join(lines, "; "))
+}
+
+// lineError associa o erro à linha e ao campo do arquivo
+func lineError(line int, field string, err error) error {
+	return fmt.Errorf("linha %d: %s: %w", line, field, err)
+}
+
+// apply valida os valores do arquivo e os copia para cfg
+func (f fileConfig) apply(cfg *Config) error {
+	if f.RedisAddr.Set {
+		cfg.RedisAddr = f.RedisAddr.Value
+	}
+
+	if f.Default.Limit.Set {
+		rates, err := ParseRates(f.Default.Limit.Value)
+		if err != nil {
+			return lineError(f.Default.Limit.Line, "default.limit", err)
+		}
+		cfg.DefaultRateLimitIP = rates[0].Limit
+		cfg.DefaultWindowIP = rates[0].Window
+		cfg.DefaultTiersIP = rates[1:]
+	}
+	if f.Default.BlockSeconds.Set {
+		cfg.DefaultBlockDurationIP = f.Default.BlockSeconds.Value
+	}
+	if f.Default.Algorithm.Set {
+		if !validAlgorithms[f.Default.Algorithm.Value] {
+			return lineError(f.Default.Algorithm.Line, "default.algorithm", fmt.Errorf("algoritmo desconhecido: %s", f.Default.Algorithm.Value))
+		}
+		cfg.DefaultAlgorithmIP = f.Default.Algorithm.Value
+	}
+	if f.Default.Burst.Set {
+		cfg.DefaultBurstIP = f.Default.Burst.Value
+	}
+	if f.Default.Escalation.Set {
+		steps, err := f.Default.escalation("default")
+		if err != nil {
+			return err
+		}
+		cfg.DefaultBlockEscalationIP = steps
+	}
+	if f.Escalation.Lookback.Set {
+		lookback, err := ParseWindow(f.Escalation.Lookback.Value)
+		if err != nil {
+			return lineError(f.Escalation.Lookback.Line, "escalation.lookback", err)
+		}
+		cfg.EscalationLookback = lookback
+	}
+	if f.Default.Quota.Set {
+		return lineError(f.Default.Quota.Line, "default.quota", errors.New("cotas valem apenas para planos e tokens"))
+	}
+
+	if f.Quota.Timezone.Set {
+		location, err := time.LoadLocation(f.Quota.Timezone.Value)
+		if err != nil {
+			return lineError(f.Quota.Timezone.Line, "quota.timezone", err)
+		}
+		cfg.QuotaTimezone = location
+	}
+	if f.Quota.ExceededStatus.Set {
+		if status := f.Quota.ExceededStatus.Value; status != 403 && status != 429 {
+			return lineError(f.Quota.ExceededStatus.Line, "quota.exceeded_status", fmt.Errorf("esperado 403 ou 429, obtido %d", status))
+		}
+		cfg.QuotaExceededStatus = f.Quota.ExceededStatus.Value
+	}
+
+	if f.RequestCosts.Set {
+		cfg.RouteCosts = nil
+		for prefix, cost := range f.RequestCosts.Value {
+			if !strings.HasPrefix(prefix, "/") || cost < 1 {
+				return lineError(f.RequestCosts.Line, "request_costs", fmt.Errorf("custo inválido para %q: %d", prefix, cost))
+			}
+			cfg.RouteCosts = append(cfg.RouteCosts, RouteCost{PathPrefix: prefix, Cost: cost})
+		}
+	}
+
+	if f.Headers.Set {
+		if !validHeaderStyles[f.Headers.Value] {
+			return lineError(f.Headers.Line, "headers", fmt.Errorf("esperado legacy, ietf, both ou none, obtido %q", f.Headers.Value))
+		}
+		cfg.RateLimitHeaders = f.Headers.Value
+	}
+
+	if f.TrustedProxies.Set {
+		proxies, err := ParseTrustedProxies(strings.Join(f.TrustedProxies.Value, ","))
+		if err != nil {
+			return lineError(f.TrustedProxies.Line, "trusted_proxies", err)
+		}
+		cfg.TrustedProxies = proxies
+	}
+	if f.IPv4PrefixLength.Set {
+		if length := f.IPv4PrefixLength.Value; length < 1 || length > 32 {
+			return lineError(f.IPv4PrefixLength.Line, "ipv4_prefix_length", fmt.Errorf("esperado 1 a 32, obtido %d", length))
+		}
+		cfg.IPv4PrefixLength = f.IPv4PrefixLength.Value
+	}
+	if f.IPv6PrefixLength.Set {
+		if length := f.IPv6PrefixLength.Value; length < 1 || length > 128 {
+			return lineError(f.IPv6PrefixLength.Line, "ipv6_prefix_length", fmt.Errorf("esperado 1 a 128, obtido %d", length))
+		}
+		cfg.IPv6PrefixLength = f.IPv6PrefixLength.Value
+	}
+
+	if f.StoreFailure.Policy.Set {
+		if !validStoreFailurePolicies[f.StoreFailure.Policy.Value] {
+			return lineError(f.StoreFailure.Policy.Line, "store_failure.policy", fmt.Errorf("esperado open, closed ou local, obtido %q", f.StoreFailure.Policy.Value))
+		}
+		cfg.StoreFailure.Policy = f.StoreFailure.Policy.Value
+	}
+	if f.StoreFailure.LocalPercent.Set {
+		if percent := f.StoreFailure.LocalPercent.Value; percent < 1 || percent > 100 {
+			return lineError(f.StoreFailure.LocalPercent.Line, "store_failure.local_percent", fmt.Errorf("esperado 1 a 100, obtido %d", percent))
+		}
+		cfg.StoreFailure.LocalPercent = f.StoreFailure.LocalPercent.Value
+	}
+
+	if f.TokenKeySource.Set {
+		spec, err := ParseKeySpec(f.TokenKeySource.Value)
+		if err != nil {
+			return lineError(f.TokenKeySource.Line, "token_key_source", err)
+		}
+		cfg.TokenKeySource = spec
+	}
+	if f.DefaultKeySource.Set {
+		spec, err := ParseKeySpec(f.DefaultKeySource.Value)
+		if err != nil {
+			return lineError(f.DefaultKeySource.Line, "default_key_source", err)
+		}
+		cfg.DefaultKeySource = spec
+	}
+
+	if f.AdminToken.Set {
+		cfg.AdminToken = f.AdminToken.Value
+	}
+
+	if err := f.JWT.apply(&cfg.JWT); err != nil {
+		return err
+	}
+
+	for name, plan := range f.Plans {
+		limit, err := plan.tokenLimit("plans." + name)
+		if err != nil {
+			return err
+		}
+		cfg.Plans[name] = limit
+	}
+
+	for token, entry := range f.Tokens {
+		limit, err := entry.tokenLimit("tokens."+token, cfg.Plans)
+		if err != nil {
+			return err
+		}
+		cfg.TokenLimits[token] = limit
+	}
+
+	if f.Routes != nil {
+		cfg.RouteLimits = nil
+	}
+	for i, route := range f.Routes {
+		limit, err := route.routeLimit(fmt.Sprintf("routes[%d]", i))
+		if err != nil {
+			return err
+		}
+		cfg.RouteLimits = append(cfg.RouteLimits, limit)
+	}
+
+	if err := f.Allowlist.apply(&cfg.AllowList, "allowlist"); err != nil {
+		return err
+	}
+	if err := f.Denylist.apply(&cfg.DenyList, "denylist"); err != nil {
+		return err
+	}
+
+	if f.Exclude.Set {
+		cfg.RateLimitExclude = nil
+		for _, value := range f.Exclude.Value {
+			pattern, err := ParseRoutePattern(value)
+			if err != nil {
+				return lineError(f.Exclude.Line, "exclude", err)
+			}
+			cfg.RateLimitExclude = append(cfg.RateLimitExclude, pattern)
+		}
+	}
+	return nil
+}
+
+func (f fileAccessList) apply(list *AccessList, field string) error {
+	if f.IPs.Set {
+		list.Networks = nil
+		if len(f.IPs.Value) > 0 {
+			networks, err := ParseNetworks(strings.Join(f.IPs.Value, ","))
+			if err != nil {
+				return lineError(f.IPs.Line, field+".ips", err)
+			}
+			list.Networks = networks
+		}
+	}
+	if f.Tokens.Set {
+		list.Tokens = f.Tokens.Value
+	}
+	return nil
+}
+
+func (f fileJWT) apply(jwt *JWTConfig) error {
+	if f.Secret.Set {
+		jwt.Secret = f.Secret.Value
+	}
+	if f.PublicKeyFile.Set {
+		jwt.PublicKeyFile = f.PublicKeyFile.Value
+	}
+	if f.JWKSFile.Set {
+		jwt.JWKSFile = f.JWKSFile.Value
+	}
+	if f.KeyClaims.Set {
+		if len(f.KeyClaims.Value) == 0 {
+			return lineError(f.KeyClaims.Line, "jwt.key_claims", errors.New("ao menos uma claim é obrigatória"))
+		}
+		jwt.KeyClaims = f.KeyClaims.Value
+	}
+	if f.PlanClaim.Set {
+		jwt.PlanClaim = f.PlanClaim.Value
+	}
+	if f.InvalidTokenAction.Set {
+		if action := f.InvalidTokenAction.Value; action != JWTInvalidFallback && action != JWTInvalidReject {
+			return lineError(f.InvalidTokenAction.Line, "jwt.invalid_token_action", fmt.Errorf("esperado fallback ou reject, obtido %q", action))
+		}
+		jwt.InvalidTokenAction = f.InvalidTokenAction.Value
+	}
+	if f.Issuer.Set {
+		jwt.Issuer = f.Issuer.Value
+	}
+	if f.Audience.Set {
+		jwt.Audience = f.Audience.Value
+	}
+	if f.RequireExp.Set {
+		jwt.RequireExp = f.RequireExp.Value
+	}
+	for name, plan := range f.Plans {
+		limit, err := plan.tokenLimit("jwt.plans." + name)
+		if err != nil {
+			return err
+		}
+		jwt.Plans[name] = limit
+	}
+	return nil
+}
+
+// tokenLimit converte um bloco com limites próprios (limit e block_seconds obrigatórios)
+func (f fileLimit) tokenLimit(field string) (TokenLimit, error) {
+	if !f.Limit.Set {
+		return TokenLimit{}, fmt.Errorf("%s: limit é obrigatório", field)
+	}
+	if !f.BlockSeconds.Set {
+		return TokenLimit{}, lineError(f.Limit.Line, field, errors.New("block_seconds é obrigatório"))
+	}
+
+	rates, err := ParseRates(f.Limit.Value)
+	if err != nil {
+		return TokenLimit{}, lineError(f.Limit.Line, field+".limit", err)
+	}
+	limit := TokenLimit{
+		Limit:             rates[0].Limit,
+		Window:            rates[0].Window,
+		BlockDurationSecs: f.BlockSeconds.Value,
+		Tiers:             rates[1:],
+	}
+	if err := f.applyOptions(&limit.Algorithm, &limit.Burst, &limit.Quota, &limit.Escalation, field); err != nil {
+		return TokenLimit{}, err
+	}
+	return limit, nil
+}
+
+// applyOptions valida algorithm, burst, quota e escalation do bloco
+func (f fileLimit) applyOptions(algorithm *string, burst *int, quota *Quota, escalation *[]time.Duration, field string) error {
+	if f.Algorithm.Set {
+		if !validAlgorithms[f.Algorithm.Value] {
+			return lineError(f.Algorithm.Line, field+".algorithm", fmt.Errorf("algoritmo desconhecido: %s", f.Algorithm.Value))
+		}
+		*algorithm = f.Algorithm.Value
+	}
+	if f.Burst.Set {
+		*burst = f.Burst.Value
+	}
+	if f.Quota.Set {
+		parsed, err := ParseQuota(f.Quota.Value)
+		if err != nil {
+			return lineError(f.Quota.Line, field+".quota", err)
+		}
+		*quota = parsed
+	}
+	if f.Escalation.Set {
+		steps, err := f.escalation(field)
+		if err != nil {
+			return err
+		}
+		*escalation = steps
+	}
+	return nil
+}
+
+// escalation interpreta os bloqueios progressivos (ex.: 1m+5m+1h+24h); vazio desativa
+func (f fileLimit) escalation(field string) ([]time.Duration, error) {
+	if f.Escalation.Value == "" {
+		return nil, nil
+	}
+	steps, err := ParseEscalation(f.Escalation.Value)
+	if err != nil {
+		return nil, lineError(f.Escalation.Line, field+".escalation", err)
+	}
+	return steps, nil
+}
+
+// tokenLimit converte um token: limites próprios ou um plano com redefinições
+func (f fileToken) tokenLimit(field string, plans map[string]TokenLimit) (TokenLimit, error) {
+	if !f.Plan.Set {
+		return f.fileLimit.tokenLimit(field)
+	}
+	if _, exists := plans[f.Plan.Value]; !exists {
+		return TokenLimit{}, lineError(f.Plan.Line, field+".plan", fmt.Errorf("plano desconhecido: %s", f.Plan.Value))
+	}
+
+	limit := TokenLimit{Plan: f.Plan.Value}
+	if f.Limit.Set {
+		rates, err := ParseRates(f.Limit.Value)
+		if err != nil {
+			return TokenLimit{}, lineError(f.Limit.Line, field+".limit", err)
+		}
+		limit.Overrides.Rates = rates
+	}
+	if f.BlockSeconds.Set {
+		block := f.BlockSeconds.Value
+		limit.Overrides.BlockDurationSecs = &block
+	}
+	overrides := &limit.Overrides
+	if err := f.applyOptions(&overrides.Algorithm, &overrides.Burst, &overrides.Quota, &overrides.Escalation, field); err != nil {
+		return TokenLimit{}, err
+	}
+	return limit, nil
+}
+
+// routeLimit converte uma rota com limites próprios
+func (f fileRoute) routeLimit(field string) (RouteLimit, error) {
+	if !f.Route.Set {
+		return RouteLimit{}, fmt.Errorf("%s: route é obrigatório", field)
+	}
+	pattern, err := ParseRoutePattern(f.Route.Value)
+	if err != nil {
+		return RouteLimit{}, lineError(f.Route.Line, field+".route", err)
+	}
+	limits, err := f.fileLimit.tokenLimit(field)
+	if err != nil {
+		return RouteLimit{}, err
+	}
+
+	route := RouteLimit{RoutePattern: pattern, Limits: limits}
+	if f.Key.Set {
+		if route.KeySource, err = ParseKeySpec(f.Key.Value); err != nil {
+			return RouteLimit{}, lineError(f.Key.Line, field+".key", err)
+		}
+	}
+	return route, nil
+}
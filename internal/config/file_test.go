@@ -0,0 +1,213 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeConfigFile grava o conteúdo em um arquivo temporário e retorna seu caminho
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Erro ao gravar arquivo: %v", err)
+	}
+	return path
+}
+
+func TestLoadConfig_YAMLFile(t *testing.T) {
+	// Setup
+	path := writeConfigFile(t, "config.yaml", `
+redis_addr: redis:6379
+default:
+  limit: 10/1s+100/1m
+  block_seconds: 60
+  algorithm: sliding_window_counter
+  escalation: 1m+5m+1h
+escalation:
+  lookback: 12h
+headers: ietf
+trusted_proxies: [10.0.0.0/8]
+plans:
+  pro:
+    limit: 100/1m
+    block_seconds: 30
+tokens:
+  abc123:
+    limit: "50"
+    block_seconds: 10
+  xyz:
+    plan: pro
+    burst: 20
+    escalation: 10m+1d
+routes:
+  - route: POST /login
+    limit: 5/1m
+    block_seconds: 300
+exclude: [/health, /metrics]
+allowlist:
+  ips: [10.1.0.0/16]
+denylist:
+  tokens: [leaked]
+store_failure:
+  policy: closed
+`)
+	os.Setenv("CONFIG_FILE", path)
+	defer os.Unsetenv("CONFIG_FILE")
+
+	// Execute
+	cfg, err := LoadConfig()
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if cfg.RedisAddr != "redis:6379" {
+		t.Errorf("RedisAddr esperado 'redis:6379', obtido '%s'", cfg.RedisAddr)
+	}
+	if cfg.DefaultRateLimitIP != 10 || len(cfg.DefaultTiersIP) != 1 || cfg.DefaultTiersIP[0].Window != time.Minute {
+		t.Errorf("Limites padrão inesperados: %d, %v", cfg.DefaultRateLimitIP, cfg.DefaultTiersIP)
+	}
+	if cfg.DefaultBlockDurationIP != 60 || cfg.DefaultAlgorithmIP != "sliding_window_counter" {
+		t.Errorf("Bloqueio/algoritmo inesperados: %d, %s", cfg.DefaultBlockDurationIP, cfg.DefaultAlgorithmIP)
+	}
+	if len(cfg.DefaultBlockEscalationIP) != 3 || cfg.DefaultBlockEscalationIP[2] != time.Hour || cfg.EscalationLookback != 12*time.Hour {
+		t.Errorf("Escalonamento inesperado: %v, %v", cfg.DefaultBlockEscalationIP, cfg.EscalationLookback)
+	}
+	if cfg.RateLimitHeaders != HeadersIETF {
+		t.Errorf("RateLimitHeaders esperado ietf, obtido %s", cfg.RateLimitHeaders)
+	}
+	if len(cfg.TrustedProxies) != 1 {
+		t.Errorf("Esperado 1 proxy confiável, obtido %d", len(cfg.TrustedProxies))
+	}
+	if limit, ok := cfg.GetTokenLimit("abc123"); !ok || limit.Limit != 50 || limit.BlockDurationSecs != 10 {
+		t.Errorf("Token abc123 inesperado: %+v", limit)
+	}
+	if limit, ok := cfg.GetTokenLimit("xyz"); !ok || limit.Limit != 100 || limit.Window != time.Minute || limit.Burst != 20 || len(limit.Escalation) != 2 {
+		t.Errorf("Token xyz inesperado: %+v", limit)
+	}
+	if len(cfg.RouteLimits) != 1 || cfg.RouteLimits[0].String() != "POST /login" || cfg.RouteLimits[0].Limits.Limit != 5 {
+		t.Errorf("Rotas inesperadas: %+v", cfg.RouteLimits)
+	}
+	if len(cfg.RateLimitExclude) != 2 {
+		t.Errorf("Esperado 2 exclusões, obtido %d", len(cfg.RateLimitExclude))
+	}
+	if len(cfg.AllowList.Networks) != 1 || len(cfg.DenyList.Tokens) != 1 {
+		t.Errorf("Listas inesperadas: %+v, %+v", cfg.AllowList, cfg.DenyList)
+	}
+	if cfg.StoreFailure.Policy != StoreFailureClosed || cfg.StoreFailure.LocalPercent != 50 {
+		t.Errorf("Política de falha inesperada: %+v", cfg.StoreFailure)
+	}
+}
+
+func TestLoadConfig_JSONFile(t *testing.T) {
+	// Setup
+	path := writeConfigFile(t, "config.json", `{
+	"redis_addr": "redis:6379",
+	"default": {"limit": "20", "block_seconds": 120},
+	"tokens": {"abc123": {"limit": "100/1m", "block_seconds": 30}}
+}`)
+	os.Setenv("CONFIG_FILE", path)
+	defer os.Unsetenv("CONFIG_FILE")
+
+	// Execute
+	cfg, err := LoadConfig()
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if cfg.DefaultRateLimitIP != 20 || cfg.DefaultBlockDurationIP != 120 {
+		t.Errorf("Limites padrão inesperados: %d, %d", cfg.DefaultRateLimitIP, cfg.DefaultBlockDurationIP)
+	}
+	if limit, ok := cfg.GetTokenLimit("abc123"); !ok || limit.Limit != 100 || limit.Window != time.Minute {
+		t.Errorf("Token abc123 inesperado: %+v", limit)
+	}
+}
+
+func TestLoadConfig_FilePrecedence(t *testing.T) {
+	// Setup: o ambiente sobrepõe o arquivo, as flags sobrepõem o ambiente
+	path := writeConfigFile(t, "config.yaml", `
+redis_addr: redis:6379
+default:
+  limit: "10"
+  block_seconds: 60
+headers: ietf
+`)
+	os.Setenv("DEFAULT_RATE_LIMIT_IP", "20")
+	os.Setenv("RATELIMIT_HEADERS", "both")
+	defer func() {
+		os.Unsetenv("DEFAULT_RATE_LIMIT_IP")
+		os.Unsetenv("RATELIMIT_HEADERS")
+	}()
+
+	// Execute
+	cfg, err := LoadConfigArgs([]string{"-config", path, "-set", "RATELIMIT_HEADERS=none"})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if cfg.DefaultRateLimitIP != 20 {
+		t.Errorf("DefaultRateLimitIP esperado 20 (ambiente), obtido %d", cfg.DefaultRateLimitIP)
+	}
+	if cfg.DefaultBlockDurationIP != 60 {
+		t.Errorf("DefaultBlockDurationIP esperado 60 (arquivo), obtido %d", cfg.DefaultBlockDurationIP)
+	}
+	if cfg.RateLimitHeaders != HeadersNone {
+		t.Errorf("RateLimitHeaders esperado none (flag), obtido %s", cfg.RateLimitHeaders)
+	}
+	if cfg.QuotaExceededStatus != 403 {
+		t.Errorf("QuotaExceededStatus esperado 403 (padrão), obtido %d", cfg.QuotaExceededStatus)
+	}
+}
+
+func TestLoadConfig_FileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"campo desconhecido", "redis_addr: redis:6379\ndefault:\n  limt: 10\n", "linha 3"},
+		{"tipo inválido", "redis_addr: redis:6379\ndefault:\n  block_seconds: dez\n", "linha 3"},
+		{"limite inválido", "redis_addr: redis:6379\ndefault:\n  limit: abc\n", "linha 3: default.limit"},
+		{"algoritmo inválido", "redis_addr: redis:6379\ntokens:\n  abc:\n    limit: \"5\"\n    block_seconds: 1\n    algorithm: foo\n", "linha 6: tokens.abc.algorithm"},
+		{"plano desconhecido", "redis_addr: redis:6379\ntokens:\n  abc:\n    plan: gold\n", "linha 4: tokens.abc.plan"},
+		{"escalonamento inválido", "redis_addr: redis:6379\nplans:\n  pro:\n    limit: \"5\"\n    block_seconds: 1\n    escalation: 1m+x\n", "linha 6: plans.pro.escalation"},
+		{"rede inválida", "redis_addr: redis:6379\ndenylist:\n  ips: [abc]\n", "linha 3: denylist.ips"},
+		{"política de falha inválida", "redis_addr: redis:6379\nstore_failure:\n  local_percent: 200\n", "linha 3: store_failure.local_percent"},
+		{"rota inválida", "redis_addr: redis:6379\nroutes:\n  - route: login\n    limit: \"5\"\n    block_seconds: 1\n", "linha 3: routes[0].route"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			os.Setenv("CONFIG_FILE", writeConfigFile(t, "config.yaml", tt.content))
+			defer os.Unsetenv("CONFIG_FILE")
+
+			// Execute
+			_, err := LoadConfig()
+
+			// Assert
+			if err == nil {
+				t.Fatal("Esperado erro, obtido nil")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Erro deveria conter %q, obtido %q", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestLoadConfigArgs_InvalidSet(t *testing.T) {
+	// Execute
+	_, err := LoadConfigArgs([]string{"-set", "SEM_VALOR"})
+
+	// Assert
+	if err == nil {
+		t.Error("Esperado erro para -set sem '=', obtido nil")
+	}
+}
@@ -0,0 +1,211 @@
+package config
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// reloadMetrics contabiliza as recargas de configuração (expvar "config_reload")
+var reloadMetrics = expvar.NewMap("config_reload")
+
+// restartFields são as configurações lidas apenas na inicialização do servidor
+var restartFields = map[string]bool{
+	"ConfigFile":        true,
+	"RedisAddr":         true,
+	"QuotaTimezone":     true,
+	"AdminToken":        true,
+	"JWT.Secret":        true,
+	"JWT.PublicKeyFile": true,
+	"JWT.JWKSFile":      true,
+	"JWT.Issuer":        true,
+	"JWT.Audience":      true,
+	"JWT.RequireExp":    true,
+	// A política de falha é aplicada ao limiter na inicialização
+	"StoreFailure.Policy":       true,
+	"StoreFailure.LocalPercent": true,
+}
+
+// Reloader mantém a configuração ativa e a substitui atomicamente a cada recarga.
+// Uma configuração inválida é descartada e a anterior continua ativa.
+type Reloader struct {
+	load    func() (*Config, error)
+	current atomic.Pointer[Config]
+	// mu serializa as recargas
+	mu sync.Mutex
+}
+
+// NewReloader cria um Reloader com a configuração inicial e a função que carrega as novas versões
+func NewReloader(cfg *Config, load func() (*Config, error)) *Reloader {
+	r := &Reloader{load: load}
+	r.current.Store(cfg)
+	return r
+}
+
+// Current retorna a configuração ativa
+func (r *Reloader) Current() *Config {
+	return r.current.Load()
+}
+
+// Reload carrega e valida a configuração e, se válida, a torna ativa
+// Retorna a descrição das mudanças (vazia se nada mudou)
+func (r *Reloader) Reload() ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cfg, err := r.load()
+	if err != nil {
+		reloadMetrics.Add("failure", 1)
+		return nil, err
+	}
+
+	changes := Diff(r.current.Load(), cfg)
+	r.current.Store(cfg)
+	reloadMetrics.Add("success", 1)
+	lastChanges := new(expvar.String)
+	lastChanges.Set(strings.Join(changes, "; "))
+	reloadMetrics.Set("last_changes", lastChanges)
+	return changes, nil
+}
+
+// reload recarrega a configuração registrando o motivo e o resultado no log
+func (r *Reloader) reload(reason string) {
+	changes, err := r.Reload()
+	switch {
+	case err != nil:
+		log.Printf("Recarga da configuração (%s) falhou, mantendo a anterior: %v", reason, err)
+	case len(changes) == 0:
+		log.Printf("Configuração recarregada (%s): sem mudanças", reason)
+	default:
+		log.Printf("Configuração recarregada (%s): %s", reason, strings.Join(changes, "; "))
+	}
+}
+
+// WatchSignals recarrega a configuração a cada sinal recebido (ex.: SIGHUP) até ctx ser cancelado
+func (r *Reloader) WatchSignals(ctx context.Context, signals ...os.Signal) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, signals...)
+	defer signal.Stop(ch)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case sig := <-ch:
+			r.reload(sig.String())
+		}
+	}
+}
+
+// WatchFile verifica o arquivo a cada intervalo e recarrega a configuração quando
+// sua data de modificação ou tamanho mudam, até ctx ser cancelado
+func (r *Reloader) WatchFile(ctx context.Context, path string, interval time.Duration) {
+	last, _ := os.Stat(path)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			info, err := os.Stat(path)
+			if err != nil || (last != nil && info.ModTime().Equal(last.ModTime()) && info.Size() == last.Size()) {
+				continue
+			}
+			last = info
+			r.reload("arquivo " + path + " alterado")
+		}
+	}
+}
+
+// Diff descreve as diferenças entre duas configurações
+// Valores de tokens e segredos não são incluídos, apenas o que mudou
+func Diff(old, updated *Config) []string {
+	changes := diffStruct("", reflect.ValueOf(*old), reflect.ValueOf(*updated))
+	for i, change := range changes {
+		name, _, _ := strings.Cut(change, ":")
+		if restartFields[name] {
+			changes[i] = change + " (requer reinício)"
+		}
+	}
+	return changes
+}
+
+func diffStruct(prefix string, old, updated reflect.Value) []string {
+	var changes []string
+	for i := range old.NumField() {
+		field := old.Type().Field(i)
+		name := prefix + field.Name
+		oldValue, newValue := old.Field(i), updated.Field(i)
+
+		switch {
+		case field.Name == "TokenLimits":
+			if change := diffTokens(oldValue.Interface().(map[string]TokenLimit), newValue.Interface().(map[string]TokenLimit)); change != "" {
+				changes = append(changes, name+": "+change)
+			}
+		case field.Type.Kind() == reflect.Struct:
+			changes = append(changes, diffStruct(name+".", oldValue, newValue)...)
+		case field.Type == reflect.TypeOf(map[string]TokenLimit{}):
+			if change := diffNames(oldValue.Interface().(map[string]TokenLimit), newValue.Interface().(map[string]TokenLimit)); change != "" {
+				changes = append(changes, name+": "+change)
+			}
+		case !reflect.DeepEqual(oldValue.Interface(), newValue.Interface()):
+			changes = append(changes, name+": alterado")
+		}
+	}
+	return changes
+}
+
+// diffTokens resume as mudanças nos tokens sem expor seus valores
+func diffTokens(old, updated map[string]TokenLimit) string {
+	var added, removed, changed int
+	for token, limit := range updated {
+		previous, exists := old[token]
+		switch {
+		case !exists:
+			added++
+		case !reflect.DeepEqual(previous, limit):
+			changed++
+		}
+	}
+	for token := range old {
+		if _, exists := updated[token]; !exists {
+			removed++
+		}
+	}
+	if added+removed+changed == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d adicionado(s), %d removido(s), %d alterado(s)", added, removed, changed)
+}
+
+// diffNames lista pelo nome os planos adicionados (+), removidos (-) e alterados (~)
+func diffNames(old, updated map[string]TokenLimit) string {
+	var changes []string
+	for name, limit := range updated {
+		previous, exists := old[name]
+		switch {
+		case !exists:
+			changes = append(changes, "+"+name)
+		case !reflect.DeepEqual(previous, limit):
+			changes = append(changes, "~"+name)
+		}
+	}
+	for name := range old {
+		if _, exists := updated[name]; !exists {
+			changes = append(changes, "-"+name)
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i][1:] < changes[j][1:] })
+	return strings.Join(changes, " ")
+}
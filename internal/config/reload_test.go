@@ -0,0 +1,108 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReloader_Reload(t *testing.T) {
+	// Setup
+	initial := defaultConfig()
+	updated := defaultConfig()
+	updated.DefaultRateLimitIP = 10
+	updated.TokenLimits["abc123"] = TokenLimit{Limit: 100, BlockDurationSecs: 60}
+	reloader := NewReloader(initial, func() (*Config, error) { return updated, nil })
+
+	// Execute
+	changes, err := reloader.Reload()
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if reloader.Current() != updated {
+		t.Error("Configuração recarregada deveria estar ativa")
+	}
+	want := []string{"DefaultRateLimitIP: alterado", "TokenLimits: 1 adicionado(s), 0 removido(s), 0 alterado(s)"}
+	if strings.Join(changes, "; ") != strings.Join(want, "; ") {
+		t.Errorf("Mudanças esperadas %v, obtidas %v", want, changes)
+	}
+}
+
+func TestReloader_InvalidConfigKeepsCurrent(t *testing.T) {
+	// Setup
+	initial := defaultConfig()
+	reloader := NewReloader(initial, func() (*Config, error) { return nil, errors.New("DEFAULT_RATE_LIMIT_IP inválido") })
+
+	// Execute
+	_, err := reloader.Reload()
+
+	// Assert
+	if err == nil {
+		t.Fatal("Esperado erro, obtido nil")
+	}
+	if reloader.Current() != initial {
+		t.Error("Configuração anterior deveria continuar ativa")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	// Setup
+	old := defaultConfig()
+	old.Plans["free"] = TokenLimit{Limit: 10}
+	old.Plans["pro"] = TokenLimit{Limit: 100}
+	updated := defaultConfig()
+	updated.Plans["pro"] = TokenLimit{Limit: 200}
+	updated.Plans["team"] = TokenLimit{Limit: 500}
+	updated.RedisAddr = "redis:6380"
+	updated.JWT.PlanClaim = "tier"
+	updated.StoreFailure.Policy = StoreFailureLocal
+
+	// Execute
+	changes := Diff(old, updated)
+
+	// Assert
+	want := []string{
+		"RedisAddr: alterado (requer reinício)",
+		"Plans: -free ~pro +team",
+		"JWT.PlanClaim: alterado",
+		"StoreFailure.Policy: alterado (requer reinício)",
+	}
+	if strings.Join(changes, "; ") != strings.Join(want, "; ") {
+		t.Errorf("Mudanças esperadas %v, obtidas %v", want, changes)
+	}
+	if len(Diff(old, old)) != 0 {
+		t.Errorf("Configurações iguais não deveriam ter mudanças: %v", Diff(old, old))
+	}
+}
+
+func TestReloader_WatchFile(t *testing.T) {
+	// Setup
+	path := writeConfigFile(t, "config.yaml", "default:\n  limit: \"5\"\n")
+	reloader := NewReloader(defaultConfig(), func() (*Config, error) {
+		cfg := defaultConfig()
+		return cfg, applyFile(cfg, path)
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go reloader.WatchFile(ctx, path, 10*time.Millisecond)
+
+	// Execute
+	time.Sleep(30 * time.Millisecond)
+	if err := os.WriteFile(path, []byte("default:\n  limit: \"50\"\n"), 0o600); err != nil {
+		t.Fatalf("Erro ao gravar arquivo: %v", err)
+	}
+
+	// Assert
+	deadline := time.Now().Add(2 * time.Second)
+	for reloader.Current().DefaultRateLimitIP != 50 {
+		if time.Now().After(deadline) {
+			t.Fatalf("DefaultRateLimitIP esperado 50 após alterar o arquivo, obtido %d", reloader.Current().DefaultRateLimitIP)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,380 @@
+package jwtauth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Algoritmos de assinatura suportados
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+	ES256 = "ES256"
+)
+
+var (
+	// ErrMalformed indica um token que não está no formato header.payload.assinatura
+	ErrMalformed = errors.New("token JWT malformado")
+	// ErrUnsupportedAlgorithm indica um alg diferente de HS256, RS256 e ES256 (inclusive none)
+	ErrUnsupportedAlgorithm = errors.New("algoritmo JWT não suportado")
+	// ErrInvalidSignature indica que nenhuma chave configurada valida a assinatura
+	ErrInvalidSignature = errors.New("assinatura JWT inválida")
+	// ErrExpired indica um token com exp no passado
+	ErrExpired = errors.New("token JWT expirado")
+	// ErrNotYetValid indica um token com nbf no futuro
+	ErrNotYetValid = errors.New("token JWT ainda não válido")
+	// ErrMissingExpiration indica um token sem exp quando a claim é obrigatória
+	ErrMissingExpiration = errors.New("token JWT sem exp")
+	// ErrInvalidIssuer indica um iss diferente do emissor configurado
+	ErrInvalidIssuer = errors.New("emissor JWT inválido")
+	// ErrInvalidAudience indica um aud que não contém a audiência configurada
+	ErrInvalidAudience = errors.New("audiência JWT inválida")
+)
+
+// Claims são as claims do payload de um token verificado
+type Claims map[string]any
+
+// String retorna a claim como texto (strings e números; outros tipos são ignorados)
+func (c Claims) String(name string) (string, bool) {
+	switch value := c[name].(type) {
+	case string:
+		return value, value != ""
+	case json.Number:
+		return value.String(), true
+	default:
+		return "", false
+	}
+}
+
+// verificationKey é uma chave capaz de verificar um algoritmo
+type verificationKey struct {
+	kid    string
+	alg    string
+	secret []byte
+	public crypto.PublicKey
+}
+
+// Verifier valida assinaturas e prazos de tokens JWT contra as chaves configuradas
+type Verifier struct {
+	keys []verificationKey
+	now  func() time.Time
+	// issuer e audience vazios não são verificados
+	issuer     string
+	audience   string
+	requireExp bool
+}
+
+// NewVerifier cria um verificador sem chaves
+func NewVerifier() *Verifier {
+	return &Verifier{now: time.Now}
+}
+
+// Len retorna o número de chaves configuradas
+func (v *Verifier) Len() int {
+	return len(v.keys)
+}
+
+// RequireIssuer exige que a claim iss seja igual a issuer (vazio: não verifica)
+func (v *Verifier) RequireIssuer(issuer string) {
+	v.issuer = issuer
+}
+
+// RequireAudience exige que a claim aud (texto ou lista) contenha audience (vazio: não verifica)
+func (v *Verifier) RequireAudience(audience string) {
+	v.audience = audience
+}
+
+// RequireExpiration define se tokens sem exp são rejeitados
+func (v *Verifier) RequireExpiration(required bool) {
+	v.requireExp = required
+}
+
+// AddHMACKey adiciona um segredo HS256 (kid vazio: vale para tokens sem kid ou com qualquer kid)
+func (v *Verifier) AddHMACKey(kid string, secret []byte) {
+	v.keys = append(v.keys, verificationKey{kid: kid, alg: HS256, secret: secret})
+}
+
+// AddPublicKey adiciona uma chave pública RSA (RS256) ou ECDSA P-256 (ES256)
+func (v *Verifier) AddPublicKey(kid string, public crypto.PublicKey) error {
+	switch key := public.(type) {
+	case *rsa.PublicKey:
+		v.keys = append(v.keys, verificationKey{kid: kid, alg: RS256, public: key})
+	case *ecdsa.PublicKey:
+		if key.Curve != elliptic.P256() {
+			return fmt.Errorf("%w: curva %s", ErrUnsupportedAlgorithm, key.Curve.Params().Name)
+		}
+		v.keys = append(v.keys, verificationKey{kid: kid, alg: ES256, public: key})
+	default:
+		return fmt.Errorf("%w: chave %T", ErrUnsupportedAlgorithm, public)
+	}
+	return nil
+}
+
+// AddPEM adiciona uma chave pública em PEM (PUBLIC KEY ou RSA PUBLIC KEY)
+func (v *Verifier) AddPEM(kid string, data []byte) error {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return errors.New("erro ao decodificar PEM: bloco não encontrado")
+	}
+
+	var public crypto.PublicKey
+	var err error
+	switch block.Type {
+	case "RSA PUBLIC KEY":
+		public, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		public, err = x509.ParsePKIXPublicKey(block.Bytes)
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao interpretar chave pública: %w", err)
+	}
+	return v.AddPublicKey(kid, public)
+}
+
+// jwk é uma chave no formato JSON Web Key (RFC 7517)
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+	// EC
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	// Simétrica
+	K string `json:"k"`
+}
+
+// AddJWKS adiciona as chaves de um documento JWKS ({"keys": [...]})
+// Chaves de uso diferente de assinatura (use=enc) são ignoradas
+func (v *Verifier) AddJWKS(data []byte) error {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return fmt.Errorf("erro ao interpretar JWKS: %w", err)
+	}
+
+	for i, key := range set.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+		if err := v.addJWK(key); err != nil {
+			return fmt.Errorf("erro na chave %d do JWKS (kid=%q): %w", i, key.Kid, err)
+		}
+	}
+	return nil
+}
+
+func (v *Verifier) addJWK(key jwk) error {
+	switch key.Kty {
+	case "oct":
+		secret, err := base64.RawURLEncoding.DecodeString(key.K)
+		if err != nil || len(secret) == 0 {
+			return errors.New("segredo k inválido")
+		}
+		v.AddHMACKey(key.Kid, secret)
+		return nil
+	case "RSA":
+		n, errN := base64.RawURLEncoding.DecodeString(key.N)
+		e, errE := base64.RawURLEncoding.DecodeString(key.E)
+		if errN != nil || errE != nil || len(n) == 0 || len(e) == 0 || len(e) > 4 {
+			return errors.New("parâmetros n/e inválidos")
+		}
+		exponent := int(new(big.Int).SetBytes(e).Int64())
+		return v.AddPublicKey(key.Kid, &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: exponent})
+	case "EC":
+		if key.Crv != "P-256" {
+			return fmt.Errorf("%w: curva %s", ErrUnsupportedAlgorithm, key.Crv)
+		}
+		x, errX := base64.RawURLEncoding.DecodeString(key.X)
+		y, errY := base64.RawURLEncoding.DecodeString(key.Y)
+		if errX != nil || errY != nil || len(x) != 32 || len(y) != 32 {
+			return errors.New("coordenadas x/y inválidas")
+		}
+		public, err := ecdsa.ParseUncompressedPublicKey(elliptic.P256(), append(append([]byte{4}, x...), y...))
+		if err != nil {
+			return fmt.Errorf("ponto EC inválido: %w", err)
+		}
+		return v.AddPublicKey(key.Kid, public)
+	default:
+		return fmt.Errorf("%w: kty %s", ErrUnsupportedAlgorithm, key.Kty)
+	}
+}
+
+// Verify valida a assinatura, exp, nbf, iss e aud do token e retorna suas claims
+// O algoritmo do header precisa corresponder ao tipo da chave, evitando a
+// confusão entre chave pública RSA e segredo HMAC.
+func (v *Verifier) Verify(token string) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformed
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+	if header.Alg != HS256 && header.Alg != RS256 && header.Alg != ES256 {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, header.Alg)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	signed := []byte(parts[0] + "." + parts[1])
+
+	verified := false
+	for _, key := range v.keys {
+		if key.alg != header.Alg || (key.kid != "" && header.Kid != "" && key.kid != header.Kid) {
+			continue
+		}
+		if key.verify(signed, signature) {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, ErrInvalidSignature
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, err
+	}
+
+	now := v.now()
+	exp, hasExp := claims.time("exp")
+	if !hasExp && v.requireExp {
+		return nil, ErrMissingExpiration
+	}
+	if hasExp && !now.Before(exp) {
+		return nil, ErrExpired
+	}
+	if nbf, ok := claims.time("nbf"); ok && now.Before(nbf) {
+		return nil, ErrNotYetValid
+	}
+	if v.issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != v.issuer {
+			return nil, ErrInvalidIssuer
+		}
+	}
+	if v.audience != "" && !claims.hasAudience(v.audience) {
+		return nil, ErrInvalidAudience
+	}
+	return claims, nil
+}
+
+// hasAudience informa se a claim aud (texto ou lista de textos) contém audience
+func (c Claims) hasAudience(audience string) bool {
+	switch aud := c["aud"].(type) {
+	case string:
+		return aud == audience
+	case []any:
+		for _, item := range aud {
+			if item == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// verify confere a assinatura com a chave
+func (k verificationKey) verify(signed, signature []byte) bool {
+	digest := sha256.Sum256(signed)
+	switch k.alg {
+	case HS256:
+		mac := hmac.New(sha256.New, k.secret)
+		mac.Write(signed)
+		return hmac.Equal(mac.Sum(nil), signature)
+	case RS256:
+		return rsa.VerifyPKCS1v15(k.public.(*rsa.PublicKey), crypto.SHA256, digest[:], signature) == nil
+	case ES256:
+		// JWS usa r||s com 32 bytes cada, e não DER
+		if len(signature) != 64 {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		return ecdsa.Verify(k.public.(*ecdsa.PublicKey), digest[:], r, s)
+	}
+	return false
+}
+
+// time interpreta uma claim NumericDate (segundos Unix)
+func (c Claims) time(name string) (time.Time, bool) {
+	number, ok := c[name].(json.Number)
+	if !ok {
+		return time.Time{}, false
+	}
+	seconds, err := strconv.ParseFloat(number.String(), 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(0, int64(seconds*float64(time.Second))), true
+}
+
+// decodeSegment decodifica um segmento base64url com JSON, preservando números
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return ErrMalformed
+	}
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.UseNumber()
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	return nil
+}
+
+// LoadVerifier cria um verificador com o segredo HS256 e as chaves dos arquivos
+// informados (PEM e JWKS). Parâmetros vazios são ignorados.
+func LoadVerifier(secret, publicKeyFile, jwksFile string) (*Verifier, error) {
+	v := NewVerifier()
+	if secret != "" {
+		v.AddHMACKey("", []byte(secret))
+	}
+	if publicKeyFile != "" {
+		data, err := os.ReadFile(publicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler chave pública: %w", err)
+		}
+		if err := v.AddPEM("", data); err != nil {
+			return nil, err
+		}
+	}
+	if jwksFile != "" {
+		data, err := os.ReadFile(jwksFile)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler JWKS: %w", err)
+		}
+		if err := v.AddJWKS(data); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,201 @@
+package jwtauth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+)
+
+var b64 = base64.RawURLEncoding
+
+// sign monta um token assinado com a chave informada (segredo HMAC, *rsa.PrivateKey ou *ecdsa.PrivateKey)
+func sign(t *testing.T, alg, kid string, claims map[string]any, key any) string {
+	t.Helper()
+	header, _ := json.Marshal(map[string]string{"alg": alg, "typ": "JWT", "kid": kid})
+	payload, _ := json.Marshal(claims)
+	signed := b64.EncodeToString(header) + "." + b64.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+
+	var signature []byte
+	switch k := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, k)
+		mac.Write([]byte(signed))
+		signature = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		var err error
+		if signature, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:]); err != nil {
+			t.Fatalf("Erro ao assinar RS256: %v", err)
+		}
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest[:])
+		if err != nil {
+			t.Fatalf("Erro ao assinar ES256: %v", err)
+		}
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+	return signed + "." + b64.EncodeToString(signature)
+}
+
+func TestVerifier_HS256(t *testing.T) {
+	// Setup
+	v := NewVerifier()
+	v.AddHMACKey("", []byte("s3cr3t"))
+	token := sign(t, HS256, "", map[string]any{"sub": "user-1", "plan": "pro"}, []byte("s3cr3t"))
+
+	// Execute
+	claims, err := v.Verify(token)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if sub, _ := claims.String("sub"); sub != "user-1" {
+		t.Errorf("sub esperado user-1, obtido %q", sub)
+	}
+
+	forged := sign(t, HS256, "", map[string]any{"sub": "user-1"}, []byte("outro"))
+	if _, err := v.Verify(forged); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Esperado ErrInvalidSignature, obtido %v", err)
+	}
+}
+
+func TestVerifier_RS256PEM(t *testing.T) {
+	// Setup
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Erro ao gerar chave: %v", err)
+	}
+	der, _ := x509.MarshalPKIXPublicKey(&private.PublicKey)
+	v := NewVerifier()
+	if err := v.AddPEM("", pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})); err != nil {
+		t.Fatalf("Erro ao adicionar PEM: %v", err)
+	}
+
+	// Execute / Assert
+	if _, err := v.Verify(sign(t, RS256, "", map[string]any{"sub": "user-1"}, private)); err != nil {
+		t.Errorf("Erro inesperado: %v", err)
+	}
+
+	// Confusão de algoritmo: HS256 assinado com a chave pública como segredo
+	confused := sign(t, HS256, "", map[string]any{"sub": "admin"}, der)
+	if _, err := v.Verify(confused); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Esperado ErrInvalidSignature, obtido %v", err)
+	}
+}
+
+func TestVerifier_JWKS(t *testing.T) {
+	// Setup
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ecPoint, _ := ecKey.PublicKey.Bytes()
+	jwks, _ := json.Marshal(map[string]any{"keys": []map[string]string{
+		{"kty": "RSA", "kid": "rsa-1", "n": b64.EncodeToString(rsaKey.N.Bytes()), "e": b64.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes())},
+		{"kty": "EC", "kid": "ec-1", "crv": "P-256", "x": b64.EncodeToString(ecPoint[1:33]), "y": b64.EncodeToString(ecPoint[33:])},
+		{"kty": "oct", "kid": "hs-1", "k": b64.EncodeToString([]byte("s3cr3t"))},
+		{"kty": "RSA", "kid": "enc-1", "use": "enc"},
+	}})
+	v := NewVerifier()
+	if err := v.AddJWKS(jwks); err != nil {
+		t.Fatalf("Erro ao carregar JWKS: %v", err)
+	}
+
+	// Execute / Assert
+	tokens := map[string]string{
+		"RS256": sign(t, RS256, "rsa-1", map[string]any{"sub": "a"}, rsaKey),
+		"ES256": sign(t, ES256, "ec-1", map[string]any{"sub": "b"}, ecKey),
+		"HS256": sign(t, HS256, "hs-1", map[string]any{"sub": "c"}, []byte("s3cr3t")),
+	}
+	for alg, token := range tokens {
+		if _, err := v.Verify(token); err != nil {
+			t.Errorf("%s: erro inesperado: %v", alg, err)
+		}
+	}
+
+	// kid de outra chave não verifica
+	wrongKid := sign(t, ES256, "rsa-1", map[string]any{"sub": "b"}, ecKey)
+	if _, err := v.Verify(wrongKid); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Esperado ErrInvalidSignature com kid trocado, obtido %v", err)
+	}
+}
+
+func TestVerifier_InvalidTokens(t *testing.T) {
+	// Setup
+	v := NewVerifier()
+	v.AddHMACKey("", []byte("s3cr3t"))
+	now := time.Now()
+	key := []byte("s3cr3t")
+
+	tests := []struct {
+		name  string
+		token string
+		want  error
+	}{
+		{"malformado", "abc.def", ErrMalformed},
+		{"alg none", b64.EncodeToString([]byte(`{"alg":"none"}`)) + "." + b64.EncodeToString([]byte(`{"sub":"x"}`)) + ".", ErrUnsupportedAlgorithm},
+		{"expirado", sign(t, HS256, "", map[string]any{"sub": "x", "exp": now.Add(-time.Minute).Unix()}, key), ErrExpired},
+		{"ainda não válido", sign(t, HS256, "", map[string]any{"sub": "x", "nbf": now.Add(time.Minute).Unix()}, key), ErrNotYetValid},
+	}
+
+	for _, tt := range tests {
+		// Execute
+		_, err := v.Verify(tt.token)
+
+		// Assert
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: esperado %v, obtido %v", tt.name, tt.want, err)
+		}
+	}
+
+	valid := sign(t, HS256, "", map[string]any{"sub": "x", "exp": now.Add(time.Minute).Unix()}, key)
+	if _, err := v.Verify(valid); err != nil {
+		t.Errorf("Token dentro do prazo deveria ser válido: %v", err)
+	}
+}
+
+func TestVerifier_RegisteredClaims(t *testing.T) {
+	// Setup
+	v := NewVerifier()
+	key := []byte("s3cr3t")
+	v.AddHMACKey("", key)
+	v.RequireIssuer("https://auth.example.com")
+	v.RequireAudience("ratelimit")
+	v.RequireExpiration(true)
+	exp := time.Now().Add(time.Minute).Unix()
+
+	tests := []struct {
+		name   string
+		claims map[string]any
+		want   error
+	}{
+		{"válido", map[string]any{"iss": "https://auth.example.com", "aud": "ratelimit", "exp": exp}, nil},
+		{"aud em lista", map[string]any{"iss": "https://auth.example.com", "aud": []string{"outra", "ratelimit"}, "exp": exp}, nil},
+		{"sem exp", map[string]any{"iss": "https://auth.example.com", "aud": "ratelimit"}, ErrMissingExpiration},
+		{"sem iss", map[string]any{"aud": "ratelimit", "exp": exp}, ErrInvalidIssuer},
+		{"iss diferente", map[string]any{"iss": "https://outro.example.com", "aud": "ratelimit", "exp": exp}, ErrInvalidIssuer},
+		{"sem aud", map[string]any{"iss": "https://auth.example.com", "exp": exp}, ErrInvalidAudience},
+		{"aud diferente", map[string]any{"iss": "https://auth.example.com", "aud": []string{"outra"}, "exp": exp}, ErrInvalidAudience},
+	}
+
+	for _, tt := range tests {
+		// Execute
+		_, err := v.Verify(sign(t, HS256, "", tt.claims, key))
+
+		// Assert
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: esperado %v, obtido %v", tt.name, tt.want, err)
+		}
+	}
+}
@@ -0,0 +1,371 @@
+package limiter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Algorithm identifica a estratégia de contagem usada por uma regra
+type Algorithm string
+
+const (
+	// AlgorithmFixedWindow conta requisições em janelas fixas
+	AlgorithmFixedWindow Algorithm = "fixed_window"
+	// AlgorithmTokenBucket permite rajadas até a capacidade com reposição contínua
+	AlgorithmTokenBucket Algorithm = "token_bucket"
+	// AlgorithmSlidingWindow estima a taxa ponderando a janela anterior e a atual
+	AlgorithmSlidingWindow Algorithm = "sliding_window_counter"
+	// AlgorithmSlidingLog registra o instante de cada requisição (preciso, mais memória)
+	AlgorithmSlidingLog Algorithm = "sliding_window_log"
+	// AlgorithmGCRA (generic cell rate algorithm) guarda apenas o instante teórico de chegada
+	AlgorithmGCRA Algorithm = "gcra"
+)
+
+// ErrUnsupportedAlgorithm indica algoritmo desconhecido ou não suportado pelo store
+var ErrUnsupportedAlgorithm = errors.New("algoritmo não suportado")
+
+// Rule descreve o limite aplicado a uma chave
+type Rule struct {
+	// Algorithm define a estratégia (padrão: fixed_window)
+	Algorithm Algorithm
+	// Limit é o número de requisições por janela (taxa de reposição no token bucket)
+	Limit int
+	// Window é a duração da janela (padrão: 1 segundo)
+	Window time.Duration
+	// Burst é a capacidade do token bucket e a rajada do GCRA (padrão: Limit)
+	Burst int
+	// BlockDuration é o tempo de bloqueio após exceder o limite
+	BlockDuration time.Duration
+	// Escalation aumenta o bloqueio das chaves reincidentes (vazio: sempre BlockDuration)
+	Escalation Escalation
+}
+
+// String descreve a regra no formato LIMITE/JANELA (ex.: 300/1m)
+func (r Rule) String() string {
+	return strconv.Itoa(r.Limit) + "/" + windowTag(r.Window)
+}
+
+// Capacity retorna a capacidade total da regra: a rajada do token bucket e do
+// GCRA quando configurada, senão o limite. É o maior custo que uma requisição pode ter.
+func (r Rule) Capacity() int64 {
+	if (r.Algorithm == AlgorithmTokenBucket || r.Algorithm == AlgorithmGCRA) && r.Burst > 0 {
+		return int64(r.Burst)
+	}
+	return int64(r.Limit)
+}
+
+// withDefaults preenche algoritmo e janela padrão
+func (r Rule) withDefaults() Rule {
+	if r.Algorithm == "" {
+		r.Algorithm = AlgorithmFixedWindow
+	}
+	if r.Window <= 0 {
+		r.Window = time.Second
+	}
+	return r
+}
+
+// stateKey monta a chave de estado de um algoritmo no formato <prefixo><chave>:<janela>
+// A janela faz parte da chave para que limites com janelas diferentes não compartilhem estado
+func stateKey(prefix, key string, rule Rule) string {
+	return prefix + key + ":" + windowTag(rule.Window)
+}
+
+// blockKeyFor monta a chave da flag de bloqueio, compartilhada por todas as regras da chave
+func blockKeyFor(key string) string {
+	return "rl:blk:" + key
+}
+
+// windowTag formata a janela de forma compacta (ex.: 1s, 5m, 1d)
+func windowTag(window time.Duration) string {
+	switch {
+	case window%(24*time.Hour) == 0:
+		return strconv.FormatInt(int64(window/(24*time.Hour)), 10) + "d"
+	case window%time.Hour == 0:
+		return strconv.FormatInt(int64(window/time.Hour), 10) + "h"
+	case window%time.Minute == 0:
+		return strconv.FormatInt(int64(window/time.Minute), 10) + "m"
+	case window%time.Second == 0:
+		return strconv.FormatInt(int64(window/time.Second), 10) + "s"
+	default:
+		return strconv.FormatInt(window.Milliseconds(), 10) + "ms"
+	}
+}
+
+// LimitAlgorithm implementa uma estratégia de rate limiting sobre o store
+// Novas estratégias podem ser registradas com CoreLimiter.RegisterAlgorithm
+type LimitAlgorithm interface {
+	// Allow consome n unidades da capacidade da chave e informa se a requisição é permitida
+	Allow(ctx context.Context, key string, rule Rule, n int64) (*BlockStatus, error)
+}
+
+// fixedWindow implementa contagem em janela fixa
+type fixedWindow struct {
+	store LimiterStoreStrategy
+}
+
+func (f *fixedWindow) Allow(ctx context.Context, key string, rule Rule, n int64) (*BlockStatus, error) {
+	// Chaves: contador da janela e flag de bloqueio por duração
+	counterKey := stateKey("rl:cnt:", key, rule)
+	blockKey := blockKeyFor(key)
+
+	// Store com suporte atômico: uma única operação, sem corrida entre réplicas
+	if atomicStore, ok := f.store.(AtomicStore); ok {
+		res, err := atomicStore.AllowAtomic(ctx, AtomicRequest{
+			CounterKey:    counterKey,
+			BlockKey:      blockKey,
+			Limit:         int64(rule.Limit),
+			Window:        rule.Window,
+			BlockDuration: rule.BlockDuration,
+			Cost:          n,
+			BlockReason:   rule.String(),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("erro na verificação atômica: %w", err)
+		}
+		return &BlockStatus{Allowed: !res.Blocked, Tripped: res.Tripped, CurrentCount: res.Count, RetryAfter: res.RetryAfter, ResetAt: resetAt(res.ResetAfter)}, nil
+	}
+
+	// Se estiver bloqueado, retorna 429
+	exists, err := f.store.Exists(ctx, blockKey)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao verificar bloqueio: %w", err)
+	}
+	if exists {
+		return &BlockStatus{Allowed: false, CurrentCount: 0}, nil
+	}
+
+	// Incrementa contador com o custo da requisição e a janela da regra
+	count, err := f.store.IncrementBy(ctx, counterKey, n, rule.Window)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao incrementar contador: %w", err)
+	}
+	// Fim da janela; sem ele, o reinício fica desconhecido
+	window, _ := f.store.TTL(ctx, counterKey)
+
+	if count > int64(rule.Limit) {
+		// Seta bloqueio pela duração configurada
+		if rule.BlockDuration > 0 {
+			if err := f.store.SetExpiring(ctx, blockKey, rule.String(), rule.BlockDuration); err != nil {
+				return nil, fmt.Errorf("erro ao setar bloqueio: %w", err)
+			}
+		}
+		return &BlockStatus{Allowed: false, Tripped: true, CurrentCount: count, ResetAt: resetAt(window)}, nil
+	}
+
+	return &BlockStatus{Allowed: true, CurrentCount: count, ResetAt: resetAt(window)}, nil
+}
+
+// allowFixedWindows verifica várias janelas fixas da mesma chave em uma única operação
+func allowFixedWindows(ctx context.Context, store MultiAtomicStore, key string, rules []Rule, n int64) (*BlockStatus, error) {
+	counters := make([]AtomicCounter, len(rules))
+	for i, rule := range rules {
+		counters[i] = AtomicCounter{
+			Key:           stateKey("rl:cnt:", key, rule),
+			Limit:         int64(rule.Limit),
+			Window:        rule.Window,
+			BlockDuration: rule.BlockDuration,
+			Description:   rule.String(),
+		}
+	}
+
+	res, err := store.AllowAtomicMulti(ctx, MultiAtomicRequest{BlockKey: blockKeyFor(key), Counters: counters, Cost: n})
+	if err != nil {
+		return nil, fmt.Errorf("erro na verificação atômica múltipla: %w", err)
+	}
+
+	// Bloqueio pré-existente: identifica a regra pela descrição gravada
+	if res.Blocked && res.Tripped < 0 {
+		status := &BlockStatus{Allowed: false, Rule: rules[0], RetryAfter: res.RetryAfter}
+		for _, rule := range rules {
+			if rule.String() == res.BlockedBy {
+				status.Rule = rule
+				break
+			}
+		}
+		return status, nil
+	}
+
+	// Negada: reporta a regra excedida; permitida: a regra mais próxima do limite
+	selected := res.Tripped
+	if selected < 0 {
+		selected = 0
+		for i := range rules {
+			if usage(res.Counts[i], rules[i].Limit) > usage(res.Counts[selected], rules[selected].Limit) {
+				selected = i
+			}
+		}
+	}
+	status := &BlockStatus{
+		Allowed:      !res.Blocked,
+		Tripped:      res.Tripped >= 0,
+		CurrentCount: res.Counts[selected],
+		RetryAfter:   res.RetryAfter,
+		Rule:         rules[selected],
+	}
+	if selected < len(res.Resets) {
+		status.ResetAt = resetAt(res.Resets[selected])
+	}
+	return status, nil
+}
+
+// resetAt converte o tempo até a capacidade total em instante (zero se desconhecido)
+func resetAt(after time.Duration) time.Time {
+	if after <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(after)
+}
+
+// usage retorna a fração consumida de um limite
+func usage(count int64, limit int) float64 {
+	if limit <= 0 {
+		return 1
+	}
+	return float64(count) / float64(limit)
+}
+
+// tokenBucket implementa o algoritmo token bucket
+// Requer um store com a capacidade TokenBucketStore
+type tokenBucket struct {
+	store LimiterStoreStrategy
+}
+
+func (t *tokenBucket) Allow(ctx context.Context, key string, rule Rule, n int64) (*BlockStatus, error) {
+	bucketStore, ok := t.store.(TokenBucketStore)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, AlgorithmTokenBucket)
+	}
+
+	req := tokenBucketRequest(key, rule, n)
+	res, err := bucketStore.TakeToken(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consumir token: %w", err)
+	}
+
+	// Tokens em uso equivalem ao contador dos demais algoritmos
+	return &BlockStatus{Allowed: !res.Blocked, Tripped: res.Tripped, CurrentCount: req.Capacity - res.Remaining, RetryAfter: res.RetryAfter, ResetAt: resetAt(res.ResetAfter)}, nil
+}
+
+// tokenBucketRequest monta a requisição do bucket da chave
+// A capacidade é a rajada da regra (padrão: Limit), reposta a Limit tokens por janela
+func tokenBucketRequest(key string, rule Rule, n int64) TokenBucketRequest {
+	capacity := int64(rule.Burst)
+	if capacity <= 0 {
+		capacity = int64(rule.Limit)
+	}
+
+	return TokenBucketRequest{
+		BucketKey:     stateKey("rl:tb:", key, rule),
+		BlockKey:      blockKeyFor(key),
+		Capacity:      capacity,
+		RefillRate:    float64(rule.Limit) / rule.Window.Seconds(),
+		BlockDuration: rule.BlockDuration,
+		Cost:          n,
+		BlockReason:   rule.String(),
+	}
+}
+
+// slidingWindow implementa a janela deslizante por contador
+// Requer um store com a capacidade SlidingWindowStore
+type slidingWindow struct {
+	store LimiterStoreStrategy
+}
+
+func (s *slidingWindow) Allow(ctx context.Context, key string, rule Rule, n int64) (*BlockStatus, error) {
+	windowStore, ok := s.store.(SlidingWindowStore)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, AlgorithmSlidingWindow)
+	}
+
+	res, err := windowStore.AllowSlidingWindow(ctx, windowRequest("rl:sw:", key, rule, n))
+	if err != nil {
+		return nil, fmt.Errorf("erro na janela deslizante: %w", err)
+	}
+	return &BlockStatus{Allowed: !res.Blocked, Tripped: res.Tripped, CurrentCount: res.Count, RetryAfter: res.RetryAfter, ResetAt: resetAt(res.ResetAfter)}, nil
+}
+
+// slidingLog implementa a janela deslizante por log de requisições
+// Requer um store com a capacidade SlidingLogStore
+type slidingLog struct {
+	store LimiterStoreStrategy
+}
+
+func (s *slidingLog) Allow(ctx context.Context, key string, rule Rule, n int64) (*BlockStatus, error) {
+	logStore, ok := s.store.(SlidingLogStore)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, AlgorithmSlidingLog)
+	}
+
+	res, err := logStore.AllowSlidingLog(ctx, windowRequest("rl:sl:", key, rule, n))
+	if err != nil {
+		return nil, fmt.Errorf("erro no log deslizante: %w", err)
+	}
+	return &BlockStatus{Allowed: !res.Blocked, Tripped: res.Tripped, CurrentCount: res.Count, RetryAfter: res.RetryAfter, ResetAt: resetAt(res.ResetAfter)}, nil
+}
+
+// windowRequest monta a requisição das janelas deslizantes com o prefixo de estado informado
+func windowRequest(prefix, key string, rule Rule, n int64) AtomicRequest {
+	return AtomicRequest{
+		CounterKey:    stateKey(prefix, key, rule),
+		BlockKey:      blockKeyFor(key),
+		Limit:         int64(rule.Limit),
+		Window:        rule.Window,
+		BlockDuration: rule.BlockDuration,
+		Cost:          n,
+		BlockReason:   rule.String(),
+	}
+}
+
+// gcra implementa o generic cell rate algorithm
+// Requer um store com a capacidade GCRAStore
+type gcra struct {
+	store LimiterStoreStrategy
+}
+
+func (g *gcra) Allow(ctx context.Context, key string, rule Rule, n int64) (*BlockStatus, error) {
+	gcraStore, ok := g.store.(GCRAStore)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, AlgorithmGCRA)
+	}
+	if rule.Limit <= 0 {
+		return &BlockStatus{Allowed: false}, nil
+	}
+
+	req, burst := gcraRequest(key, rule, n)
+	res, err := gcraStore.AllowGCRA(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("erro no GCRA: %w", err)
+	}
+
+	return &BlockStatus{
+		Allowed:      !res.Blocked,
+		Tripped:      res.Tripped,
+		CurrentCount: burst - res.Remaining,
+		RetryAfter:   res.RetryAfter,
+		ResetAt:      time.Now().Add(res.ResetAfter),
+	}, nil
+}
+
+// gcraRequest monta a requisição GCRA da chave e retorna a rajada aplicada
+// Requer rule.Limit positivo
+func gcraRequest(key string, rule Rule, n int64) (GCRARequest, int64) {
+	burst := int64(rule.Burst)
+	if burst <= 0 {
+		burst = int64(rule.Limit)
+	}
+	interval := rule.Window / time.Duration(rule.Limit)
+
+	return GCRARequest{
+		Key:              stateKey("rl:gcra:", key, rule),
+		BlockKey:         blockKeyFor(key),
+		EmissionInterval: interval,
+		Tolerance:        interval * time.Duration(burst),
+		BlockDuration:    rule.BlockDuration,
+		Cost:             n,
+		BlockReason:      rule.String(),
+	}, burst
+}
@@ -0,0 +1,130 @@
+package limiter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ManualBlock é o motivo registrado nos bloqueios criados por Block
+const ManualBlock = "manual"
+
+// BlockedKey é uma chave bloqueada no momento
+type BlockedKey struct {
+	Key string
+	// Rule é o limite que causou o bloqueio (ex.: 5/1s), ManualBlock ou vazio se desconhecido
+	Rule string
+	// BlockedUntil é o fim do bloqueio (zero se não expira)
+	BlockedUntil time.Time
+}
+
+// StateKeyer é implementado por algoritmos que informam as chaves de estado de uma
+// chave no store, permitindo zerá-las. Todos os algoritmos embutidos o implementam.
+type StateKeyer interface {
+	StateKeys(key string, rule Rule) []string
+}
+
+// Block bloqueia a chave manualmente pela duração informada, valendo para todas as regras
+func (c *CoreLimiter) Block(ctx context.Context, key string, duration time.Duration) error {
+	if duration <= 0 {
+		return errors.New("duração do bloqueio deve ser positiva")
+	}
+	if err := c.store.SetExpiring(ctx, blockKeyFor(key), ManualBlock, duration); err != nil {
+		return fmt.Errorf("erro ao bloquear chave: %w", err)
+	}
+	return nil
+}
+
+// Unblock remove o bloqueio da chave; retorna false se ela não estava bloqueada
+// Os contadores e as infrações são mantidos: com o limite ainda excedido, a chave volta a ser bloqueada
+func (c *CoreLimiter) Unblock(ctx context.Context, key string) (bool, error) {
+	deleted, err := c.store.Delete(ctx, blockKeyFor(key))
+	if err != nil {
+		return false, fmt.Errorf("erro ao desbloquear chave: %w", err)
+	}
+	return deleted > 0, nil
+}
+
+// BlockTTL retorna o tempo restante do bloqueio da chave (zero se não bloqueada ou sem expiração)
+func (c *CoreLimiter) BlockTTL(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := c.store.TTL(ctx, blockKeyFor(key))
+	if err != nil {
+		return 0, fmt.Errorf("erro ao consultar bloqueio: %w", err)
+	}
+	return ttl, nil
+}
+
+// Reset zera o estado da chave nas regras informadas e suas infrações, e remove seu bloqueio
+// Cotas de longo prazo não são afetadas
+func (c *CoreLimiter) Reset(ctx context.Context, key string, rules []Rule) error {
+	keys := []string{blockKeyFor(key), offenceKeyFor(key)}
+	for _, rule := range rules {
+		rule = rule.withDefaults()
+		algorithm, ok := c.algorithms[rule.Algorithm]
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, rule.Algorithm)
+		}
+		keyer, ok := algorithm.(StateKeyer)
+		if !ok {
+			return fmt.Errorf("%w: %s não permite reinício", ErrUnsupportedAlgorithm, rule.Algorithm)
+		}
+		keys = append(keys, keyer.StateKeys(key, rule)...)
+	}
+
+	if _, err := c.store.Delete(ctx, keys...); err != nil {
+		return fmt.Errorf("erro ao reiniciar chave: %w", err)
+	}
+	return nil
+}
+
+// BlockedKeys lista em páginas as chaves bloqueadas cujo nome começa com prefix (ex.: "ip:")
+// O cursor vazio inicia a listagem e o próximo cursor vazio indica o fim; uma página pode
+// vir vazia antes do fim. Requer um store com a capacidade KeyScanner.
+func (c *CoreLimiter) BlockedKeys(ctx context.Context, prefix string, cursor string, count int64) ([]BlockedKey, string, error) {
+	scanner, ok := c.store.(KeyScanner)
+	if !ok {
+		return nil, "", errors.New("store não permite listar chaves")
+	}
+
+	scanned, next, err := scanner.ScanKeys(ctx, blockKeyFor(prefix), cursor, count)
+	if err != nil {
+		return nil, "", fmt.Errorf("erro ao listar bloqueios: %w", err)
+	}
+
+	now := time.Now()
+	blocked := make([]BlockedKey, 0, len(scanned))
+	for _, s := range scanned {
+		b := BlockedKey{Key: strings.TrimPrefix(s.Key, blockKeyFor("")), Rule: s.Value}
+		// Bloqueios gravados antes do registro do limite guardam apenas "1"
+		if b.Rule == "1" {
+			b.Rule = ""
+		}
+		if s.TTL > 0 {
+			b.BlockedUntil = now.Add(s.TTL)
+		}
+		blocked = append(blocked, b)
+	}
+	return blocked, next, nil
+}
+
+func (f *fixedWindow) StateKeys(key string, rule Rule) []string {
+	return []string{stateKey("rl:cnt:", key, rule)}
+}
+
+func (t *tokenBucket) StateKeys(key string, rule Rule) []string {
+	return []string{stateKey("rl:tb:", key, rule)}
+}
+
+func (s *slidingWindow) StateKeys(key string, rule Rule) []string {
+	return []string{stateKey("rl:sw:", key, rule)}
+}
+
+func (s *slidingLog) StateKeys(key string, rule Rule) []string {
+	return []string{stateKey("rl:sl:", key, rule)}
+}
+
+func (g *gcra) StateKeys(key string, rule Rule) []string {
+	return []string{stateKey("rl:gcra:", key, rule)}
+}
@@ -0,0 +1,129 @@
+package limiter
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCoreLimiter_BlockUnblock(t *testing.T) {
+	// Setup
+	clock := newFakeClock()
+	limiter := NewCoreLimiter(newMemoryStore(clock.Now))
+	defer limiter.Close()
+	ctx := context.Background()
+	rule := Rule{Limit: 10, Window: time.Minute}
+
+	// Execute - bloqueio manual
+	if err := limiter.Block(ctx, "test:ip:manual", 10*time.Minute); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	blocked, _ := limiter.AllowRule(ctx, "test:ip:manual", rule)
+
+	unblocked, err := limiter.Unblock(ctx, "test:ip:manual")
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	allowed, _ := limiter.AllowRule(ctx, "test:ip:manual", rule)
+	again, _ := limiter.Unblock(ctx, "test:ip:manual")
+
+	// Assert
+	if blocked.Allowed {
+		t.Error("Chave bloqueada manualmente não deveria ser permitida")
+	}
+	if !unblocked {
+		t.Error("Unblock deveria informar que a chave estava bloqueada")
+	}
+	if !allowed.Allowed {
+		t.Error("Chave desbloqueada deveria ser permitida")
+	}
+	if again {
+		t.Error("Unblock de chave não bloqueada deveria retornar false")
+	}
+	if err := limiter.Block(ctx, "test:ip:manual", 0); err == nil {
+		t.Error("Bloqueio sem duração deveria retornar erro")
+	}
+}
+
+func TestCoreLimiter_Reset(t *testing.T) {
+	algorithms := []Algorithm{
+		AlgorithmFixedWindow,
+		AlgorithmTokenBucket,
+		AlgorithmSlidingWindow,
+		AlgorithmSlidingLog,
+		AlgorithmGCRA,
+	}
+
+	for _, algorithm := range algorithms {
+		t.Run(string(algorithm), func(t *testing.T) {
+			// Setup - esgota o limite e bloqueia a chave
+			clock := newFakeClock()
+			limiter := NewCoreLimiter(newMemoryStore(clock.Now))
+			defer limiter.Close()
+			ctx := context.Background()
+			rules := []Rule{{Algorithm: algorithm, Limit: 2, Window: time.Minute, BlockDuration: time.Minute}}
+			for i := 0; i < 3; i++ {
+				limiter.AllowRules(ctx, "test:ip:reset", rules)
+			}
+
+			// Execute
+			if err := limiter.Reset(ctx, "test:ip:reset", rules); err != nil {
+				t.Fatalf("Erro inesperado: %v", err)
+			}
+
+			// Assert
+			status, err := limiter.Status(ctx, "test:ip:reset", rules[0])
+			if err != nil {
+				t.Fatalf("Erro inesperado: %v", err)
+			}
+			if status.Blocked || status.Count != 0 || status.Remaining != 2 {
+				t.Errorf("Estado esperado zerado, obtido %+v", status)
+			}
+		})
+	}
+}
+
+func TestCoreLimiter_BlockedKeys(t *testing.T) {
+	// Setup - duas chaves bloqueadas por limite, uma manual e uma livre
+	clock := newFakeClock()
+	limiter := NewCoreLimiter(newMemoryStore(clock.Now))
+	defer limiter.Close()
+	ctx := context.Background()
+	rule := Rule{Limit: 1, Window: time.Second, BlockDuration: time.Minute}
+	for _, key := range []string{"ip:10.0.0.1", "ip:10.0.0.2", "ip:10.0.0.3"} {
+		for i := 0; i < 2; i++ {
+			limiter.AllowRule(ctx, key, rule)
+		}
+	}
+	limiter.Block(ctx, "token:abc123", time.Hour)
+	limiter.AllowRule(ctx, "ip:10.0.0.9", rule)
+
+	// Execute - páginas de 2 chaves filtradas por ip:
+	var keys []BlockedKey
+	var cursor string
+	pages := 0
+	for {
+		page, next, err := limiter.BlockedKeys(ctx, "ip:", cursor, 2)
+		if err != nil {
+			t.Fatalf("Erro inesperado: %v", err)
+		}
+		keys = append(keys, page...)
+		pages++
+		if cursor = next; cursor == "" {
+			break
+		}
+	}
+
+	// Assert
+	if len(keys) != 3 || pages != 2 {
+		t.Fatalf("Esperado 3 chaves em 2 páginas, obtido %d em %d: %+v", len(keys), pages, keys)
+	}
+	if keys[0].Key != "ip:10.0.0.1" || keys[0].Rule != "1/1s" || keys[0].BlockedUntil.IsZero() {
+		t.Errorf("Chave bloqueada inesperada: %+v", keys[0])
+	}
+
+	all, _, _ := limiter.BlockedKeys(ctx, "", "", 100)
+	if len(all) != 4 || all[3].Key != "token:abc123" || all[3].Rule != ManualBlock {
+		t.Errorf("Listagem sem filtro inesperada: %+v", all)
+	}
+}
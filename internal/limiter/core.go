@@ -2,69 +2,260 @@ package limiter
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync/atomic"
 	"time"
 )
 
 // CoreLimiter implementa a lógica principal de rate limiting
 type CoreLimiter struct {
-	store LimiterStoreStrategy
+	store      LimiterStoreStrategy
+	algorithms map[Algorithm]LimitAlgorithm
+
+	// Política aplicada quando o store falha (ver SetFailurePolicy)
+	failurePolicy FailurePolicy
+	fallback      *CoreLimiter
+	localPercent  int
+	// local identifica o limiter em memória usado por FailLocal, que não publica métricas
+	local    bool
+	degraded atomic.Bool
 }
 
 // NewCoreLimiter cria uma nova instância do CoreLimiter
 func NewCoreLimiter(store LimiterStoreStrategy) *CoreLimiter {
 	return &CoreLimiter{
-		store: store,
+		store:         store,
+		failurePolicy: FailOpen,
+		algorithms: map[Algorithm]LimitAlgorithm{
+			AlgorithmFixedWindow:   &fixedWindow{store: store},
+			AlgorithmTokenBucket:   &tokenBucket{store: store},
+			AlgorithmSlidingWindow: &slidingWindow{store: store},
+			AlgorithmSlidingLog:    &slidingLog{store: store},
+			AlgorithmGCRA:          &gcra{store: store},
+		},
 	}
 }
 
+// RegisterAlgorithm registra (ou substitui) uma estratégia de rate limiting
+// Deve ser chamado antes de o limiter começar a receber requisições
+func (c *CoreLimiter) RegisterAlgorithm(name Algorithm, algorithm LimitAlgorithm) {
+	c.algorithms[name] = algorithm
+}
+
 // BlockStatus representa o resultado da verificação de rate limit
 type BlockStatus struct {
 	Allowed       bool
 	CurrentCount  int64
 	Limit         int
 	BlockDuration time.Duration
+	// RetryAfter é o tempo até a próxima requisição ser permitida (quando conhecido)
+	RetryAfter time.Duration
+	// ResetAt é o instante em que a chave volta à capacidade total (quando conhecido)
+	ResetAt time.Time
+	// Rule é a regra que determinou o resultado: a excedida quando negada
+	Rule Rule
+	// Tripped indica que esta requisição excedeu o limite; false quando negada
+	// por um bloqueio já existente
+	Tripped bool
+	// Failure é a política de falha que decidiu o resultado; vazio com o store disponível
+	Failure FailurePolicy
 }
 
-// Allow verifica se a requisição deve ser permitida
+// Allow verifica se a requisição deve ser permitida usando janela fixa de 1 segundo
 // key: identificador único (IP ou Token)
 // limit: número máximo de requisições permitidas por segundo
 // blockDuration: tempo de bloqueio após exceder o limite
 func (c *CoreLimiter) Allow(ctx context.Context, key string, limit int, blockDuration time.Duration) (*BlockStatus, error) {
-	// Chaves: contador de 1s e flag de bloqueio por duração
-	counterKey := "rl:cnt:" + key
-	blockKey := "rl:blk:" + key
+	return c.AllowRule(ctx, key, Rule{
+		Algorithm:     AlgorithmFixedWindow,
+		Limit:         limit,
+		BlockDuration: blockDuration,
+	})
+}
+
+// AllowN é como Allow, mas a requisição consome n unidades do limite
+// (ex.: uma exportação em lote que vale 50 requisições). Valores de n menores que 1 valem 1.
+func (c *CoreLimiter) AllowN(ctx context.Context, key string, n int, limit int, blockDuration time.Duration) (*BlockStatus, error) {
+	return c.AllowRuleN(ctx, key, Rule{
+		Algorithm:     AlgorithmFixedWindow,
+		Limit:         limit,
+		BlockDuration: blockDuration,
+	}, n)
+}
+
+// AllowRule verifica se a requisição deve ser permitida segundo a regra informada
+// Em caso de erro do store o resultado segue a política de falha (padrão: fail-open)
+// e o erro é retornado
+func (c *CoreLimiter) AllowRule(ctx context.Context, key string, rule Rule) (*BlockStatus, error) {
+	return c.AllowRuleN(ctx, key, rule, 1)
+}
+
+// AllowRuleN verifica a requisição segundo a regra consumindo n unidades
+func (c *CoreLimiter) AllowRuleN(ctx context.Context, key string, rule Rule, n int) (*BlockStatus, error) {
+	rule = rule.withDefaults()
+	if status, err := costExceeded([]Rule{rule}, n); err != nil {
+		return status, err
+	}
+	status, err := c.allowRule(ctx, key, rule, n)
+	if err != nil {
+		return c.onFailure(ctx, key, []Rule{rule}, n, err)
+	}
+	c.recovered()
+	return status, nil
+}
+
+// allowRule verifica uma regra normalizada, retornando os erros do store sem aplicar a política de falha
+func (c *CoreLimiter) allowRule(ctx context.Context, key string, rule Rule, n int) (*BlockStatus, error) {
+	algorithm, ok := c.algorithms[rule.Algorithm]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, rule.Algorithm)
+	}
+
+	status, err := algorithm.Allow(ctx, key, rule, costOf(int64(n)))
+	if err != nil {
+		return nil, err
+	}
+
+	status.Rule = rule
+	status.Limit = rule.Limit
+	status.BlockDuration = rule.BlockDuration
+	c.escalate(ctx, key, status)
+	return status, nil
+}
+
+// AllowRules verifica a requisição contra vários limites simultâneos da mesma chave
+// (ex.: 10/s, 300/min e 10000/dia). A requisição é negada se qualquer regra for
+// excedida e BlockStatus.Rule informa qual delas. Quando todas as regras são de
+// janela fixa e o store suporta MultiAtomicStore, a verificação é atômica e uma
+// requisição negada não consome as demais janelas. Caso contrário, todas as regras
+// são consultadas antes do consumo e a primeira sem capacidade é verificada antes das
+// demais: a requisição negada não as consome, exceto quando requisições simultâneas
+// esgotam uma regra entre a consulta e o consumo.
+func (c *CoreLimiter) AllowRules(ctx context.Context, key string, rules []Rule) (*BlockStatus, error) {
+	return c.AllowRulesN(ctx, key, rules, 1)
+}
+
+// AllowRulesN verifica a requisição contra vários limites consumindo n unidades de cada
+// Um custo maior que a capacidade de alguma regra nunca seria permitido: a requisição é
+// negada com ErrCostExceedsLimit sem consumir capacidade nem criar bloqueio.
+func (c *CoreLimiter) AllowRulesN(ctx context.Context, key string, rules []Rule, n int) (*BlockStatus, error) {
+	switch len(rules) {
+	case 0:
+		return &BlockStatus{Allowed: true}, nil
+	case 1:
+		return c.AllowRuleN(ctx, key, rules[0], n)
+	}
 
-	// Se estiver bloqueado, retorna 429
-	exists, err := c.store.Exists(ctx, blockKey)
+	normalized := make([]Rule, len(rules))
+	allFixed := true
+	for i, rule := range rules {
+		normalized[i] = rule.withDefaults()
+		allFixed = allFixed && normalized[i].Algorithm == AlgorithmFixedWindow
+	}
+	if status, err := costExceeded(normalized, n); err != nil {
+		return status, err
+	}
+
+	status, err := c.allowRules(ctx, key, normalized, allFixed, n)
 	if err != nil {
-		// Fail-open
-		return &BlockStatus{Allowed: true, CurrentCount: 0, Limit: limit, BlockDuration: blockDuration}, fmt.Errorf("erro ao verificar bloqueio: %w", err)
+		// A política de falha considera todas as regras da chave
+		return c.onFailure(ctx, key, normalized, n, err)
+	}
+	c.recovered()
+	return status, nil
+}
+
+// allowRules verifica regras normalizadas, retornando os erros do store sem aplicar a política de falha
+func (c *CoreLimiter) allowRules(ctx context.Context, key string, rules []Rule, allFixed bool, n int) (*BlockStatus, error) {
+	if multiStore, ok := c.store.(MultiAtomicStore); ok && allFixed {
+		return c.allowAtomicWindows(ctx, multiStore, key, rules, n)
+	}
+
+	// Consulta todas as regras antes de consumir: a primeira sem capacidade é verificada
+	// antes das demais, e a negação não consome as regras seguintes
+	exhausted := -1
+	if len(rules) > 1 {
+		var err error
+		if exhausted, err = c.firstExhausted(ctx, key, rules, n); err != nil {
+			return nil, err
+		}
+	}
+	if exhausted > 0 {
+		ordered := append([]Rule{rules[exhausted]}, rules[:exhausted]...)
+		rules = append(ordered, rules[exhausted+1:]...)
+	}
+
+	var result *BlockStatus
+	for _, rule := range rules {
+		status, err := c.allowRule(ctx, key, rule, n)
+		if err != nil || !status.Allowed {
+			return status, err
+		}
+		if result == nil || usage(status.CurrentCount, rule.Limit) > usage(result.CurrentCount, result.Limit) {
+			result = status
+		}
 	}
-	if exists {
-		return &BlockStatus{Allowed: false, CurrentCount: 0, Limit: limit, BlockDuration: blockDuration}, nil
+	return result, nil
+}
+
+// firstExhausted retorna o índice da primeira regra sem capacidade para o custo,
+// consultada sem consumir (-1 se todas comportam o custo). Regras que não podem ser
+// consultadas (algoritmo sem LimitInspector ou store sem PeekStore) são ignoradas.
+func (c *CoreLimiter) firstExhausted(ctx context.Context, key string, rules []Rule, n int) (int, error) {
+	cost := costOf(int64(n))
+	for i, rule := range rules {
+		inspector, ok := c.algorithms[rule.Algorithm].(LimitInspector)
+		if !ok {
+			continue
+		}
+		status, err := inspector.Status(ctx, key, rule)
+		if errors.Is(err, ErrUnsupportedAlgorithm) {
+			continue
+		}
+		if err != nil {
+			return -1, err
+		}
+		if status.Remaining < cost {
+			return i, nil
+		}
 	}
+	return -1, nil
+}
 
-	// Incrementa contador com janela de 1 segundo
-	count, err := c.store.Increment(ctx, counterKey, time.Second)
+// allowAtomicWindows verifica janelas fixas normalizadas em uma única operação do store
+func (c *CoreLimiter) allowAtomicWindows(ctx context.Context, store MultiAtomicStore, key string, rules []Rule, n int) (*BlockStatus, error) {
+	status, err := allowFixedWindows(ctx, store, key, rules, costOf(int64(n)))
 	if err != nil {
-		// Fail-open
-		return &BlockStatus{Allowed: true, CurrentCount: 0, Limit: limit, BlockDuration: blockDuration}, fmt.Errorf("erro ao incrementar contador: %w", err)
+		return nil, err
 	}
+	status.Limit = status.Rule.Limit
+	status.BlockDuration = status.Rule.BlockDuration
+	c.escalate(ctx, key, status)
+	return status, nil
+}
 
-	if count > int64(limit) {
-		// Seta bloqueio pela duração configurada
-		if err := c.store.SetExpiring(ctx, blockKey, "1", blockDuration); err != nil {
-			// Fail-open
-			return &BlockStatus{Allowed: true, CurrentCount: count, Limit: limit, BlockDuration: blockDuration}, fmt.Errorf("erro ao setar bloqueio: %w", err)
+// costExceeded retorna o status negado e ErrCostExceedsLimit quando o custo não cabe
+// na capacidade de alguma das regras normalizadas
+func costExceeded(rules []Rule, n int) (*BlockStatus, error) {
+	cost := costOf(int64(n))
+	for _, rule := range rules {
+		if cost > rule.Capacity() {
+			return &BlockStatus{Limit: rule.Limit, Rule: rule}, fmt.Errorf("%w: %d > %s", ErrCostExceedsLimit, cost, rule)
 		}
-		return &BlockStatus{Allowed: false, CurrentCount: count, Limit: limit, BlockDuration: blockDuration}, nil
 	}
+	return nil, nil
+}
 
-	return &BlockStatus{Allowed: true, CurrentCount: count, Limit: limit, BlockDuration: blockDuration}, nil
+// failOpen retorna o status permissivo usado quando o store falha
+func failOpen(rule Rule) *BlockStatus {
+	return &BlockStatus{Allowed: true, Limit: rule.Limit, BlockDuration: rule.BlockDuration, Rule: rule}
 }
 
-// Close fecha a conexão com o store
+// Close fecha a conexão com o store (e o limiter local de FailLocal, se houver)
 func (c *CoreLimiter) Close() error {
+	if c.fallback != nil {
+		c.fallback.Close()
+	}
 	return c.store.Close()
 }
@@ -2,6 +2,7 @@ package limiter
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 )
@@ -168,3 +169,539 @@ func TestCoreLimiter_Close(t *testing.T) {
 		t.Errorf("Close não deveria retornar erro: %v", err)
 	}
 }
+
+func TestCoreLimiter_Allow_UsesAtomicStore(t *testing.T) {
+	// Setup
+	atomicStore := NewAtomicMockStore()
+	limiter := NewCoreLimiter(atomicStore)
+	ctx := context.Background()
+
+	key := "test:ip:192.168.1.5"
+	limit := 3
+	blockDuration := 10 * time.Second
+
+	// Execute - dentro do limite
+	for i := 1; i <= limit; i++ {
+		status, err := limiter.Allow(ctx, key, limit, blockDuration)
+		if err != nil {
+			t.Fatalf("Erro inesperado: %v", err)
+		}
+		if !status.Allowed {
+			t.Errorf("Requisição %d deveria ser permitida", i)
+		}
+		if status.CurrentCount != int64(i) {
+			t.Errorf("CurrentCount esperado %d, obtido %d", i, status.CurrentCount)
+		}
+	}
+
+	// Excede e permanece bloqueado
+	for i := 0; i < 2; i++ {
+		status, err := limiter.Allow(ctx, key, limit, blockDuration)
+		if err != nil {
+			t.Fatalf("Erro inesperado: %v", err)
+		}
+		if status.Allowed {
+			t.Error("Requisição deveria ser bloqueada")
+		}
+	}
+
+	// Assert - todas as chamadas passaram pelo caminho atômico
+	if atomicStore.atomicCalls != limit+2 {
+		t.Errorf("Esperado %d chamadas atômicas, obtido %d", limit+2, atomicStore.atomicCalls)
+	}
+}
+
+func TestCoreLimiter_Allow_AtomicFailOpen(t *testing.T) {
+	// Setup
+	atomicStore := NewAtomicMockStore()
+	atomicStore.SetShouldFail(true)
+	limiter := NewCoreLimiter(atomicStore)
+
+	// Execute
+	status, err := limiter.Allow(context.Background(), "test:ip:192.168.1.6", 5, time.Second)
+
+	// Assert
+	if err == nil {
+		t.Error("Esperado erro do mock store")
+	}
+	if !status.Allowed {
+		t.Error("Deveria permitir requisição em caso de erro (fail-open)")
+	}
+}
+
+func TestCoreLimiter_AllowRule_TokenBucket(t *testing.T) {
+	// Setup
+	clock := newFakeClock()
+	store := newMemoryStore(clock.Now)
+	limiter := NewCoreLimiter(store)
+	defer limiter.Close()
+	ctx := context.Background()
+
+	rule := Rule{Algorithm: AlgorithmTokenBucket, Limit: 1, Burst: 3}
+
+	// Execute - rajada até a capacidade
+	for i := 1; i <= 3; i++ {
+		status, err := limiter.AllowRule(ctx, "test:token:burst", rule)
+		if err != nil {
+			t.Fatalf("Erro inesperado: %v", err)
+		}
+		if !status.Allowed {
+			t.Errorf("Requisição %d deveria ser permitida", i)
+		}
+	}
+
+	status, _ := limiter.AllowRule(ctx, "test:token:burst", rule)
+	if status.Allowed {
+		t.Error("Requisição além da capacidade deveria ser bloqueada")
+	}
+
+	// Reposição de 1 token/s
+	clock.Advance(time.Second)
+	status, _ = limiter.AllowRule(ctx, "test:token:burst", rule)
+	if !status.Allowed {
+		t.Error("Requisição após reposição deveria ser permitida")
+	}
+}
+
+func TestCoreLimiter_AllowRule_UnsupportedAlgorithm(t *testing.T) {
+	// MockStore não implementa TokenBucketStore
+	limiter := NewCoreLimiter(NewMockStore())
+
+	status, err := limiter.AllowRule(context.Background(), "test:key", Rule{Algorithm: AlgorithmTokenBucket, Limit: 5})
+	if !errors.Is(err, ErrUnsupportedAlgorithm) {
+		t.Errorf("Esperado ErrUnsupportedAlgorithm, obtido %v", err)
+	}
+	if !status.Allowed {
+		t.Error("Deveria permitir requisição em caso de erro (fail-open)")
+	}
+
+	_, err = limiter.AllowRule(context.Background(), "test:key", Rule{Algorithm: "unknown", Limit: 5})
+	if !errors.Is(err, ErrUnsupportedAlgorithm) {
+		t.Errorf("Esperado ErrUnsupportedAlgorithm, obtido %v", err)
+	}
+}
+
+// boundaryBurst abre a janela em t=0 e envia 2*limit-1 requisições ao redor da
+// fronteira de 1s (t=0.9s e t=1.1s), retornando quantas foram permitidas nesse trecho
+func boundaryBurst(t *testing.T, algorithm Algorithm, limit int) int {
+	t.Helper()
+
+	clock := newFakeClock()
+	limiter := NewCoreLimiter(newMemoryStore(clock.Now))
+	defer limiter.Close()
+	ctx := context.Background()
+	rule := Rule{Algorithm: algorithm, Limit: limit}
+
+	send := func(n int) int {
+		allowed := 0
+		for i := 0; i < n; i++ {
+			status, err := limiter.AllowRule(ctx, "test:ip:boundary", rule)
+			if err != nil {
+				t.Fatalf("Erro inesperado: %v", err)
+			}
+			if status.Allowed {
+				allowed++
+			}
+		}
+		return allowed
+	}
+
+	send(1)
+	clock.Advance(900 * time.Millisecond)
+	allowed := send(limit - 1)
+	clock.Advance(200 * time.Millisecond)
+	return allowed + send(limit)
+}
+
+func TestCoreLimiter_BoundaryBurst_FixedWindow(t *testing.T) {
+	// Janela fixa: quase o dobro do limite passa em 200ms
+	if allowed := boundaryBurst(t, AlgorithmFixedWindow, 5); allowed != 9 {
+		t.Errorf("Janela fixa deveria permitir 9 requisições na fronteira, obtido %d", allowed)
+	}
+}
+
+func TestCoreLimiter_BoundaryBurst_SlidingWindow(t *testing.T) {
+	if allowed := boundaryBurst(t, AlgorithmSlidingWindow, 5); allowed > 5 {
+		t.Errorf("Janela deslizante não deveria permitir mais que 5 requisições, obtido %d", allowed)
+	}
+}
+
+func TestCoreLimiter_BoundaryBurst_SlidingLog(t *testing.T) {
+	if allowed := boundaryBurst(t, AlgorithmSlidingLog, 5); allowed != 5 {
+		t.Errorf("Log deslizante deveria permitir exatamente 5 requisições, obtido %d", allowed)
+	}
+}
+
+func TestCoreLimiter_AllowRule_GCRA(t *testing.T) {
+	// Setup
+	clock := newFakeClock()
+	limiter := NewCoreLimiter(newMemoryStore(clock.Now))
+	defer limiter.Close()
+	ctx := context.Background()
+
+	rule := Rule{Algorithm: AlgorithmGCRA, Limit: 4}
+
+	// Execute - rajada padrão igual ao limite
+	for i := 1; i <= 4; i++ {
+		status, err := limiter.AllowRule(ctx, "test:ip:gcra", rule)
+		if err != nil {
+			t.Fatalf("Erro inesperado: %v", err)
+		}
+		if !status.Allowed {
+			t.Errorf("Requisição %d deveria ser permitida", i)
+		}
+		if status.CurrentCount != int64(i) {
+			t.Errorf("CurrentCount esperado %d, obtido %d", i, status.CurrentCount)
+		}
+	}
+
+	// Assert - negada com RetryAfter de um intervalo de emissão
+	status, _ := limiter.AllowRule(ctx, "test:ip:gcra", rule)
+	if status.Allowed {
+		t.Error("Requisição além da rajada deveria ser bloqueada")
+	}
+	if status.RetryAfter != 250*time.Millisecond {
+		t.Errorf("RetryAfter esperado 250ms, obtido %v", status.RetryAfter)
+	}
+	if status.ResetAt.IsZero() {
+		t.Error("ResetAt deveria ser preenchido")
+	}
+}
+
+func TestCoreLimiter_AllowRule_Window(t *testing.T) {
+	// Setup
+	clock := newFakeClock()
+	limiter := NewCoreLimiter(newMemoryStore(clock.Now))
+	defer limiter.Close()
+	ctx := context.Background()
+
+	perMinute := Rule{Limit: 2, Window: time.Minute}
+
+	// Execute - 2 por minuto
+	for i := 0; i < 2; i++ {
+		if status, _ := limiter.AllowRule(ctx, "test:token:partner", perMinute); !status.Allowed {
+			t.Errorf("Requisição %d deveria ser permitida", i+1)
+		}
+	}
+
+	clock.Advance(30 * time.Second)
+	if status, _ := limiter.AllowRule(ctx, "test:token:partner", perMinute); status.Allowed {
+		t.Error("Requisição na mesma janela de 1 minuto deveria ser negada")
+	}
+
+	// Janelas diferentes não compartilham contador
+	if status, _ := limiter.AllowRule(ctx, "test:token:partner", Rule{Limit: 2, Window: time.Hour}); !status.Allowed {
+		t.Error("Regra com janela de 1 hora deveria ter contador próprio")
+	}
+
+	clock.Advance(31 * time.Second)
+	if status, _ := limiter.AllowRule(ctx, "test:token:partner", perMinute); !status.Allowed {
+		t.Error("Requisição após a janela deveria ser permitida")
+	}
+}
+
+func TestWindowTag(t *testing.T) {
+	tests := map[time.Duration]string{
+		time.Second:             "1s",
+		30 * time.Second:        "30s",
+		time.Minute:             "1m",
+		90 * time.Second:        "90s",
+		2 * time.Hour:           "2h",
+		24 * time.Hour:          "1d",
+		1500 * time.Millisecond: "1500ms",
+	}
+
+	for window, expected := range tests {
+		if tag := windowTag(window); tag != expected {
+			t.Errorf("windowTag(%v) esperado %s, obtido %s", window, expected, tag)
+		}
+	}
+}
+
+func TestCoreLimiter_AllowRules_MultipleTiers(t *testing.T) {
+	// Setup
+	clock := newFakeClock()
+	limiter := NewCoreLimiter(newMemoryStore(clock.Now))
+	defer limiter.Close()
+	ctx := context.Background()
+
+	perSecond := Rule{Limit: 2, Window: time.Second}
+	perMinute := Rule{Limit: 3, Window: time.Minute}
+	rules := []Rule{perSecond, perMinute}
+
+	// Execute - 2 por segundo permitidas
+	for i := 0; i < 2; i++ {
+		status, err := limiter.AllowRules(ctx, "test:token:tiers", rules)
+		if err != nil {
+			t.Fatalf("Erro inesperado: %v", err)
+		}
+		if !status.Allowed {
+			t.Errorf("Requisição %d deveria ser permitida", i+1)
+		}
+	}
+
+	// 3ª no mesmo segundo: excede o limite por segundo
+	status, _ := limiter.AllowRules(ctx, "test:token:tiers", rules)
+	if status.Allowed {
+		t.Error("Requisição deveria exceder o limite por segundo")
+	}
+	if status.Rule.Window != time.Second {
+		t.Errorf("Limite excedido esperado 2/1s, obtido %s", status.Rule)
+	}
+
+	// Negada não consome o limite por minuto: resta 1
+	clock.Advance(time.Second)
+	if status, _ = limiter.AllowRules(ctx, "test:token:tiers", rules); !status.Allowed {
+		t.Error("Requisição deveria ser permitida no segundo seguinte")
+	}
+
+	status, _ = limiter.AllowRules(ctx, "test:token:tiers", rules)
+	if status.Allowed {
+		t.Error("Requisição deveria exceder o limite por minuto")
+	}
+	if status.Rule.String() != "3/1m" {
+		t.Errorf("Limite excedido esperado 3/1m, obtido %s", status.Rule)
+	}
+	if status.Limit != 3 {
+		t.Errorf("Limit esperado 3, obtido %d", status.Limit)
+	}
+}
+
+func TestCoreLimiter_AllowRules_BlockReportsTier(t *testing.T) {
+	// Setup
+	clock := newFakeClock()
+	limiter := NewCoreLimiter(newMemoryStore(clock.Now))
+	defer limiter.Close()
+	ctx := context.Background()
+
+	rules := []Rule{
+		{Limit: 10, Window: time.Second, BlockDuration: time.Minute},
+		{Limit: 1, Window: time.Hour, BlockDuration: time.Minute},
+	}
+
+	limiter.AllowRules(ctx, "test:ip:blocked", rules)
+	limiter.AllowRules(ctx, "test:ip:blocked", rules)
+
+	// Bloqueio existente continua reportando o limite que o criou
+	status, _ := limiter.AllowRules(ctx, "test:ip:blocked", rules)
+	if status.Allowed {
+		t.Fatal("Chave deveria estar bloqueada")
+	}
+	if status.Rule.String() != "1/1h" {
+		t.Errorf("Limite esperado 1/1h, obtido %s", status.Rule)
+	}
+}
+
+func TestCoreLimiter_AllowRules_MixedAlgorithms(t *testing.T) {
+	// Setup - algoritmos diferentes usam avaliação sequencial
+	clock := newFakeClock()
+	limiter := NewCoreLimiter(newMemoryStore(clock.Now))
+	defer limiter.Close()
+	ctx := context.Background()
+
+	rules := []Rule{
+		{Algorithm: AlgorithmGCRA, Limit: 5, Window: time.Second},
+		{Algorithm: AlgorithmSlidingLog, Limit: 2, Window: time.Minute},
+	}
+
+	for i := 0; i < 2; i++ {
+		if status, _ := limiter.AllowRules(ctx, "test:ip:mixed", rules); !status.Allowed {
+			t.Errorf("Requisição %d deveria ser permitida", i+1)
+		}
+	}
+
+	status, _ := limiter.AllowRules(ctx, "test:ip:mixed", rules)
+	if status.Allowed {
+		t.Error("Requisição deveria exceder o limite por minuto")
+	}
+	if status.Rule.Algorithm != AlgorithmSlidingLog {
+		t.Errorf("Regra excedida esperada sliding_window_log, obtido %s", status.Rule.Algorithm)
+	}
+}
+
+func TestCoreLimiter_AllowN_FixedWindow(t *testing.T) {
+	// Setup - MockStore usa o caminho não atômico (IncrementBy)
+	limiter := NewCoreLimiter(NewMockStore())
+	ctx := context.Background()
+
+	// Execute - duas exportações de custo 50 consomem o limite inteiro
+	for i := 1; i <= 2; i++ {
+		status, err := limiter.AllowN(ctx, "test:token:export", 50, 100, 0)
+		if err != nil {
+			t.Fatalf("Erro inesperado: %v", err)
+		}
+		if !status.Allowed {
+			t.Errorf("Exportação %d deveria ser permitida", i)
+		}
+		if status.CurrentCount != int64(50*i) {
+			t.Errorf("CurrentCount esperado %d, obtido %d", 50*i, status.CurrentCount)
+		}
+	}
+
+	// Assert - nem uma requisição simples cabe mais na janela
+	status, _ := limiter.AllowN(ctx, "test:token:export", 1, 100, 0)
+	if status.Allowed {
+		t.Error("Requisição deveria ser negada após o consumo do limite")
+	}
+}
+
+func TestCoreLimiter_AllowRuleN_Algorithms(t *testing.T) {
+	algorithms := []Algorithm{
+		AlgorithmFixedWindow,
+		AlgorithmTokenBucket,
+		AlgorithmSlidingWindow,
+		AlgorithmSlidingLog,
+		AlgorithmGCRA,
+	}
+
+	for _, algorithm := range algorithms {
+		t.Run(string(algorithm), func(t *testing.T) {
+			// Setup
+			clock := newFakeClock()
+			limiter := NewCoreLimiter(newMemoryStore(clock.Now))
+			defer limiter.Close()
+			ctx := context.Background()
+			rule := Rule{Algorithm: algorithm, Limit: 10, Window: time.Minute}
+
+			// Execute - custo 6 cabe no limite de 10
+			status, err := limiter.AllowRuleN(ctx, "test:ip:cost", rule, 6)
+			if err != nil {
+				t.Fatalf("Erro inesperado: %v", err)
+			}
+			if !status.Allowed {
+				t.Error("Primeira requisição de custo 6 deveria ser permitida")
+			}
+
+			// Assert - restam 4 unidades, a segunda requisição de custo 6 não cabe
+			if status, _ = limiter.AllowRuleN(ctx, "test:ip:cost", rule, 6); status.Allowed {
+				t.Error("Segunda requisição de custo 6 deveria ser negada")
+			}
+		})
+	}
+}
+
+func TestCoreLimiter_AllowRulesN_MultipleTiers(t *testing.T) {
+	// Setup
+	clock := newFakeClock()
+	limiter := NewCoreLimiter(newMemoryStore(clock.Now))
+	defer limiter.Close()
+	ctx := context.Background()
+	rules := []Rule{{Limit: 100, Window: time.Second}, {Limit: 120, Window: time.Minute}}
+
+	// Execute
+	status, _ := limiter.AllowRulesN(ctx, "test:token:cost", rules, 50)
+	if !status.Allowed {
+		t.Fatal("Requisição de custo 50 deveria ser permitida")
+	}
+
+	// Assert - 50 + 50 excede o limite por segundo
+	status, _ = limiter.AllowRulesN(ctx, "test:token:cost", rules, 60)
+	if status.Allowed || status.Rule.String() != "100/1s" {
+		t.Errorf("Esperado bloqueio pelo limite 100/1s, obtido allowed=%v regra=%s", status.Allowed, status.Rule)
+	}
+
+	// No segundo seguinte o limite por segundo reinicia, mas 50 + 80 excede o limite por minuto
+	clock.Advance(time.Second)
+	status, _ = limiter.AllowRulesN(ctx, "test:token:cost", rules, 80)
+	if status.Allowed || status.Rule.String() != "120/1m" {
+		t.Errorf("Esperado bloqueio pelo limite 120/1m, obtido allowed=%v regra=%s", status.Allowed, status.Rule)
+	}
+}
+
+func TestCoreLimiter_AllowRules_TokenBucketTiers(t *testing.T) {
+	// Setup
+	clock := newFakeClock()
+	limiter := NewCoreLimiter(newMemoryStore(clock.Now))
+	defer limiter.Close()
+	ctx := context.Background()
+	rules := []Rule{
+		{Algorithm: AlgorithmTokenBucket, Limit: 10, Window: time.Second},
+		{Algorithm: AlgorithmTokenBucket, Limit: 2, Window: time.Minute},
+	}
+	for i := 0; i < 2; i++ {
+		if status, _ := limiter.AllowRules(ctx, "test:ip:tiers", rules); !status.Allowed {
+			t.Fatalf("Requisição %d deveria ser permitida", i+1)
+		}
+	}
+
+	// Execute - o segundo limite está esgotado
+	var status *BlockStatus
+	for i := 0; i < 3; i++ {
+		status, _ = limiter.AllowRules(ctx, "test:ip:tiers", rules)
+	}
+
+	// Assert - negadas pelo segundo limite sem consumir o primeiro
+	if status.Allowed || status.Rule.String() != "2/1m" {
+		t.Errorf("Esperado negação pelo limite 2/1m, obtido allowed=%v regra=%s", status.Allowed, status.Rule)
+	}
+	first, _ := limiter.Status(ctx, "test:ip:tiers", rules[0])
+	if first.Remaining != 8 {
+		t.Errorf("Requisições negadas não deveriam consumir o primeiro limite: restam %d de 10, esperado 8", first.Remaining)
+	}
+}
+
+func TestCoreLimiter_AllowRulesN_CostExceedsLimit(t *testing.T) {
+	// Setup
+	clock := newFakeClock()
+	store := newMemoryStore(clock.Now)
+	limiter := NewCoreLimiter(store)
+	defer limiter.Close()
+	ctx := context.Background()
+	rules := []Rule{{Limit: 5, Window: time.Second, BlockDuration: 300 * time.Second}, {Limit: 100, Window: time.Minute}}
+
+	for _, tt := range []struct {
+		name  string
+		rules []Rule
+	}{{"uma regra", rules[:1]}, {"multiplas janelas", rules}} {
+		// Execute
+		status, err := limiter.AllowRulesN(ctx, "test:ip:cost", tt.rules, 50)
+
+		// Assert - negada sem consumir nem bloquear
+		if !errors.Is(err, ErrCostExceedsLimit) {
+			t.Errorf("%s: esperado ErrCostExceedsLimit, obtido %v", tt.name, err)
+		}
+		if status == nil || status.Allowed || status.Rule.String() != "5/1s" {
+			t.Errorf("%s: esperado negação pelo limite 5/1s, obtido %+v", tt.name, status)
+		}
+	}
+	if ttl, _ := store.TTL(ctx, blockKeyFor("test:ip:cost")); ttl > 0 {
+		t.Errorf("Custo acima do limite não deveria bloquear a chave, TTL %v", ttl)
+	}
+	if status, _ := limiter.AllowRules(ctx, "test:ip:cost", rules); !status.Allowed || status.CurrentCount != 1 {
+		t.Errorf("Custo acima do limite não deveria consumir capacidade: %+v", status)
+	}
+}
+
+func TestCoreLimiter_ResetAt(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []Rule
+	}{
+		{"fixed_window", []Rule{{Algorithm: AlgorithmFixedWindow, Limit: 10, Window: time.Minute}}},
+		{"token_bucket", []Rule{{Algorithm: AlgorithmTokenBucket, Limit: 10, Window: time.Minute}}},
+		{"sliding_window_counter", []Rule{{Algorithm: AlgorithmSlidingWindow, Limit: 10, Window: time.Minute}}},
+		{"sliding_window_log", []Rule{{Algorithm: AlgorithmSlidingLog, Limit: 10, Window: time.Minute}}},
+		{"gcra", []Rule{{Algorithm: AlgorithmGCRA, Limit: 10, Window: time.Minute}}},
+		{"multiplas janelas", []Rule{{Limit: 10, Window: time.Minute}, {Limit: 100, Window: time.Hour}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			limiter := NewCoreLimiter(newMemoryStore(newFakeClock().Now))
+			defer limiter.Close()
+
+			// Execute
+			status, err := limiter.AllowRules(context.Background(), "test:ip:reset", tt.rules)
+
+			// Assert - o reinício vem do próprio resultado, dentro de duas janelas da regra
+			if err != nil {
+				t.Fatalf("Erro inesperado: %v", err)
+			}
+			until := time.Until(status.ResetAt)
+			if until <= 0 || until > 2*status.Rule.Window {
+				t.Errorf("ResetAt deveria estar nas próximas duas janelas de %s, obtido em %v", status.Rule, until)
+			}
+		})
+	}
+}
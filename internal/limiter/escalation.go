@@ -0,0 +1,101 @@
+package limiter
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DefaultEscalationLookback é o período padrão sem infrações após o qual a contagem é zerada
+const DefaultEscalationLookback = 24 * time.Hour
+
+// Escalation define bloqueios progressivos para chaves reincidentes.
+// Cada vez que a chave excede o limite, a contagem de infrações é incrementada e o
+// bloqueio usa o degrau correspondente no lugar de Rule.BlockDuration.
+type Escalation struct {
+	// Steps são as durações do bloqueio na 1ª, 2ª, ... infração; as seguintes
+	// repetem a última, que funciona como teto (ex.: 1m, 5m, 1h, 24h)
+	Steps []time.Duration
+	// Lookback é o período sem novas infrações após o qual a contagem é zerada
+	// (padrão: DefaultEscalationLookback)
+	Lookback time.Duration
+}
+
+// Enabled informa se há degraus configurados
+func (e Escalation) Enabled() bool {
+	return len(e.Steps) > 0
+}
+
+// Duration retorna o bloqueio da n-ésima infração (n a partir de 1)
+func (e Escalation) Duration(offences int64) time.Duration {
+	if !e.Enabled() || offences < 1 {
+		return 0
+	}
+	return e.Steps[min(offences, int64(len(e.Steps)))-1]
+}
+
+func (e Escalation) lookback() time.Duration {
+	if e.Lookback <= 0 {
+		return DefaultEscalationLookback
+	}
+	return e.Lookback
+}
+
+// offenceKeyFor monta a chave da contagem de infrações, compartilhada por todas as regras da chave
+func offenceKeyFor(key string) string {
+	return "rl:off:" + key
+}
+
+// escalate registra a infração da requisição que excedeu uma regra com escalonamento
+// e substitui o bloqueio criado pelo degrau correspondente. Falhas do store mantêm
+// o bloqueio da regra, já aplicado pela verificação.
+func (c *CoreLimiter) escalate(ctx context.Context, key string, status *BlockStatus) {
+	escalation := status.Rule.Escalation
+	if !status.Tripped || !escalation.Enabled() {
+		return
+	}
+
+	res, err := c.addOffence(ctx, OffenceRequest{
+		OffenceKey:  offenceKeyFor(key),
+		BlockKey:    blockKeyFor(key),
+		Lookback:    escalation.lookback(),
+		Steps:       escalation.Steps,
+		BlockReason: status.Rule.String(),
+		// Regras com bloqueio já o gravaram na verificação
+		KeepUnblocked: status.Rule.BlockDuration > 0,
+	})
+	if err != nil || res.BlockDuration <= 0 {
+		return
+	}
+	status.BlockDuration = res.BlockDuration
+	status.RetryAfter = res.BlockDuration
+}
+
+// addOffence incrementa a contagem de infrações, que expira após o período sem novas
+// infrações, e grava o bloqueio escalonado. Stores sem OffenceStore fazem isso em
+// operações separadas, contam o período a partir da primeira infração e não
+// distinguem um bloqueio removido desde a verificação.
+func (c *CoreLimiter) addOffence(ctx context.Context, req OffenceRequest) (OffenceResult, error) {
+	if offenceStore, ok := c.store.(OffenceStore); ok {
+		return offenceStore.Escalate(ctx, req)
+	}
+
+	offences, err := c.store.IncrementBy(ctx, req.OffenceKey, 1, req.Lookback)
+	if err != nil {
+		return OffenceResult{}, err
+	}
+	duration := Escalation{Steps: req.Steps}.Duration(offences)
+	if err := c.store.SetExpiring(ctx, req.BlockKey, req.BlockReason, duration); err != nil {
+		return OffenceResult{}, err
+	}
+	return OffenceResult{Offences: offences, BlockDuration: duration}, nil
+}
+
+// Offences retorna o número de infrações recentes da chave, usado pelo escalonamento
+func (c *CoreLimiter) Offences(ctx context.Context, key string) (int64, error) {
+	offences, err := c.store.GetCount(ctx, offenceKeyFor(key))
+	if err != nil {
+		return 0, fmt.Errorf("erro ao consultar infrações: %w", err)
+	}
+	return offences, nil
+}
@@ -0,0 +1,107 @@
+package limiter
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCoreLimiter_Escalation(t *testing.T) {
+	escalation := Escalation{Steps: []time.Duration{time.Minute, 5 * time.Minute, time.Hour}, Lookback: 2 * time.Hour}
+	tests := []struct {
+		name  string
+		rules []Rule
+	}{
+		{"fixed_window", []Rule{{Algorithm: AlgorithmFixedWindow, Limit: 1, BlockDuration: 10 * time.Second, Escalation: escalation}}},
+		{"token_bucket", []Rule{{Algorithm: AlgorithmTokenBucket, Limit: 1, BlockDuration: 10 * time.Second, Escalation: escalation}}},
+		{"sliding_window_counter", []Rule{{Algorithm: AlgorithmSlidingWindow, Limit: 1, BlockDuration: 10 * time.Second, Escalation: escalation}}},
+		{"sliding_window_log", []Rule{{Algorithm: AlgorithmSlidingLog, Limit: 1, BlockDuration: 10 * time.Second, Escalation: escalation}}},
+		{"gcra", []Rule{{Algorithm: AlgorithmGCRA, Limit: 1, BlockDuration: 10 * time.Second, Escalation: escalation}}},
+		{"multiplas janelas", []Rule{
+			{Limit: 1, BlockDuration: 10 * time.Second, Escalation: escalation},
+			{Limit: 100, Window: time.Minute, BlockDuration: 10 * time.Second, Escalation: escalation},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			clock := newFakeClock()
+			store := newMemoryStore(clock.Now)
+			limiter := NewCoreLimiter(store)
+			defer limiter.Close()
+			ctx := context.Background()
+			key := "test:ip:escalation"
+
+			// offend esgota o limite após o fim do bloqueio anterior e retorna o bloqueio aplicado
+			offend := func() *BlockStatus {
+				limiter.AllowRules(ctx, key, tt.rules)
+				status, _ := limiter.AllowRules(ctx, key, tt.rules)
+				if status.Allowed || !status.Tripped {
+					t.Fatalf("Segunda requisição deveria exceder o limite: %+v", status)
+				}
+				return status
+			}
+
+			// Execute & Assert - degraus crescentes até o teto
+			for i, want := range []time.Duration{time.Minute, 5 * time.Minute, time.Hour, time.Hour} {
+				status := offend()
+				ttl, _ := store.TTL(ctx, blockKeyFor(key))
+				if status.BlockDuration != want || ttl != want {
+					t.Errorf("Infração %d: bloqueio esperado %v, obtido %v (TTL %v)", i+1, want, status.BlockDuration, ttl)
+				}
+
+				// Requisições negadas pelo bloqueio existente não contam como infração
+				blocked, _ := limiter.AllowRules(ctx, key, tt.rules)
+				if blocked.Allowed || blocked.Tripped {
+					t.Errorf("Infração %d: requisição bloqueada não deveria exceder o limite: %+v", i+1, blocked)
+				}
+				if offences, _ := limiter.Offences(ctx, key); offences != int64(i+1) {
+					t.Errorf("Infração %d: contagem esperada %d, obtida %d", i+1, i+1, offences)
+				}
+				clock.Advance(want + time.Minute)
+			}
+
+			// A contagem é zerada após o período sem infrações
+			clock.Advance(2 * time.Hour)
+			if status := offend(); status.BlockDuration != time.Minute {
+				t.Errorf("Após o período sem infrações o bloqueio deveria voltar a 1m, obtido %v", status.BlockDuration)
+			}
+
+			// Reset também zera as infrações
+			if err := limiter.Reset(ctx, key, tt.rules); err != nil {
+				t.Fatalf("Erro inesperado: %v", err)
+			}
+			if offences, _ := limiter.Offences(ctx, key); offences != 0 {
+				t.Errorf("Reset deveria zerar as infrações, obtido %d", offences)
+			}
+		})
+	}
+}
+
+func TestCoreLimiter_WithoutEscalation(t *testing.T) {
+	// Setup
+	clock := newFakeClock()
+	store := newMemoryStore(clock.Now)
+	limiter := NewCoreLimiter(store)
+	defer limiter.Close()
+	ctx := context.Background()
+	rule := Rule{Limit: 1, BlockDuration: 10 * time.Second}
+
+	// Execute - duas infrações seguidas
+	for i := 0; i < 2; i++ {
+		limiter.AllowRule(ctx, "test:ip:fixed", rule)
+		limiter.AllowRule(ctx, "test:ip:fixed", rule)
+		clock.Advance(time.Second)
+
+		// Assert
+		ttl, _ := store.TTL(ctx, blockKeyFor("test:ip:fixed"))
+		if ttl != 9*time.Second {
+			t.Errorf("Infração %d: bloqueio deveria ser sempre o da regra, TTL obtido %v", i+1, ttl)
+		}
+		clock.Advance(10 * time.Second)
+	}
+	if offences, _ := limiter.Offences(ctx, "test:ip:fixed"); offences != 0 {
+		t.Errorf("Sem escalonamento as infrações não deveriam ser contadas, obtido %d", offences)
+	}
+}
@@ -0,0 +1,145 @@
+package limiter
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"sync/atomic"
+)
+
+// FailurePolicy define o resultado das verificações quando o store falha
+type FailurePolicy string
+
+const (
+	// FailOpen permite a requisição (padrão)
+	FailOpen FailurePolicy = "open"
+	// FailClosed nega a requisição
+	FailClosed FailurePolicy = "closed"
+	// FailLocal avalia a requisição em um limiter em memória da própria instância,
+	// com limites reduzidos; o estado não é compartilhado entre réplicas
+	FailLocal FailurePolicy = "local"
+)
+
+// storeFailureMetrics descreve a política de falha (expvar "store_failure")
+// Chaves: policy (configurada), active ("store" ou a política em uso enquanto o
+// store falha) e open, closed e local (requisições decididas por cada política)
+var (
+	storeFailureMetrics = expvar.NewMap("store_failure")
+	failurePolicyVar    = new(expvar.String)
+	activeModeVar       = new(expvar.String)
+)
+
+func init() {
+	failurePolicyVar.Set(string(FailOpen))
+	activeModeVar.Set("store")
+	storeFailureMetrics.Set("policy", failurePolicyVar)
+	storeFailureMetrics.Set("active", activeModeVar)
+}
+
+// SetFailurePolicy define o comportamento quando o store falha. Com FailLocal, as
+// requisições passam a ser avaliadas em memória com os limites e rajadas reduzidos a
+// localPercent% (1 a 100; ex.: 100/réplicas). Deve ser chamado antes de o limiter
+// começar a receber requisições.
+func (c *CoreLimiter) SetFailurePolicy(policy FailurePolicy, localPercent int) error {
+	if err := validateFailurePolicy(policy, localPercent); err != nil {
+		return err
+	}
+	if policy == FailLocal {
+		if c.fallback == nil {
+			c.fallback = NewCoreLimiter(NewMemoryStore())
+			c.fallback.local = true
+		}
+		c.localPercent = localPercent
+	}
+
+	c.failurePolicy = policy
+	failurePolicyVar.Set(string(policy))
+	return nil
+}
+
+// validateFailurePolicy verifica a política e, com FailLocal, o percentual local
+func validateFailurePolicy(policy FailurePolicy, localPercent int) error {
+	switch policy {
+	case FailOpen, FailClosed:
+		return nil
+	case FailLocal:
+		if localPercent < 1 || localPercent > 100 {
+			return fmt.Errorf("percentual local inválido (esperado 1 a 100): %d", localPercent)
+		}
+		return nil
+	default:
+		return fmt.Errorf("política de falha desconhecida: %s", policy)
+	}
+}
+
+// onFailure aplica a política de falha a uma verificação que falhou no store
+// O erro é sempre retornado; BlockStatus.Failure informa a política aplicada
+func (c *CoreLimiter) onFailure(ctx context.Context, key string, rules []Rule, n int, err error) (*BlockStatus, error) {
+	policy := c.failurePolicy
+	var status *BlockStatus
+	switch policy {
+	case FailClosed:
+		status = failOpen(rules[0])
+		status.Allowed = false
+	case FailLocal:
+		local, localErr := c.fallback.AllowRulesN(ctx, key, localRules(rules, c.localPercent), n)
+		if localErr == nil {
+			status = local
+			break
+		}
+		// Algoritmos personalizados não existem no limiter local: permite a requisição
+		policy = FailOpen
+		status = failOpen(rules[0])
+	default:
+		status = failOpen(rules[0])
+	}
+	status.Failure = policy
+
+	if !c.local {
+		recordFailure(&c.degraded, c.failurePolicy, policy)
+	}
+	return status, err
+}
+
+// recovered registra que o store voltou a responder após uma falha
+func (c *CoreLimiter) recovered() {
+	if !c.local {
+		recordRecovery(&c.degraded)
+	}
+}
+
+// recordFailure contabiliza a requisição decidida pela política applied e, na
+// primeira falha, publica a política configurada como modo ativo
+func recordFailure(degraded *atomic.Bool, configured, applied FailurePolicy) {
+	storeFailureMetrics.Add(string(applied), 1)
+	if !degraded.Swap(true) {
+		activeModeVar.Set(string(configured))
+	}
+}
+
+// recordRecovery volta o modo ativo ao store após uma falha
+func recordRecovery(degraded *atomic.Bool) {
+	if degraded.Load() && degraded.Swap(false) {
+		activeModeVar.Set("store")
+	}
+}
+
+// localRules reduz os limites e rajadas das regras a percent%, com mínimo de 1
+func localRules(rules []Rule, percent int) []Rule {
+	scaled := make([]Rule, len(rules))
+	for i, rule := range rules {
+		rule.Limit = scalePercent(rule.Limit, percent)
+		if rule.Burst > 0 {
+			rule.Burst = scalePercent(rule.Burst, percent)
+		}
+		scaled[i] = rule
+	}
+	return scaled
+}
+
+func scalePercent(value, percent int) int {
+	if value <= 0 {
+		return value
+	}
+	return max(value*percent/100, 1)
+}
@@ -0,0 +1,144 @@
+package limiter
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"testing"
+	"time"
+)
+
+func TestCoreLimiter_FailurePolicy(t *testing.T) {
+	rules := []Rule{
+		{Limit: 10, BlockDuration: time.Second},
+		{Limit: 100, Window: time.Minute, BlockDuration: time.Second},
+	}
+	tests := []struct {
+		name        string
+		policy      FailurePolicy
+		wantAllowed int
+	}{
+		{"open", FailOpen, 20},
+		{"closed", FailClosed, 0},
+		// 10/s reduzido a 30% permite 3 requisições por instância
+		{"local", FailLocal, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			atomicStore := NewAtomicMockStore()
+			atomicStore.SetShouldFail(true)
+			limiter := NewCoreLimiter(atomicStore)
+			defer limiter.Close()
+			if err := limiter.SetFailurePolicy(tt.policy, 30); err != nil {
+				t.Fatalf("Erro inesperado: %v", err)
+			}
+			ctx := context.Background()
+			before := failureCount(tt.policy)
+
+			// Execute
+			allowed := 0
+			for i := 0; i < 20; i++ {
+				status, err := limiter.AllowRules(ctx, "test:ip:failure", rules)
+				if err == nil {
+					t.Fatal("Esperado erro do mock store")
+				}
+				if status.Failure != tt.policy {
+					t.Errorf("Política aplicada esperada %q, obtida %q", tt.policy, status.Failure)
+				}
+				if status.Allowed {
+					allowed++
+				}
+			}
+
+			// Assert
+			if allowed != tt.wantAllowed {
+				t.Errorf("Esperado %d requisições permitidas, obtido %d", tt.wantAllowed, allowed)
+			}
+			if got := activeModeVar.Value(); got != string(tt.policy) {
+				t.Errorf("Modo ativo esperado %q, obtido %q", tt.policy, got)
+			}
+			if got := failureCount(tt.policy) - before; got != 20 {
+				t.Errorf("Contador da política %q deveria aumentar 20, aumentou %d", tt.policy, got)
+			}
+
+			// O modo ativo volta ao store quando ele se recupera
+			atomicStore.SetShouldFail(false)
+			status, err := limiter.AllowRules(ctx, "test:ip:failure", rules)
+			if err != nil || status.Failure != "" {
+				t.Errorf("Com o store disponível não deveria haver falha: %+v, %v", status, err)
+			}
+			if got := activeModeVar.Value(); got != "store" {
+				t.Errorf("Modo ativo esperado \"store\", obtido %q", got)
+			}
+		})
+	}
+}
+
+func TestCoreLimiter_FailurePolicy_LocalCustomAlgorithm(t *testing.T) {
+	// Setup - algoritmos personalizados não existem no limiter local
+	mockStore := NewMockStore()
+	limiter := NewCoreLimiter(mockStore)
+	limiter.RegisterAlgorithm("custom", failingAlgorithm{})
+	limiter.SetFailurePolicy(FailLocal, 50)
+
+	// Execute
+	status, err := limiter.AllowRule(context.Background(), "test:ip:custom", Rule{Algorithm: "custom", Limit: 1})
+
+	// Assert
+	if err == nil {
+		t.Error("Esperado erro do algoritmo")
+	}
+	if !status.Allowed || status.Failure != FailOpen {
+		t.Errorf("Sem o algoritmo no limiter local a requisição deveria ser permitida: %+v", status)
+	}
+}
+
+func TestCoreLimiter_SetFailurePolicy_Invalid(t *testing.T) {
+	limiter := NewCoreLimiter(NewMockStore())
+
+	if err := limiter.SetFailurePolicy("fechado", 50); err == nil {
+		t.Error("Esperado erro para política desconhecida")
+	}
+	if err := limiter.SetFailurePolicy(FailLocal, 0); err == nil {
+		t.Error("Esperado erro para percentual local fora de 1 a 100")
+	}
+}
+
+func TestLocalRules(t *testing.T) {
+	// Setup
+	rules := []Rule{
+		{Algorithm: AlgorithmTokenBucket, Limit: 10, Burst: 40},
+		{Limit: 1, Window: time.Hour},
+	}
+
+	// Execute
+	scaled := localRules(rules, 25)
+
+	// Assert - reduz limites e rajadas sem alterar as regras originais
+	if scaled[0].Limit != 2 || scaled[0].Burst != 10 {
+		t.Errorf("Esperado 2 req/s com rajada 10, obtido %d e %d", scaled[0].Limit, scaled[0].Burst)
+	}
+	if scaled[1].Limit != 1 {
+		t.Errorf("Limite reduzido deveria ser no mínimo 1, obtido %d", scaled[1].Limit)
+	}
+	if rules[0].Limit != 10 {
+		t.Error("As regras originais não deveriam ser alteradas")
+	}
+}
+
+// failingAlgorithm é um algoritmo personalizado que sempre falha
+type failingAlgorithm struct{}
+
+func (failingAlgorithm) Allow(context.Context, string, Rule, int64) (*BlockStatus, error) {
+	return nil, errors.New("store indisponível")
+}
+
+// failureCount lê o contador da política na métrica store_failure
+func failureCount(policy FailurePolicy) int64 {
+	if v, ok := storeFailureMetrics.Get(string(policy)).(*expvar.Int); ok {
+		return v.Value()
+	}
+	return 0
+}
@@ -310,3 +310,599 @@ func TestRedisStore_Integration_ConnectionFailure(t *testing.T) {
 		t.Error("Esperado erro ao conectar em endereço inválido")
 	}
 }
+
+func TestRedisStore_Integration_WindowDoesNotSlide(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Pulando teste de integração em modo short")
+	}
+
+	// Setup
+	_, endpoint := setupRedisContainer(t)
+
+	store, err := NewRedisStore(endpoint)
+	if err != nil {
+		t.Fatalf("Erro ao conectar ao Redis: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	key := "test:integration:window"
+	expiry := 2 * time.Second
+
+	// Incrementos contínuos não devem renovar a expiração da janela
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, err := store.Increment(ctx, key, expiry); err != nil {
+			t.Fatalf("Erro ao incrementar: %v", err)
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+
+	// A janela deveria ter reiniciado ao menos uma vez
+	count, err := store.GetCount(ctx, key)
+	if err != nil {
+		t.Fatalf("Erro ao obter count: %v", err)
+	}
+	if count >= 15 {
+		t.Errorf("Janela não expirou: count %d", count)
+	}
+}
+
+func TestRedisStore_Integration_AllowAtomic(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Pulando teste de integração em modo short")
+	}
+
+	// Setup
+	_, endpoint := setupRedisContainer(t)
+
+	store, err := NewRedisStore(endpoint)
+	if err != nil {
+		t.Fatalf("Erro ao conectar ao Redis: %v", err)
+	}
+	defer store.Close()
+
+	// Força o fallback NOSCRIPT na primeira execução
+	if err := store.client.ScriptFlush(context.Background()).Err(); err != nil {
+		t.Fatalf("Erro ao limpar scripts: %v", err)
+	}
+
+	ctx := context.Background()
+	req := AtomicRequest{
+		CounterKey:    "rl:cnt:test:atomic",
+		BlockKey:      "rl:blk:test:atomic",
+		Limit:         5,
+		Window:        10 * time.Second,
+		BlockDuration: 10 * time.Second,
+	}
+	numGoroutines := 20
+
+	results := make(chan AtomicResult, numGoroutines)
+	for i := 0; i < numGoroutines; i++ {
+		go func() {
+			res, err := store.AllowAtomic(ctx, req)
+			if err != nil {
+				t.Errorf("Erro em verificação concorrente: %v", err)
+			}
+			results <- res
+		}()
+	}
+
+	allowed := 0
+	for i := 0; i < numGoroutines; i++ {
+		if res := <-results; !res.Blocked {
+			allowed++
+		}
+	}
+
+	// Exatamente o limite deve passar, mesmo sob concorrência
+	if allowed != int(req.Limit) {
+		t.Errorf("Esperado %d requisições permitidas, obtido %d", req.Limit, allowed)
+	}
+
+	blocked, err := store.Exists(ctx, req.BlockKey)
+	if err != nil {
+		t.Fatalf("Erro ao verificar bloqueio: %v", err)
+	}
+	if !blocked {
+		t.Error("Chave de bloqueio deveria existir")
+	}
+}
+
+func TestCoreLimiter_Integration_TokenBucket(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Pulando teste de integração em modo short")
+	}
+
+	// Setup
+	_, endpoint := setupRedisContainer(t)
+
+	store, err := NewRedisStore(endpoint)
+	if err != nil {
+		t.Fatalf("Erro ao conectar ao Redis: %v", err)
+	}
+	defer store.Close()
+
+	limiter := NewCoreLimiter(store)
+	ctx := context.Background()
+	key := "test:integration:bucket"
+	rule := Rule{Algorithm: AlgorithmTokenBucket, Limit: 2, Burst: 5}
+
+	// Fase 1: rajada até a capacidade
+	for i := 1; i <= 5; i++ {
+		status, err := limiter.AllowRule(ctx, key, rule)
+		if err != nil {
+			t.Fatalf("Erro na requisição %d: %v", i, err)
+		}
+		if !status.Allowed {
+			t.Errorf("Requisição %d deveria ser permitida", i)
+		}
+	}
+
+	// Fase 2: bucket vazio
+	status, err := limiter.AllowRule(ctx, key, rule)
+	if err != nil {
+		t.Fatalf("Erro ao exceder capacidade: %v", err)
+	}
+	if status.Allowed {
+		t.Error("Requisição além da capacidade deveria ser bloqueada")
+	}
+
+	// Fase 3: reposição de 2 tokens/s
+	time.Sleep(600 * time.Millisecond)
+	status, err = limiter.AllowRule(ctx, key, rule)
+	if err != nil {
+		t.Fatalf("Erro após reposição: %v", err)
+	}
+	if !status.Allowed {
+		t.Error("Requisição após reposição deveria ser permitida")
+	}
+}
+
+func TestCoreLimiter_Integration_SlidingAlgorithms(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Pulando teste de integração em modo short")
+	}
+
+	// Setup
+	_, endpoint := setupRedisContainer(t)
+
+	store, err := NewRedisStore(endpoint)
+	if err != nil {
+		t.Fatalf("Erro ao conectar ao Redis: %v", err)
+	}
+	defer store.Close()
+
+	limiter := NewCoreLimiter(store)
+	ctx := context.Background()
+
+	for _, algorithm := range []Algorithm{AlgorithmSlidingWindow, AlgorithmSlidingLog} {
+		key := "test:integration:" + string(algorithm)
+		rule := Rule{Algorithm: algorithm, Limit: 5}
+
+		allowed := 0
+		for i := 0; i < 10; i++ {
+			status, err := limiter.AllowRule(ctx, key, rule)
+			if err != nil {
+				t.Fatalf("%s: erro na requisição %d: %v", algorithm, i+1, err)
+			}
+			if status.Allowed {
+				allowed++
+			}
+		}
+
+		if allowed != 5 {
+			t.Errorf("%s: esperado 5 requisições permitidas, obtido %d", algorithm, allowed)
+		}
+	}
+}
+
+func TestCoreLimiter_Integration_GCRA(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Pulando teste de integração em modo short")
+	}
+
+	// Setup
+	_, endpoint := setupRedisContainer(t)
+
+	store, err := NewRedisStore(endpoint)
+	if err != nil {
+		t.Fatalf("Erro ao conectar ao Redis: %v", err)
+	}
+	defer store.Close()
+
+	limiter := NewCoreLimiter(store)
+	ctx := context.Background()
+	key := "test:integration:gcra"
+	rule := Rule{Algorithm: AlgorithmGCRA, Limit: 5}
+
+	for i := 1; i <= 5; i++ {
+		status, err := limiter.AllowRule(ctx, key, rule)
+		if err != nil {
+			t.Fatalf("Erro na requisição %d: %v", i, err)
+		}
+		if !status.Allowed {
+			t.Errorf("Requisição %d deveria ser permitida", i)
+		}
+	}
+
+	status, err := limiter.AllowRule(ctx, key, rule)
+	if err != nil {
+		t.Fatalf("Erro ao exceder limite: %v", err)
+	}
+	if status.Allowed {
+		t.Error("Requisição além da rajada deveria ser bloqueada")
+	}
+	if status.RetryAfter <= 0 || status.RetryAfter > 200*time.Millisecond {
+		t.Errorf("RetryAfter esperado em (0, 200ms], obtido %v", status.RetryAfter)
+	}
+
+	time.Sleep(status.RetryAfter)
+	status, err = limiter.AllowRule(ctx, key, rule)
+	if err != nil {
+		t.Fatalf("Erro após RetryAfter: %v", err)
+	}
+	if !status.Allowed {
+		t.Error("Requisição após RetryAfter deveria ser permitida")
+	}
+}
+
+func TestCoreLimiter_Integration_MultipleTiers(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Pulando teste de integração em modo short")
+	}
+
+	// Setup
+	_, endpoint := setupRedisContainer(t)
+
+	store, err := NewRedisStore(endpoint)
+	if err != nil {
+		t.Fatalf("Erro ao conectar ao Redis: %v", err)
+	}
+	defer store.Close()
+
+	limiter := NewCoreLimiter(store)
+	ctx := context.Background()
+	key := "test:integration:tiers"
+	rules := []Rule{
+		{Limit: 5, Window: time.Second},
+		{Limit: 3, Window: time.Minute, BlockDuration: 5 * time.Second},
+	}
+
+	for i := 1; i <= 3; i++ {
+		status, err := limiter.AllowRules(ctx, key, rules)
+		if err != nil {
+			t.Fatalf("Erro na requisição %d: %v", i, err)
+		}
+		if !status.Allowed {
+			t.Errorf("Requisição %d deveria ser permitida", i)
+		}
+	}
+
+	// Excede o limite por minuto e cria o bloqueio
+	status, err := limiter.AllowRules(ctx, key, rules)
+	if err != nil {
+		t.Fatalf("Erro ao exceder limite: %v", err)
+	}
+	if status.Allowed || status.Rule.String() != "3/1m" {
+		t.Errorf("Esperado bloqueio pelo limite 3/1m, obtido allowed=%v rule=%s", status.Allowed, status.Rule)
+	}
+
+	// Bloqueio existente reporta o mesmo limite
+	status, err = limiter.AllowRules(ctx, key, rules)
+	if err != nil {
+		t.Fatalf("Erro ao verificar bloqueio: %v", err)
+	}
+	if status.Allowed || status.Rule.String() != "3/1m" {
+		t.Errorf("Esperado bloqueio pelo limite 3/1m, obtido allowed=%v rule=%s", status.Allowed, status.Rule)
+	}
+}
+
+func TestCoreLimiter_Integration_AllowN(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Pulando teste de integração em modo short")
+	}
+
+	// Setup
+	_, endpoint := setupRedisContainer(t)
+
+	store, err := NewRedisStore(endpoint)
+	if err != nil {
+		t.Fatalf("Erro ao conectar ao Redis: %v", err)
+	}
+	defer store.Close()
+
+	limiter := NewCoreLimiter(store)
+	ctx := context.Background()
+
+	algorithms := []Algorithm{AlgorithmFixedWindow, AlgorithmTokenBucket, AlgorithmSlidingWindow, AlgorithmSlidingLog, AlgorithmGCRA}
+	for _, algorithm := range algorithms {
+		key := "test:integration:cost:" + string(algorithm)
+		rule := Rule{Algorithm: algorithm, Limit: 10, Window: time.Minute}
+
+		// Custo 6 cabe no limite; a segunda requisição de custo 6 não
+		status, err := limiter.AllowRuleN(ctx, key, rule, 6)
+		if err != nil {
+			t.Fatalf("%s: erro inesperado: %v", algorithm, err)
+		}
+		if !status.Allowed {
+			t.Errorf("%s: primeira requisição de custo 6 deveria ser permitida", algorithm)
+		}
+		if status, _ = limiter.AllowRuleN(ctx, key, rule, 6); status.Allowed {
+			t.Errorf("%s: segunda requisição de custo 6 deveria ser negada", algorithm)
+		}
+	}
+
+	// Sliding log com custo maior que um bloco de ZADD: todas as unidades são registradas
+	logRule := Rule{Algorithm: AlgorithmSlidingLog, Limit: 5000, Window: time.Minute}
+	if status, err := limiter.AllowRuleN(ctx, "test:integration:cost:log", logRule, 2500); err != nil || status.CurrentCount != 2500 {
+		t.Errorf("Sliding log de custo 2500 deveria registrar 2500 unidades: %+v, %v", status, err)
+	}
+
+	// Múltiplas janelas fixas: o custo é somado a todos os contadores
+	rules := []Rule{{Limit: 100, Window: time.Second}, {Limit: 120, Window: time.Minute}}
+	if status, _ := limiter.AllowRulesN(ctx, "test:integration:cost:multi", rules, 100); !status.Allowed {
+		t.Error("Requisição de custo 100 deveria ser permitida")
+	}
+	count, err := store.GetCount(ctx, "rl:cnt:test:integration:cost:multi:1m")
+	if err != nil {
+		t.Fatalf("Erro ao ler contador: %v", err)
+	}
+	if count != 100 {
+		t.Errorf("Contador por minuto esperado 100, obtido %d", count)
+	}
+}
+
+func TestCoreLimiter_Integration_Reserve(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Pulando teste de integração em modo short")
+	}
+
+	// Setup
+	_, endpoint := setupRedisContainer(t)
+
+	store, err := NewRedisStore(endpoint)
+	if err != nil {
+		t.Fatalf("Erro ao conectar ao Redis: %v", err)
+	}
+	defer store.Close()
+
+	limiter := NewCoreLimiter(store)
+	ctx := context.Background()
+	rules := []Rule{{Limit: 2, Window: time.Second, BlockDuration: time.Minute}}
+
+	for i := 0; i < 2; i++ {
+		if res, err := limiter.Reserve(ctx, "test:integration:reserve", rules, 1); err != nil || !res.OK {
+			t.Fatalf("Reserva %d deveria ser concedida (err=%v)", i+1, err)
+		}
+	}
+
+	// Negada sem bloqueio e com espera até o fim da janela
+	res, err := limiter.Reserve(ctx, "test:integration:reserve", rules, 1)
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if res.OK || res.Delay <= 0 || res.Delay > time.Second {
+		t.Errorf("Esperada reserva negada com Delay até 1s, obtido OK=%v Delay=%v", res.OK, res.Delay)
+	}
+	if blocked, _ := store.Exists(ctx, "rl:blk:test:integration:reserve"); blocked {
+		t.Error("Reserva negada não deveria criar bloqueio")
+	}
+
+	// Wait aguarda a janela seguinte
+	waitCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	if err := limiter.Wait(waitCtx, "test:integration:reserve", rules, 1); err != nil {
+		t.Errorf("Wait deveria obter capacidade na janela seguinte: %v", err)
+	}
+}
+
+func TestCoreLimiter_Integration_Status(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Pulando teste de integração em modo short")
+	}
+
+	// Setup
+	_, endpoint := setupRedisContainer(t)
+
+	store, err := NewRedisStore(endpoint)
+	if err != nil {
+		t.Fatalf("Erro ao conectar ao Redis: %v", err)
+	}
+	defer store.Close()
+
+	limiter := NewCoreLimiter(store)
+	ctx := context.Background()
+
+	for _, algorithm := range []Algorithm{AlgorithmFixedWindow, AlgorithmTokenBucket, AlgorithmSlidingWindow, AlgorithmSlidingLog, AlgorithmGCRA} {
+		key := "test:integration:status:" + string(algorithm)
+		rule := Rule{Algorithm: algorithm, Limit: 5, Window: time.Minute}
+
+		for i := 0; i < 2; i++ {
+			limiter.AllowRule(ctx, key, rule)
+		}
+
+		// Execute - consultas repetidas não consomem capacidade
+		var status *LimitStatus
+		for i := 0; i < 3; i++ {
+			if status, err = limiter.Status(ctx, key, rule); err != nil {
+				t.Fatalf("%s: erro inesperado: %v", algorithm, err)
+			}
+		}
+
+		// Assert
+		if status.Remaining != 3 {
+			t.Errorf("%s: esperado restante 3, obtido %d", algorithm, status.Remaining)
+		}
+		if status.ResetAt.IsZero() || status.Blocked {
+			t.Errorf("%s: estado inesperado %+v", algorithm, status)
+		}
+	}
+}
+
+func TestCoreLimiter_Integration_BlockAndReset(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Pulando teste de integração em modo short")
+	}
+
+	// Setup
+	_, endpoint := setupRedisContainer(t)
+
+	store, err := NewRedisStore(endpoint)
+	if err != nil {
+		t.Fatalf("Erro ao conectar ao Redis: %v", err)
+	}
+	defer store.Close()
+
+	limiter := NewCoreLimiter(store)
+	ctx := context.Background()
+
+	for _, algorithm := range []Algorithm{AlgorithmFixedWindow, AlgorithmTokenBucket, AlgorithmSlidingWindow, AlgorithmSlidingLog, AlgorithmGCRA} {
+		key := "test:integration:reset:" + string(algorithm)
+		rules := []Rule{{Algorithm: algorithm, Limit: 2, Window: time.Minute, BlockDuration: time.Minute}}
+		for i := 0; i < 3; i++ {
+			limiter.AllowRules(ctx, key, rules)
+		}
+
+		// Execute
+		if err := limiter.Reset(ctx, key, rules); err != nil {
+			t.Fatalf("%s: erro inesperado: %v", algorithm, err)
+		}
+
+		// Assert
+		status, err := limiter.Status(ctx, key, rules[0])
+		if err != nil {
+			t.Fatalf("%s: erro inesperado: %v", algorithm, err)
+		}
+		if status.Blocked || status.Remaining != 2 {
+			t.Errorf("%s: estado esperado zerado, obtido %+v", algorithm, status)
+		}
+	}
+
+	// Bloqueio manual e desbloqueio
+	if err := limiter.Block(ctx, "test:integration:manual", time.Minute); err != nil {
+		t.Fatalf("Erro ao bloquear: %v", err)
+	}
+	if res, _ := limiter.AllowRule(ctx, "test:integration:manual", Rule{Limit: 10}); res.Allowed {
+		t.Error("Chave bloqueada manualmente não deveria ser permitida")
+	}
+	if unblocked, err := limiter.Unblock(ctx, "test:integration:manual"); err != nil || !unblocked {
+		t.Errorf("Unblock esperado true, obtido %v (%v)", unblocked, err)
+	}
+}
+
+func TestCoreLimiter_Integration_BlockedKeys(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Pulando teste de integração em modo short")
+	}
+
+	// Setup
+	_, endpoint := setupRedisContainer(t)
+
+	store, err := NewRedisStore(endpoint)
+	if err != nil {
+		t.Fatalf("Erro ao conectar ao Redis: %v", err)
+	}
+	defer store.Close()
+
+	limiter := NewCoreLimiter(store)
+	ctx := context.Background()
+
+	// Uma chave bloqueada por algoritmo, cada uma registrando o limite excedido
+	for _, algorithm := range []Algorithm{AlgorithmFixedWindow, AlgorithmTokenBucket, AlgorithmSlidingWindow, AlgorithmSlidingLog, AlgorithmGCRA} {
+		rule := Rule{Algorithm: algorithm, Limit: 1, Window: time.Minute, BlockDuration: time.Minute}
+		for i := 0; i < 3; i++ {
+			limiter.AllowRule(ctx, "ip:"+string(algorithm), rule)
+		}
+	}
+	limiter.Block(ctx, "token:*special", time.Minute)
+
+	// Execute
+	var keys []BlockedKey
+	var cursor string
+	for {
+		page, next, err := limiter.BlockedKeys(ctx, "ip:", cursor, 2)
+		if err != nil {
+			t.Fatalf("Erro inesperado: %v", err)
+		}
+		keys = append(keys, page...)
+		if cursor = next; cursor == "" {
+			break
+		}
+	}
+
+	// Assert
+	if len(keys) != 5 {
+		t.Fatalf("Esperado 5 chaves bloqueadas, obtido %d: %+v", len(keys), keys)
+	}
+	for _, k := range keys {
+		if k.Rule != "1/1m" || k.BlockedUntil.IsZero() {
+			t.Errorf("Chave bloqueada inesperada: %+v", k)
+		}
+	}
+
+	special, _, err := limiter.BlockedKeys(ctx, "token:*", "", 100)
+	if err != nil || len(special) != 1 || special[0].Rule != ManualBlock {
+		t.Errorf("Prefixo com caractere especial inesperado: %+v (%v)", special, err)
+	}
+}
+
+func TestCoreLimiter_Integration_Escalation(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Pulando teste de integração em modo short")
+	}
+
+	// Setup
+	_, endpoint := setupRedisContainer(t)
+
+	store, err := NewRedisStore(endpoint)
+	if err != nil {
+		t.Fatalf("Erro ao conectar ao Redis: %v", err)
+	}
+	defer store.Close()
+
+	limiter := NewCoreLimiter(store)
+	ctx := context.Background()
+	escalation := Escalation{Steps: []time.Duration{time.Minute, 5 * time.Minute}, Lookback: time.Hour}
+
+	for _, algorithm := range []Algorithm{AlgorithmFixedWindow, AlgorithmTokenBucket, AlgorithmSlidingWindow, AlgorithmSlidingLog, AlgorithmGCRA} {
+		t.Run(string(algorithm), func(t *testing.T) {
+			key := "ip:escalation:" + string(algorithm)
+			rule := Rule{Algorithm: algorithm, Limit: 1, Window: time.Minute, BlockDuration: 10 * time.Second, Escalation: escalation}
+
+			// Execute - duas infrações, desbloqueando a chave entre elas
+			var durations []time.Duration
+			for i := 0; i < 2; i++ {
+				limiter.AllowRule(ctx, key, rule)
+				status, err := limiter.AllowRule(ctx, key, rule)
+				if err != nil || status.Allowed || !status.Tripped {
+					t.Fatalf("Requisição deveria exceder o limite: %+v (%v)", status, err)
+				}
+				durations = append(durations, status.BlockDuration)
+
+				// Requisição negada pelo bloqueio não é uma nova infração
+				if blocked, _ := limiter.AllowRule(ctx, key, rule); blocked.Allowed || blocked.Tripped {
+					t.Errorf("Requisição bloqueada inesperada: %+v", blocked)
+				}
+				if i == 0 {
+					limiter.Unblock(ctx, key)
+				}
+			}
+
+			// Assert
+			if durations[0] != time.Minute || durations[1] != 5*time.Minute {
+				t.Errorf("Bloqueios esperados [1m 5m], obtidos %v", durations)
+			}
+			if ttl, _ := store.TTL(ctx, blockKeyFor(key)); ttl <= time.Minute || ttl > 5*time.Minute {
+				t.Errorf("TTL do bloqueio escalonado inesperado: %v", ttl)
+			}
+			if offences, _ := limiter.Offences(ctx, key); offences != 2 {
+				t.Errorf("Esperado 2 infrações, obtido %d", offences)
+			}
+			// A contagem expira após o período sem infrações, renovado a cada infração
+			if ttl, _ := store.TTL(ctx, offenceKeyFor(key)); ttl <= 59*time.Minute || ttl > time.Hour {
+				t.Errorf("TTL da contagem de infrações inesperado: %v", ttl)
+			}
+		})
+	}
+}
@@ -0,0 +1,622 @@
+package limiter
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// memoryEntry armazena o estado de uma chave no MemoryStore
+type memoryEntry struct {
+	count     int64
+	value     string
+	tokens    float64
+	prev      int64
+	window    int64 // índice da janela atual (janela deslizante)
+	log       []time.Time
+	tat       time.Time // instante teórico de chegada (GCRA)
+	updatedAt time.Time
+	expiresAt time.Time // zero: sem expiração
+}
+
+func (e *memoryEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// MemoryStore implementa LimiterStoreStrategy em memória local
+// Útil para testes e instâncias únicas; o estado não é compartilhado entre réplicas
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]*memoryEntry
+	now     func() time.Time
+	stop    chan struct{}
+	once    sync.Once
+}
+
+var (
+	_ LimiterStoreStrategy = (*MemoryStore)(nil)
+	_ AtomicStore          = (*MemoryStore)(nil)
+	_ TokenBucketStore     = (*MemoryStore)(nil)
+	_ SlidingWindowStore   = (*MemoryStore)(nil)
+	_ SlidingLogStore      = (*MemoryStore)(nil)
+	_ GCRAStore            = (*MemoryStore)(nil)
+	_ MultiAtomicStore     = (*MemoryStore)(nil)
+	_ OffenceStore         = (*MemoryStore)(nil)
+	_ PeekStore            = (*MemoryStore)(nil)
+	_ KeyScanner           = (*MemoryStore)(nil)
+)
+
+// NewMemoryStore cria uma nova instância de MemoryStore
+// Chaves expiradas são removidas periodicamente até Close ser chamado
+func NewMemoryStore() *MemoryStore {
+	return newMemoryStore(time.Now)
+}
+
+// newMemoryStore cria um MemoryStore com relógio customizado
+func newMemoryStore(now func() time.Time) *MemoryStore {
+	m := &MemoryStore{
+		entries: make(map[string]*memoryEntry),
+		now:     now,
+		stop:    make(chan struct{}),
+	}
+	go m.janitor(time.Minute)
+	return m
+}
+
+// janitor remove chaves expiradas periodicamente
+func (m *MemoryStore) janitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			m.mu.Lock()
+			now := m.now()
+			for key, e := range m.entries {
+				if e.expired(now) {
+					delete(m.entries, key)
+				}
+			}
+			m.mu.Unlock()
+		case <-m.stop:
+			return
+		}
+	}
+}
+
+// get retorna a entrada não expirada da chave (requer lock)
+func (m *MemoryStore) get(key string, now time.Time) (*memoryEntry, bool) {
+	e, ok := m.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if e.expired(now) {
+		delete(m.entries, key)
+		return nil, false
+	}
+	return e, true
+}
+
+// increment soma n ao contador definindo a expiração apenas no início da janela (requer lock)
+func (m *MemoryStore) increment(key string, n int64, expiry time.Duration, now time.Time) int64 {
+	e, ok := m.get(key, now)
+	if !ok {
+		e = &memoryEntry{expiresAt: now.Add(expiry)}
+		m.entries[key] = e
+	}
+	e.count += n
+	return e.count
+}
+
+// ttl retorna o tempo restante da entrada (zero se não expira)
+func (e *memoryEntry) ttl(now time.Time) time.Duration {
+	if e.expiresAt.IsZero() {
+		return 0
+	}
+	return e.expiresAt.Sub(now)
+}
+
+// setExpiring seta um valor com expiração (requer lock)
+func (m *MemoryStore) setExpiring(key, value string, expiry time.Duration, now time.Time) {
+	e := &memoryEntry{value: value}
+	if count, err := strconv.ParseInt(value, 10, 64); err == nil {
+		e.count = count
+	}
+	if expiry > 0 {
+		e.expiresAt = now.Add(expiry)
+	}
+	m.entries[key] = e
+}
+
+// Increment incrementa o contador e define expiração no primeiro incremento
+func (m *MemoryStore) Increment(ctx context.Context, key string, expiry time.Duration) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.increment(key, 1, expiry, m.now()), nil
+}
+
+// IncrementBy incrementa o contador em n e define expiração no primeiro incremento
+func (m *MemoryStore) IncrementBy(ctx context.Context, key string, n int64, expiry time.Duration) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.increment(key, n, expiry, m.now()), nil
+}
+
+// Escalate registra a infração e grava o bloqueio escalonado sob o mesmo lock
+func (m *MemoryStore) Escalate(ctx context.Context, req OffenceRequest) (OffenceResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	offences := m.increment(req.OffenceKey, 1, req.Lookback, now)
+	m.entries[req.OffenceKey].expiresAt = now.Add(req.Lookback)
+
+	res := OffenceResult{Offences: offences}
+	step := Escalation{Steps: req.Steps}.Duration(offences)
+	if step <= 0 {
+		return res, nil
+	}
+	if _, blocked := m.get(req.BlockKey, now); req.KeepUnblocked && !blocked {
+		return res, nil
+	}
+	m.setExpiring(req.BlockKey, blockValue(req.BlockReason), step, now)
+	res.BlockDuration = step
+	return res, nil
+}
+
+// GetCount retorna o contador atual
+func (m *MemoryStore) GetCount(ctx context.Context, key string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.get(key, m.now())
+	if !ok {
+		return 0, nil
+	}
+	return e.count, nil
+}
+
+// Exists verifica se a chave existe e não expirou
+func (m *MemoryStore) Exists(ctx context.Context, key string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.get(key, m.now())
+	return ok, nil
+}
+
+// SetExpiring seta um valor com expiração
+func (m *MemoryStore) SetExpiring(ctx context.Context, key string, value string, expiry time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.setExpiring(key, value, expiry, m.now())
+	return nil
+}
+
+// Delete remove as chaves e retorna quantas existiam
+func (m *MemoryStore) Delete(ctx context.Context, keys ...string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	var deleted int64
+	for _, key := range keys {
+		if _, ok := m.get(key, now); ok {
+			deleted++
+		}
+		delete(m.entries, key)
+	}
+	return deleted, nil
+}
+
+// ScanKeys percorre as chaves com o prefixo em ordem; o cursor é a última chave
+// retornada e a página seguinte começa na primeira chave maior que ela, sem repetir
+// nem pular chaves quando outras são criadas ou expiram entre as páginas
+func (m *MemoryStore) ScanKeys(ctx context.Context, prefix string, cursor string, count int64) ([]ScannedKey, string, error) {
+	if cursor != "" && !strings.HasPrefix(cursor, prefix) {
+		return nil, "", fmt.Errorf("%w: %s", ErrInvalidCursor, cursor)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	var keys []string
+	for key, e := range m.entries {
+		if strings.HasPrefix(key, prefix) && key > cursor && !e.expired(now) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	size := min(int(max(count, 1)), len(keys))
+	scanned := make([]ScannedKey, 0, size)
+	for _, key := range keys[:size] {
+		e := m.entries[key]
+		scanned = append(scanned, ScannedKey{Key: key, Value: e.value, TTL: e.ttl(now)})
+	}
+	if size == len(keys) {
+		return scanned, "", nil
+	}
+	return scanned, keys[size-1], nil
+}
+
+// AllowAtomic executa a verificação de janela fixa sob um único lock
+func (m *MemoryStore) AllowAtomic(ctx context.Context, req AtomicRequest) (AtomicResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	if block, blocked := m.get(req.BlockKey, now); blocked {
+		return AtomicResult{Blocked: true, RetryAfter: block.ttl(now), ResetAfter: block.ttl(now)}, nil
+	}
+
+	count := m.increment(req.CounterKey, costOf(req.Cost), req.Window, now)
+	resetAfter := m.entries[req.CounterKey].ttl(now)
+	if count > req.Limit {
+		retryAfter := resetAfter
+		if req.BlockDuration > 0 {
+			m.setExpiring(req.BlockKey, blockValue(req.BlockReason), req.BlockDuration, now)
+			retryAfter = max(retryAfter, req.BlockDuration)
+		}
+		return AtomicResult{Count: count, Blocked: true, Tripped: true, RetryAfter: retryAfter, ResetAfter: resetAfter}, nil
+	}
+	return AtomicResult{Count: count, ResetAfter: resetAfter}, nil
+}
+
+// AllowAtomicMulti verifica várias janelas fixas sob um único lock
+func (m *MemoryStore) AllowAtomicMulti(ctx context.Context, req MultiAtomicRequest) (MultiAtomicResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	if block, blocked := m.get(req.BlockKey, now); blocked {
+		return MultiAtomicResult{Blocked: true, Tripped: -1, BlockedBy: block.value, RetryAfter: block.ttl(now)}, nil
+	}
+
+	// Verifica todos os contadores antes de incrementar
+	cost := costOf(req.Cost)
+	res := MultiAtomicResult{Tripped: -1, Counts: make([]int64, len(req.Counters)), Resets: make([]time.Duration, len(req.Counters))}
+	for i, c := range req.Counters {
+		var count int64
+		if e, ok := m.get(c.Key, now); ok {
+			count = e.count
+			res.Resets[i] = e.ttl(now)
+		}
+		res.Counts[i] = count + cost
+		if res.Tripped < 0 && res.Counts[i] > c.Limit {
+			res.Tripped = i
+		}
+	}
+
+	if res.Tripped >= 0 {
+		tripped := req.Counters[res.Tripped]
+		// Sem contador existente, a janela inteira precisa passar
+		res.RetryAfter = tripped.Window
+		if e, ok := m.get(tripped.Key, now); ok {
+			res.RetryAfter = e.ttl(now)
+		}
+		if tripped.BlockDuration > 0 {
+			m.setExpiring(req.BlockKey, tripped.Description, tripped.BlockDuration, now)
+			res.RetryAfter = max(res.RetryAfter, tripped.BlockDuration)
+		}
+		res.Blocked = true
+		return res, nil
+	}
+
+	for i, c := range req.Counters {
+		res.Counts[i] = m.increment(c.Key, cost, c.Window, now)
+		res.Resets[i] = m.entries[c.Key].ttl(now)
+	}
+	return res, nil
+}
+
+// TakeToken consome um token do bucket sob um único lock
+func (m *MemoryStore) TakeToken(ctx context.Context, req TokenBucketRequest) (TokenBucketResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	if block, blocked := m.get(req.BlockKey, now); blocked {
+		return TokenBucketResult{Blocked: true, RetryAfter: block.ttl(now), ResetAfter: block.ttl(now)}, nil
+	}
+
+	capacity := float64(req.Capacity)
+	e, ok := m.get(req.BucketKey, now)
+	if !ok {
+		e = &memoryEntry{tokens: capacity, updatedAt: now}
+		m.entries[req.BucketKey] = e
+	}
+
+	// Reposição proporcional ao tempo decorrido
+	elapsed := math.Max(0, now.Sub(e.updatedAt).Seconds())
+	e.tokens = math.Min(capacity, e.tokens+elapsed*req.RefillRate)
+	e.updatedAt = now
+
+	cost := float64(costOf(req.Cost))
+	blocked := true
+	if e.tokens >= cost {
+		e.tokens -= cost
+		blocked = false
+	}
+
+	// Bucket cheio novamente equivale a estado inicial: pode expirar
+	ttl := 24 * time.Hour
+	var resetAfter time.Duration
+	if req.RefillRate > 0 {
+		resetAfter = time.Duration(math.Ceil((capacity - e.tokens) / req.RefillRate * float64(time.Second)))
+		ttl = resetAfter + time.Second
+	}
+	e.expiresAt = now.Add(ttl)
+
+	res := TokenBucketResult{Remaining: int64(math.Floor(e.tokens)), Blocked: blocked, Tripped: blocked, ResetAfter: resetAfter}
+	if blocked {
+		if req.RefillRate > 0 {
+			res.RetryAfter = time.Duration(math.Ceil((cost - e.tokens) / req.RefillRate * float64(time.Second)))
+		}
+		if req.BlockDuration > 0 {
+			m.setExpiring(req.BlockKey, blockValue(req.BlockReason), req.BlockDuration, now)
+			res.RetryAfter = max(res.RetryAfter, req.BlockDuration)
+		}
+	}
+	return res, nil
+}
+
+// AllowSlidingWindow aplica a janela deslizante por contador sob um único lock
+func (m *MemoryStore) AllowSlidingWindow(ctx context.Context, req AtomicRequest) (AtomicResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	if block, blocked := m.get(req.BlockKey, now); blocked {
+		return AtomicResult{Blocked: true, RetryAfter: block.ttl(now), ResetAfter: block.ttl(now)}, nil
+	}
+
+	idx := now.UnixNano() / int64(req.Window)
+	e, ok := m.get(req.CounterKey, now)
+	if !ok {
+		e = &memoryEntry{window: idx}
+		m.entries[req.CounterKey] = e
+	}
+
+	// Avança as janelas: a atual vira anterior ou ambas são descartadas
+	switch e.window {
+	case idx:
+	case idx - 1:
+		e.prev, e.count = e.count, 0
+	default:
+		e.prev, e.count = 0, 0
+	}
+	e.window = idx
+	e.expiresAt = now.Add(2 * req.Window)
+
+	elapsed := now.UnixNano() - idx*int64(req.Window)
+	weight := float64(int64(req.Window)-elapsed) / float64(req.Window)
+
+	cost := costOf(req.Cost)
+	blocked := true
+	if float64(e.prev)*weight+float64(e.count+cost) <= float64(req.Limit) {
+		e.count += cost
+		blocked = false
+	}
+
+	res := AtomicResult{Count: int64(math.Ceil(float64(e.prev)*weight)) + e.count, Blocked: blocked, Tripped: blocked}
+	// A contagem zera quando as janelas atual e anterior deixam de pesar
+	if e.count > 0 {
+		res.ResetAfter = time.Duration(2*int64(req.Window) - elapsed)
+	} else if e.prev > 0 {
+		res.ResetAfter = time.Duration(int64(req.Window) - elapsed)
+	}
+	if blocked {
+		// Instante em que o peso da janela anterior cai o suficiente, ou o fim da janela atual
+		res.RetryAfter = time.Duration(int64(req.Window) - elapsed)
+		if e.count+cost <= req.Limit {
+			target := float64(req.Window) * (1 - float64(req.Limit-e.count-cost)/float64(e.prev))
+			res.RetryAfter = time.Duration(math.Ceil(target - float64(elapsed)))
+		}
+		if req.BlockDuration > 0 {
+			m.setExpiring(req.BlockKey, blockValue(req.BlockReason), req.BlockDuration, now)
+			res.RetryAfter = max(res.RetryAfter, req.BlockDuration)
+		}
+	}
+	return res, nil
+}
+
+// AllowSlidingLog aplica a janela deslizante por log sob um único lock
+func (m *MemoryStore) AllowSlidingLog(ctx context.Context, req AtomicRequest) (AtomicResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	if block, blocked := m.get(req.BlockKey, now); blocked {
+		return AtomicResult{Blocked: true, RetryAfter: block.ttl(now), ResetAfter: block.ttl(now)}, nil
+	}
+
+	e, ok := m.get(req.CounterKey, now)
+	if !ok {
+		e = &memoryEntry{}
+		m.entries[req.CounterKey] = e
+	}
+
+	// Descarta registros fora da janela
+	cutoff := now.Add(-req.Window)
+	kept := e.log[:0]
+	for _, ts := range e.log {
+		if ts.After(cutoff) {
+			kept = append(kept, ts)
+		}
+	}
+	e.log = kept
+
+	cost := costOf(req.Cost)
+	blocked := true
+	if int64(len(e.log))+cost <= req.Limit {
+		// Um registro por unidade de custo
+		for range cost {
+			e.log = append(e.log, now)
+		}
+		blocked = false
+	}
+	e.expiresAt = now.Add(req.Window)
+
+	res := AtomicResult{Count: int64(len(e.log)), Blocked: blocked, Tripped: blocked}
+	// A janela esvazia quando o registro mais recente sai dela
+	if len(e.log) > 0 {
+		res.ResetAfter = e.log[len(e.log)-1].Add(req.Window).Sub(now)
+	}
+	if blocked {
+		// Espera até sair da janela o registro que libera espaço para o custo
+		res.RetryAfter = req.Window
+		if excess := int64(len(e.log)) + cost - req.Limit; excess <= int64(len(e.log)) {
+			res.RetryAfter = e.log[excess-1].Add(req.Window).Sub(now)
+		}
+		if req.BlockDuration > 0 {
+			m.setExpiring(req.BlockKey, blockValue(req.BlockReason), req.BlockDuration, now)
+			res.RetryAfter = max(res.RetryAfter, req.BlockDuration)
+		}
+	}
+	return res, nil
+}
+
+// AllowGCRA aplica o GCRA sob um único lock
+func (m *MemoryStore) AllowGCRA(ctx context.Context, req GCRARequest) (GCRAResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	if block, blocked := m.get(req.BlockKey, now); blocked {
+		ttl := block.expiresAt.Sub(now)
+		return GCRAResult{Blocked: true, RetryAfter: ttl, ResetAfter: ttl}, nil
+	}
+
+	tat := now
+	if e, ok := m.get(req.Key, now); ok && e.tat.After(now) {
+		tat = e.tat
+	}
+
+	newTAT := tat.Add(req.EmissionInterval * time.Duration(costOf(req.Cost)))
+	allowAt := newTAT.Add(-req.Tolerance)
+	if now.Before(allowAt) {
+		res := GCRAResult{Blocked: true, Tripped: true, RetryAfter: allowAt.Sub(now), ResetAfter: tat.Sub(now)}
+		if req.BlockDuration > 0 {
+			m.setExpiring(req.BlockKey, blockValue(req.BlockReason), req.BlockDuration, now)
+			res.RetryAfter = max(res.RetryAfter, req.BlockDuration)
+			res.ResetAfter = max(res.ResetAfter, req.BlockDuration)
+		}
+		return res, nil
+	}
+
+	m.entries[req.Key] = &memoryEntry{tat: newTAT, expiresAt: newTAT}
+	return GCRAResult{
+		Remaining:  int64(now.Sub(allowAt) / req.EmissionInterval),
+		ResetAfter: newTAT.Sub(now),
+	}, nil
+}
+
+// TTL retorna o tempo restante até a chave expirar (zero se não existe ou não expira)
+func (m *MemoryStore) TTL(ctx context.Context, key string) (time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	e, ok := m.get(key, now)
+	if !ok {
+		return 0, nil
+	}
+	return e.ttl(now), nil
+}
+
+// PeekTokenBucket consulta o bucket aplicando a reposição sem alterá-lo
+func (m *MemoryStore) PeekTokenBucket(ctx context.Context, req TokenBucketRequest) (PeekResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	e, ok := m.get(req.BucketKey, now)
+	if !ok {
+		return PeekResult{Remaining: req.Capacity}, nil
+	}
+
+	capacity := float64(req.Capacity)
+	tokens := math.Min(capacity, e.tokens+math.Max(0, now.Sub(e.updatedAt).Seconds())*req.RefillRate)
+	res := PeekResult{Remaining: int64(math.Floor(tokens))}
+	res.Count = req.Capacity - res.Remaining
+	if req.RefillRate > 0 {
+		res.ResetAfter = time.Duration(math.Ceil((capacity - tokens) / req.RefillRate * float64(time.Second)))
+	}
+	return res, nil
+}
+
+// PeekSlidingWindow consulta a contagem estimada da janela deslizante
+func (m *MemoryStore) PeekSlidingWindow(ctx context.Context, req AtomicRequest) (PeekResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	idx := now.UnixNano() / int64(req.Window)
+	var cur, prev int64
+	if e, ok := m.get(req.CounterKey, now); ok {
+		switch e.window {
+		case idx:
+			cur, prev = e.count, e.prev
+		case idx - 1:
+			prev = e.count
+		}
+	}
+
+	elapsed := time.Duration(now.UnixNano() - idx*int64(req.Window))
+	weight := float64(req.Window-elapsed) / float64(req.Window)
+	res := PeekResult{Count: int64(math.Ceil(float64(prev)*weight)) + cur}
+	res.Remaining = max(req.Limit-res.Count, 0)
+	switch {
+	case cur > 0:
+		res.ResetAfter = 2*req.Window - elapsed
+	case prev > 0:
+		res.ResetAfter = req.Window - elapsed
+	}
+	return res, nil
+}
+
+// PeekSlidingLog conta os registros dentro da janela sem removê-los
+func (m *MemoryStore) PeekSlidingLog(ctx context.Context, req AtomicRequest) (PeekResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	var res PeekResult
+	if e, ok := m.get(req.CounterKey, now); ok {
+		cutoff := now.Add(-req.Window)
+		for _, ts := range e.log {
+			if ts.After(cutoff) {
+				res.Count++
+			}
+		}
+		if res.Count > 0 {
+			res.ResetAfter = e.log[len(e.log)-1].Add(req.Window).Sub(now)
+		}
+	}
+	res.Remaining = max(req.Limit-res.Count, 0)
+	return res, nil
+}
+
+// PeekGCRA calcula quantas requisições o TAT atual ainda permite
+func (m *MemoryStore) PeekGCRA(ctx context.Context, req GCRARequest) (PeekResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	tat := now
+	if e, ok := m.get(req.Key, now); ok && e.tat.After(now) {
+		tat = e.tat
+	}
+
+	burst := int64(req.Tolerance / req.EmissionInterval)
+	remaining := max(int64((now.Sub(tat)+req.Tolerance)/req.EmissionInterval), 0)
+	return PeekResult{Count: max(burst-remaining, 0), Remaining: remaining, ResetAfter: tat.Sub(now)}, nil
+}
+
+// Close interrompe a limpeza periódica de chaves expiradas
+func (m *MemoryStore) Close() error {
+	m.once.Do(func() { close(m.stop) })
+	return nil
+}
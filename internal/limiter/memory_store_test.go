@@ -0,0 +1,429 @@
+package limiter
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock permite controlar o tempo nos testes do MemoryStore
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestMemoryStore_IncrementAndExpiry(t *testing.T) {
+	clock := newFakeClock()
+	store := newMemoryStore(clock.Now)
+	defer store.Close()
+	ctx := context.Background()
+
+	for i := int64(1); i <= 3; i++ {
+		count, err := store.Increment(ctx, "k", time.Second)
+		if err != nil {
+			t.Fatalf("Erro inesperado: %v", err)
+		}
+		if count != i {
+			t.Errorf("Count esperado %d, obtido %d", i, count)
+		}
+		clock.Advance(300 * time.Millisecond)
+	}
+
+	// Incrementos não renovam a janela: expira 1s após o primeiro
+	clock.Advance(200 * time.Millisecond)
+	count, _ := store.GetCount(ctx, "k")
+	if count != 0 {
+		t.Errorf("Count após expiração esperado 0, obtido %d", count)
+	}
+}
+
+func TestMemoryStore_Escalate(t *testing.T) {
+	clock := newFakeClock()
+	store := newMemoryStore(clock.Now)
+	defer store.Close()
+	ctx := context.Background()
+	req := OffenceRequest{OffenceKey: "off", BlockKey: "blk", Lookback: time.Hour, Steps: []time.Duration{time.Minute}}
+
+	// Infrações simultâneas não se perdem
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			store.Escalate(ctx, req)
+		}()
+	}
+	wg.Wait()
+	if count, _ := store.GetCount(ctx, "off"); count != 50 {
+		t.Errorf("Esperado 50 infrações, obtido %d", count)
+	}
+
+	// Cada infração renova o período, ao contrário de IncrementBy
+	clock.Advance(45 * time.Minute)
+	store.Escalate(ctx, req)
+	clock.Advance(45 * time.Minute)
+	if count, _ := store.GetCount(ctx, "off"); count != 51 {
+		t.Errorf("A contagem deveria ser mantida até 1h após a última infração, obtido %d", count)
+	}
+	clock.Advance(15 * time.Minute)
+	if count, _ := store.GetCount(ctx, "off"); count != 0 {
+		t.Errorf("A contagem deveria expirar 1h após a última infração, obtido %d", count)
+	}
+}
+
+func TestMemoryStore_Escalate_Block(t *testing.T) {
+	// Setup
+	clock := newFakeClock()
+	store := newMemoryStore(clock.Now)
+	defer store.Close()
+	ctx := context.Background()
+	req := OffenceRequest{
+		OffenceKey:    "off",
+		BlockKey:      "blk",
+		Lookback:      time.Hour,
+		Steps:         []time.Duration{time.Minute, 5 * time.Minute},
+		BlockReason:   "10/1s",
+		KeepUnblocked: true,
+	}
+	store.SetExpiring(ctx, "blk", "10/1s", 10*time.Second)
+
+	// Execute
+	first, _ := store.Escalate(ctx, req)
+	store.Delete(ctx, "blk") // desbloqueio entre a verificação e o escalonamento
+	second, _ := store.Escalate(ctx, req)
+
+	// Assert
+	if first.Offences != 1 || first.BlockDuration != time.Minute {
+		t.Errorf("1ª infração: esperado bloqueio de 1m, obtido %+v", first)
+	}
+	if second.Offences != 2 || second.BlockDuration != 0 {
+		t.Errorf("2ª infração: esperado contagem 2 sem bloqueio, obtido %+v", second)
+	}
+	if exists, _ := store.Exists(ctx, "blk"); exists {
+		t.Error("O bloqueio removido não deveria ser recriado")
+	}
+}
+
+func TestMemoryStore_ScanKeys(t *testing.T) {
+	// Setup
+	clock := newFakeClock()
+	store := newMemoryStore(clock.Now)
+	defer store.Close()
+	ctx := context.Background()
+	store.SetExpiring(ctx, "blk:a", "1", time.Second)
+	store.SetExpiring(ctx, "blk:b", "1", time.Minute)
+	store.SetExpiring(ctx, "blk:c", "1", time.Minute)
+	store.SetExpiring(ctx, "blk:d", "1", time.Minute)
+	store.SetExpiring(ctx, "other", "1", time.Minute)
+
+	// Execute - "blk:a" expira e "blk:0" e "blk:e" são criadas entre as páginas
+	first, cursor, err := store.ScanKeys(ctx, "blk:", "", 2)
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	clock.Advance(2 * time.Second)
+	store.SetExpiring(ctx, "blk:0", "1", time.Minute)
+	store.SetExpiring(ctx, "blk:e", "1", time.Minute)
+	var rest []ScannedKey
+	for cursor != "" {
+		var page []ScannedKey
+		if page, cursor, err = store.ScanKeys(ctx, "blk:", cursor, 2); err != nil {
+			t.Fatalf("Erro inesperado: %v", err)
+		}
+		rest = append(rest, page...)
+	}
+
+	// Assert - nenhuma chave repetida ou pulada
+	var got []string
+	for _, k := range append(first, rest...) {
+		got = append(got, k.Key)
+	}
+	if want := "blk:a,blk:b,blk:c,blk:d,blk:e"; strings.Join(got, ",") != want {
+		t.Errorf("Chaves esperadas %s, obtido %v", want, got)
+	}
+	if _, _, err := store.ScanKeys(ctx, "blk:", "abc", 2); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("Esperado ErrInvalidCursor, obtido %v", err)
+	}
+}
+
+func TestMemoryStore_SetExpiringAndExists(t *testing.T) {
+	clock := newFakeClock()
+	store := newMemoryStore(clock.Now)
+	defer store.Close()
+	ctx := context.Background()
+
+	if err := store.SetExpiring(ctx, "blk", "1", time.Second); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	exists, _ := store.Exists(ctx, "blk")
+	if !exists {
+		t.Error("Chave deveria existir")
+	}
+
+	clock.Advance(time.Second)
+	exists, _ = store.Exists(ctx, "blk")
+	if exists {
+		t.Error("Chave deveria ter expirado")
+	}
+}
+
+func TestMemoryStore_TakeToken_BurstAndRefill(t *testing.T) {
+	clock := newFakeClock()
+	store := newMemoryStore(clock.Now)
+	defer store.Close()
+	ctx := context.Background()
+
+	req := TokenBucketRequest{
+		BucketKey:  "rl:tb:k",
+		BlockKey:   "rl:blk:k",
+		Capacity:   5,
+		RefillRate: 2,
+	}
+
+	// Rajada até a capacidade
+	for i := 0; i < 5; i++ {
+		res, err := store.TakeToken(ctx, req)
+		if err != nil {
+			t.Fatalf("Erro inesperado: %v", err)
+		}
+		if res.Blocked {
+			t.Errorf("Token %d deveria ser concedido", i+1)
+		}
+		if res.Remaining != int64(4-i) {
+			t.Errorf("Restantes esperado %d, obtido %d", 4-i, res.Remaining)
+		}
+	}
+
+	res, _ := store.TakeToken(ctx, req)
+	if !res.Blocked {
+		t.Error("Bucket vazio deveria negar a requisição")
+	}
+
+	// 2 tokens/s: após 500ms há 1 token disponível
+	clock.Advance(500 * time.Millisecond)
+	res, _ = store.TakeToken(ctx, req)
+	if res.Blocked {
+		t.Error("Token reposto deveria ser concedido")
+	}
+	res, _ = store.TakeToken(ctx, req)
+	if !res.Blocked {
+		t.Error("Não deveria haver token disponível")
+	}
+}
+
+func TestMemoryStore_TakeToken_Block(t *testing.T) {
+	clock := newFakeClock()
+	store := newMemoryStore(clock.Now)
+	defer store.Close()
+	ctx := context.Background()
+
+	req := TokenBucketRequest{
+		BucketKey:     "rl:tb:k",
+		BlockKey:      "rl:blk:k",
+		Capacity:      1,
+		RefillRate:    10,
+		BlockDuration: 5 * time.Second,
+	}
+
+	store.TakeToken(ctx, req)
+	if res, _ := store.TakeToken(ctx, req); !res.Blocked {
+		t.Fatal("Segunda requisição deveria ser negada")
+	}
+
+	// Mesmo com o bucket reposto, o bloqueio prevalece
+	clock.Advance(time.Second)
+	if res, _ := store.TakeToken(ctx, req); !res.Blocked {
+		t.Error("Deveria continuar bloqueado")
+	}
+
+	clock.Advance(4 * time.Second)
+	if res, _ := store.TakeToken(ctx, req); res.Blocked {
+		t.Error("Deveria permitir após o bloqueio")
+	}
+}
+
+func TestMemoryStore_AllowSlidingWindow_Weighted(t *testing.T) {
+	clock := newFakeClock()
+	store := newMemoryStore(clock.Now)
+	defer store.Close()
+	ctx := context.Background()
+
+	req := AtomicRequest{CounterKey: "rl:sw:k", BlockKey: "rl:blk:k", Limit: 4, Window: time.Second}
+
+	for i := 0; i < 4; i++ {
+		if res, _ := store.AllowSlidingWindow(ctx, req); res.Blocked {
+			t.Fatalf("Requisição %d deveria ser permitida", i+1)
+		}
+	}
+
+	// Metade da janela seguinte: anterior pesa 50% (2 de 4), restam 2
+	clock.Advance(1500 * time.Millisecond)
+	for i := 0; i < 2; i++ {
+		if res, _ := store.AllowSlidingWindow(ctx, req); res.Blocked {
+			t.Errorf("Requisição %d deveria ser permitida", i+1)
+		}
+	}
+	if res, _ := store.AllowSlidingWindow(ctx, req); !res.Blocked {
+		t.Error("Requisição além da estimativa deveria ser negada")
+	}
+
+	// Duas janelas depois o estado é descartado
+	clock.Advance(2 * time.Second)
+	res, _ := store.AllowSlidingWindow(ctx, req)
+	if res.Blocked || res.Count != 1 {
+		t.Errorf("Esperado contagem 1 permitida, obtido %d (bloqueado=%v)", res.Count, res.Blocked)
+	}
+}
+
+func TestMemoryStore_AllowSlidingLog_Expiry(t *testing.T) {
+	clock := newFakeClock()
+	store := newMemoryStore(clock.Now)
+	defer store.Close()
+	ctx := context.Background()
+
+	req := AtomicRequest{CounterKey: "rl:sl:k", BlockKey: "rl:blk:k", Limit: 2, Window: time.Second}
+
+	store.AllowSlidingLog(ctx, req)
+	clock.Advance(600 * time.Millisecond)
+	store.AllowSlidingLog(ctx, req)
+
+	if res, _ := store.AllowSlidingLog(ctx, req); !res.Blocked {
+		t.Error("Terceira requisição na janela deveria ser negada")
+	}
+
+	// Primeiro registro sai da janela
+	clock.Advance(500 * time.Millisecond)
+	res, _ := store.AllowSlidingLog(ctx, req)
+	if res.Blocked {
+		t.Error("Requisição deveria ser permitida após o primeiro registro expirar")
+	}
+	if res.Count != 2 {
+		t.Errorf("Contagem esperada 2, obtido %d", res.Count)
+	}
+}
+
+func TestMemoryStore_AllowGCRA(t *testing.T) {
+	clock := newFakeClock()
+	store := newMemoryStore(clock.Now)
+	defer store.Close()
+	ctx := context.Background()
+
+	// 10 req/s com rajada de 2
+	req := GCRARequest{
+		Key:              "rl:gcra:k",
+		BlockKey:         "rl:blk:k",
+		EmissionInterval: 100 * time.Millisecond,
+		Tolerance:        200 * time.Millisecond,
+	}
+
+	res, _ := store.AllowGCRA(ctx, req)
+	if res.Blocked || res.Remaining != 1 {
+		t.Errorf("Primeira requisição: esperado permitida com 1 restante, obtido %+v", res)
+	}
+	res, _ = store.AllowGCRA(ctx, req)
+	if res.Blocked || res.Remaining != 0 {
+		t.Errorf("Segunda requisição: esperado permitida com 0 restantes, obtido %+v", res)
+	}
+	if res.ResetAfter != 200*time.Millisecond {
+		t.Errorf("ResetAfter esperado 200ms, obtido %v", res.ResetAfter)
+	}
+
+	res, _ = store.AllowGCRA(ctx, req)
+	if !res.Blocked {
+		t.Fatal("Terceira requisição deveria ser negada")
+	}
+	if res.RetryAfter != 100*time.Millisecond {
+		t.Errorf("RetryAfter esperado 100ms, obtido %v", res.RetryAfter)
+	}
+
+	clock.Advance(res.RetryAfter)
+	if res, _ = store.AllowGCRA(ctx, req); res.Blocked {
+		t.Error("Requisição após RetryAfter deveria ser permitida")
+	}
+}
+
+func TestMemoryStore_AllowGCRA_Block(t *testing.T) {
+	clock := newFakeClock()
+	store := newMemoryStore(clock.Now)
+	defer store.Close()
+	ctx := context.Background()
+
+	req := GCRARequest{
+		Key:              "rl:gcra:k",
+		BlockKey:         "rl:blk:k",
+		EmissionInterval: 100 * time.Millisecond,
+		Tolerance:        100 * time.Millisecond,
+		BlockDuration:    5 * time.Second,
+	}
+
+	store.AllowGCRA(ctx, req)
+	res, _ := store.AllowGCRA(ctx, req)
+	if !res.Blocked || res.RetryAfter != 5*time.Second {
+		t.Fatalf("Esperado bloqueio de 5s, obtido %+v", res)
+	}
+
+	clock.Advance(2 * time.Second)
+	res, _ = store.AllowGCRA(ctx, req)
+	if !res.Blocked || res.RetryAfter != 3*time.Second {
+		t.Errorf("Esperado bloqueio restante de 3s, obtido %+v", res)
+	}
+}
+
+func TestMemoryStore_RetryAfter(t *testing.T) {
+	clock := newFakeClock()
+	store := newMemoryStore(clock.Now)
+	defer store.Close()
+	ctx := context.Background()
+
+	// Janela fixa: espera até o fim da janela aberta pelo primeiro incremento
+	fixed := AtomicRequest{CounterKey: "rl:cnt:k", BlockKey: "rl:blk:k", Limit: 1, Window: time.Second}
+	store.AllowAtomic(ctx, fixed)
+	clock.Advance(400 * time.Millisecond)
+	if res, _ := store.AllowAtomic(ctx, fixed); res.RetryAfter != 600*time.Millisecond {
+		t.Errorf("Janela fixa: RetryAfter esperado 600ms, obtido %v", res.RetryAfter)
+	}
+
+	// Token bucket: um token a cada 100ms
+	bucket := TokenBucketRequest{BucketKey: "rl:tb:k", BlockKey: "rl:blk:k", Capacity: 1, RefillRate: 10}
+	store.TakeToken(ctx, bucket)
+	if res, _ := store.TakeToken(ctx, bucket); res.RetryAfter != 100*time.Millisecond {
+		t.Errorf("Token bucket: RetryAfter esperado 100ms, obtido %v", res.RetryAfter)
+	}
+
+	// Log deslizante: espera o registro mais antigo sair da janela
+	log := AtomicRequest{CounterKey: "rl:sl:k", BlockKey: "rl:blk:k", Limit: 2, Window: time.Second}
+	store.AllowSlidingLog(ctx, log)
+	clock.Advance(300 * time.Millisecond)
+	store.AllowSlidingLog(ctx, log)
+	if res, _ := store.AllowSlidingLog(ctx, log); res.RetryAfter != 700*time.Millisecond {
+		t.Errorf("Log deslizante: RetryAfter esperado 700ms, obtido %v", res.RetryAfter)
+	}
+
+	// Bloqueio: espera o bloqueio expirar
+	blocking := AtomicRequest{CounterKey: "rl:cnt:b", BlockKey: "rl:blk:b", Limit: 0, Window: time.Second, BlockDuration: time.Minute}
+	store.AllowAtomic(ctx, blocking)
+	clock.Advance(10 * time.Second)
+	if res, _ := store.AllowAtomic(ctx, blocking); res.RetryAfter != 50*time.Second {
+		t.Errorf("Bloqueio: RetryAfter esperado 50s, obtido %v", res.RetryAfter)
+	}
+}
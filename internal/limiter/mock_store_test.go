@@ -32,6 +32,11 @@ func (m *MockStore) SetShouldFail(fail bool) {
 
 // Increment incrementa o contador
 func (m *MockStore) Increment(ctx context.Context, key string, expiry time.Duration) (int64, error) {
+	return m.IncrementBy(ctx, key, 1, expiry)
+}
+
+// IncrementBy incrementa o contador em n
+func (m *MockStore) IncrementBy(ctx context.Context, key string, n int64, expiry time.Duration) (int64, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -47,7 +52,7 @@ func (m *MockStore) Increment(ctx context.Context, key string, expiry time.Durat
 		}
 	}
 
-	m.counters[key]++
+	m.counters[key] += n
 	m.expiries[key] = time.Now().Add(expiry)
 
 	return m.counters[key], nil
@@ -112,3 +117,80 @@ func (m *MockStore) SetExpiring(ctx context.Context, key string, _ string, expir
 	m.expiries[key] = time.Now().Add(expiry)
 	return nil
 }
+
+// Delete remove contadores e expirações das chaves
+func (m *MockStore) Delete(ctx context.Context, keys ...string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.shouldFail {
+		return 0, fmt.Errorf("mock error: simulated failure")
+	}
+	var deleted int64
+	for _, key := range keys {
+		_, counted := m.counters[key]
+		_, expiring := m.expiries[key]
+		if counted || expiring {
+			deleted++
+		}
+		delete(m.counters, key)
+		delete(m.expiries, key)
+	}
+	return deleted, nil
+}
+
+// TTL retorna o tempo restante até a chave expirar
+func (m *MockStore) TTL(ctx context.Context, key string) (time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.shouldFail {
+		return 0, fmt.Errorf("mock error: simulated failure")
+	}
+	exp, ok := m.expiries[key]
+	if !ok || time.Now().After(exp) {
+		return 0, nil
+	}
+	return time.Until(exp), nil
+}
+
+// AtomicMockStore adiciona a capacidade AtomicStore ao MockStore
+type AtomicMockStore struct {
+	*MockStore
+	atomicCalls int
+}
+
+// NewAtomicMockStore cria uma nova instância de AtomicMockStore
+func NewAtomicMockStore() *AtomicMockStore {
+	return &AtomicMockStore{MockStore: NewMockStore()}
+}
+
+// AllowAtomic simula o script atômico sob um único lock
+func (m *AtomicMockStore) AllowAtomic(ctx context.Context, req AtomicRequest) (AtomicResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.atomicCalls++
+	if m.shouldFail {
+		return AtomicResult{}, fmt.Errorf("mock error: simulated failure")
+	}
+
+	now := time.Now()
+	if exp, ok := m.expiries[req.BlockKey]; ok && now.Before(exp) {
+		return AtomicResult{Blocked: true}, nil
+	}
+
+	// Janela só é definida no primeiro incremento
+	if exp, ok := m.expiries[req.CounterKey]; !ok || now.After(exp) {
+		m.counters[req.CounterKey] = 0
+		m.expiries[req.CounterKey] = now.Add(req.Window)
+	}
+	m.counters[req.CounterKey] += costOf(req.Cost)
+	count := m.counters[req.CounterKey]
+
+	if count > req.Limit {
+		if req.BlockDuration > 0 {
+			m.expiries[req.BlockKey] = now.Add(req.BlockDuration)
+		}
+		return AtomicResult{Count: count, Blocked: true, Tripped: true}, nil
+	}
+	return AtomicResult{Count: count}, nil
+}
@@ -0,0 +1,207 @@
+package limiter
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// QuotaPeriod define o período de calendário de uma cota
+type QuotaPeriod string
+
+const (
+	// QuotaDaily reinicia à meia-noite do fuso configurado
+	QuotaDaily QuotaPeriod = "day"
+	// QuotaMonthly reinicia no primeiro dia de cada mês
+	QuotaMonthly QuotaPeriod = "month"
+)
+
+// quotaRetention mantém o contador por um tempo após o fim do período para consulta
+const quotaRetention = 7 * 24 * time.Hour
+
+// Quota descreve uma cota de longo prazo (ex.: 50000 requisições por mês)
+type Quota struct {
+	Limit  int64
+	Period QuotaPeriod
+}
+
+// String descreve a cota no formato LIMITE/PERÍODO (ex.: 50000/month)
+func (q Quota) String() string {
+	return strconv.FormatInt(q.Limit, 10) + "/" + string(q.Period)
+}
+
+// QuotaStatus representa o consumo de uma cota no período atual
+type QuotaStatus struct {
+	Allowed   bool
+	Used      int64
+	Limit     int64
+	Remaining int64
+	// ResetAt é o início do próximo período
+	ResetAt time.Time
+	// Failure é a política aplicada quando o store falhou (vazio: resultado do store)
+	Failure FailurePolicy
+}
+
+// QuotaManager controla cotas de longo prazo com reinício alinhado ao calendário
+// O uso é persistido no store com uma chave por período, sobrevivendo a reinícios
+// da aplicação. Diferente do CoreLimiter, requisições negadas não consomem a cota.
+type QuotaManager struct {
+	store    LimiterStoreStrategy
+	location *time.Location
+	now      func() time.Time
+
+	// Política aplicada quando o store falha (ver SetFailurePolicy)
+	failurePolicy FailurePolicy
+	fallback      *QuotaManager
+	localPercent  int
+	// local identifica o QuotaManager em memória usado por FailLocal, que não publica métricas
+	local    bool
+	degraded atomic.Bool
+}
+
+// NewQuotaManager cria um QuotaManager que calcula os períodos no fuso informado
+// Se location for nil, usa UTC
+func NewQuotaManager(store LimiterStoreStrategy, location *time.Location) *QuotaManager {
+	if location == nil {
+		location = time.UTC
+	}
+	return &QuotaManager{store: store, location: location, now: time.Now, failurePolicy: FailOpen}
+}
+
+// SetFailurePolicy define o resultado do consumo quando o store falha, como em
+// CoreLimiter.SetFailurePolicy e com as mesmas métricas. Com FailLocal, as cotas
+// passam a ser contadas em memória, reduzidas a localPercent%.
+func (q *QuotaManager) SetFailurePolicy(policy FailurePolicy, localPercent int) error {
+	if err := validateFailurePolicy(policy, localPercent); err != nil {
+		return err
+	}
+	if policy == FailLocal {
+		if q.fallback == nil {
+			q.fallback = NewQuotaManager(NewMemoryStore(), q.location)
+			q.fallback.local = true
+		}
+		q.localPercent = localPercent
+	}
+	q.failurePolicy = policy
+	return nil
+}
+
+// periodBounds retorna início e fim do período que contém o instante
+func (q *QuotaManager) periodBounds(period QuotaPeriod, t time.Time) (time.Time, time.Time, error) {
+	t = t.In(q.location)
+	switch period {
+	case QuotaDaily:
+		start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, q.location)
+		return start, start.AddDate(0, 0, 1), nil
+	case QuotaMonthly:
+		start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, q.location)
+		return start, start.AddDate(0, 1, 0), nil
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("período de cota desconhecido: %s", period)
+	}
+}
+
+// quotaKey monta a chave do período: rl:quota:<chave>:<período>:<início>
+func quotaKey(key string, period QuotaPeriod, start time.Time) string {
+	return "rl:quota:" + key + ":" + string(period) + ":" + start.Format("2006-01-02")
+}
+
+// Consume consome uma unidade da cota da chave
+// Em caso de erro do store o resultado segue a política de falha (fail-open por
+// padrão), QuotaStatus.Failure informa a política aplicada e o erro é retornado
+func (q *QuotaManager) Consume(ctx context.Context, key string, quota Quota) (*QuotaStatus, error) {
+	now := q.now()
+	start, end, err := q.periodBounds(quota.Period, now)
+	if err != nil {
+		return &QuotaStatus{Allowed: true, Limit: quota.Limit}, err
+	}
+
+	status := &QuotaStatus{Limit: quota.Limit, ResetAt: end}
+	if err := q.consume(ctx, quotaKey(key, quota.Period, start), end.Sub(now)+quotaRetention, quota, status); err != nil {
+		return q.onFailure(ctx, key, quota, status, fmt.Errorf("erro ao consumir cota: %w", err))
+	}
+	if !q.local {
+		recordRecovery(&q.degraded)
+	}
+
+	status.Remaining = quota.Limit - status.Used
+	return status, nil
+}
+
+// consume incrementa o contador do período, retornando os erros do store
+func (q *QuotaManager) consume(ctx context.Context, counterKey string, expiry time.Duration, quota Quota, status *QuotaStatus) error {
+	// Verificação e incremento atômicos: cota esgotada não é incrementada
+	if multiStore, ok := q.store.(MultiAtomicStore); ok {
+		res, err := multiStore.AllowAtomicMulti(ctx, MultiAtomicRequest{
+			BlockKey: counterKey + ":blk",
+			Counters: []AtomicCounter{{Key: counterKey, Limit: quota.Limit, Window: expiry, Description: quota.String()}},
+		})
+		if err != nil {
+			return err
+		}
+		status.Allowed = !res.Blocked
+		status.Used = quota.Limit
+		if len(res.Counts) > 0 {
+			status.Used = min(res.Counts[0], quota.Limit)
+		}
+		return nil
+	}
+
+	count, err := q.store.Increment(ctx, counterKey, expiry)
+	if err != nil {
+		return err
+	}
+	status.Allowed = count <= quota.Limit
+	status.Used = min(count, quota.Limit)
+	return nil
+}
+
+// onFailure aplica a política de falha a um consumo que falhou no store
+func (q *QuotaManager) onFailure(ctx context.Context, key string, quota Quota, status *QuotaStatus, err error) (*QuotaStatus, error) {
+	policy := q.failurePolicy
+	switch policy {
+	case FailClosed:
+		status.Allowed = false
+	case FailLocal:
+		local, localErr := q.fallback.Consume(ctx, key, Quota{Limit: max(quota.Limit*int64(q.localPercent)/100, 1), Period: quota.Period})
+		if localErr == nil {
+			status = local
+			break
+		}
+		policy = FailOpen
+		status.Allowed = true
+	default:
+		status.Allowed = true
+	}
+	status.Failure = policy
+
+	if !q.local {
+		recordFailure(&q.degraded, q.failurePolicy, policy)
+	}
+	return status, err
+}
+
+// Usage retorna o consumo da cota no período atual sem consumi-la
+func (q *QuotaManager) Usage(ctx context.Context, key string, quota Quota) (*QuotaStatus, error) {
+	now := q.now()
+	start, end, err := q.periodBounds(quota.Period, now)
+	if err != nil {
+		return nil, err
+	}
+
+	used, err := q.store.GetCount(ctx, quotaKey(key, quota.Period, start))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consultar cota: %w", err)
+	}
+	used = min(used, quota.Limit)
+
+	return &QuotaStatus{
+		Allowed:   used < quota.Limit,
+		Used:      used,
+		Limit:     quota.Limit,
+		Remaining: quota.Limit - used,
+		ResetAt:   end,
+	}, nil
+}
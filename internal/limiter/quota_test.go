@@ -0,0 +1,150 @@
+package limiter
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestQuotaManager_Consume_Monthly(t *testing.T) {
+	// Setup - UTC-3, último dia do mês às 20h locais
+	location := time.FixedZone("BRT", -3*60*60)
+	clock := &fakeClock{now: time.Date(2025, 1, 31, 20, 0, 0, 0, location)}
+	store := newMemoryStore(clock.Now)
+	defer store.Close()
+
+	quotas := NewQuotaManager(store, location)
+	quotas.now = clock.Now
+	ctx := context.Background()
+	quota := Quota{Limit: 3, Period: QuotaMonthly}
+
+	// Execute
+	for i := int64(1); i <= 3; i++ {
+		status, err := quotas.Consume(ctx, "token:abc", quota)
+		if err != nil {
+			t.Fatalf("Erro inesperado: %v", err)
+		}
+		if !status.Allowed {
+			t.Errorf("Requisição %d deveria ser permitida", i)
+		}
+		if status.Remaining != 3-i {
+			t.Errorf("Remaining esperado %d, obtido %d", 3-i, status.Remaining)
+		}
+	}
+
+	status, _ := quotas.Consume(ctx, "token:abc", quota)
+	if status.Allowed {
+		t.Error("Cota esgotada deveria negar a requisição")
+	}
+
+	// Assert - reinício na virada do mês local (já é fevereiro em UTC)
+	expectedReset := time.Date(2025, 2, 1, 0, 0, 0, 0, location)
+	if !status.ResetAt.Equal(expectedReset) {
+		t.Errorf("ResetAt esperado %v, obtido %v", expectedReset, status.ResetAt)
+	}
+
+	clock.Advance(4 * time.Hour)
+	status, _ = quotas.Consume(ctx, "token:abc", quota)
+	if !status.Allowed || status.Used != 1 {
+		t.Errorf("Novo mês deveria reiniciar a cota, obtido %+v", status)
+	}
+}
+
+func TestQuotaManager_Usage(t *testing.T) {
+	// Setup
+	clock := newFakeClock()
+	store := newMemoryStore(clock.Now)
+	defer store.Close()
+
+	quotas := NewQuotaManager(store, nil)
+	quotas.now = clock.Now
+	ctx := context.Background()
+	quota := Quota{Limit: 10, Period: QuotaDaily}
+
+	quotas.Consume(ctx, "token:abc", quota)
+	quotas.Consume(ctx, "token:abc", quota)
+
+	// Execute - consulta não consome
+	for i := 0; i < 2; i++ {
+		status, err := quotas.Usage(ctx, "token:abc", quota)
+		if err != nil {
+			t.Fatalf("Erro inesperado: %v", err)
+		}
+		if status.Used != 2 || status.Remaining != 8 {
+			t.Errorf("Esperado 2 usados e 8 restantes, obtido %+v", status)
+		}
+	}
+}
+
+func TestQuotaManager_Consume_FailOpen(t *testing.T) {
+	mockStore := NewMockStore()
+	mockStore.SetShouldFail(true)
+	quotas := NewQuotaManager(mockStore, nil)
+
+	status, err := quotas.Consume(context.Background(), "token:abc", Quota{Limit: 1, Period: QuotaMonthly})
+	if err == nil {
+		t.Error("Esperado erro do mock store")
+	}
+	if !status.Allowed {
+		t.Error("Deveria permitir requisição em caso de erro (fail-open)")
+	}
+}
+
+func TestQuotaManager_Consume_FailClosed(t *testing.T) {
+	// Setup
+	mockStore := NewMockStore()
+	mockStore.SetShouldFail(true)
+	quotas := NewQuotaManager(mockStore, nil)
+	if err := quotas.SetFailurePolicy(FailClosed, 0); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	before := failureCount(FailClosed)
+
+	// Execute
+	status, err := quotas.Consume(context.Background(), "token:abc", Quota{Limit: 10, Period: QuotaMonthly})
+
+	// Assert
+	if err == nil {
+		t.Error("Esperado erro do mock store")
+	}
+	if status.Allowed {
+		t.Error("Não deveria permitir requisição em caso de erro (fail-closed)")
+	}
+	if status.Failure != FailClosed {
+		t.Errorf("Esperado Failure %q, obtido %q", FailClosed, status.Failure)
+	}
+	if got := failureCount(FailClosed) - before; got != 1 {
+		t.Errorf("Esperado 1 falha contabilizada como closed, obtido %d", got)
+	}
+}
+
+func TestQuotaManager_Consume_FailLocal(t *testing.T) {
+	// Setup
+	mockStore := NewMockStore()
+	mockStore.SetShouldFail(true)
+	quotas := NewQuotaManager(mockStore, nil)
+	if err := quotas.SetFailurePolicy(FailLocal, 50); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	quota := Quota{Limit: 4, Period: QuotaMonthly}
+
+	// Execute: a cota local é reduzida a 50% (2 requisições)
+	var allowed int
+	for range 3 {
+		status, err := quotas.Consume(context.Background(), "token:abc", quota)
+		if err == nil {
+			t.Error("Esperado erro do mock store")
+		}
+		if status.Failure != FailLocal {
+			t.Errorf("Esperado Failure %q, obtido %q", FailLocal, status.Failure)
+		}
+		if status.Allowed {
+			allowed++
+		}
+	}
+
+	// Assert
+	if allowed != 2 {
+		t.Errorf("Esperado 2 requisições permitidas pela cota local, obtido %d", allowed)
+	}
+}
@@ -2,16 +2,420 @@ package limiter
 
 import (
 	"context"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
+// incrementScript incrementa o contador em ARGV[2] e define a expiração apenas
+// quando a chave ainda não possui TTL, permitindo que a janela expire normalmente
+var incrementScript = redis.NewScript(`
+local count = redis.call('INCRBY', KEYS[1], ARGV[2])
+if redis.call('PTTL', KEYS[1]) < 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return count
+`)
+
+// offenceScript incrementa a contagem de infrações, renova a expiração a cada infração
+// e grava o bloqueio do degrau correspondente
+// KEYS[1]: contagem, KEYS[2]: bloqueio
+// ARGV[1]: período sem infrações (ms), ARGV[2]: valor do bloqueio,
+// ARGV[3]: 1 para gravar apenas se o bloqueio existir, ARGV[4...]: degraus (ms)
+// Retorna {infrações, bloqueio gravado (ms)}
+var offenceScript = redis.NewScript(`
+local offences = redis.call('INCR', KEYS[1])
+redis.call('PEXPIRE', KEYS[1], ARGV[1])
+local step = tonumber(ARGV[3 + math.min(offences, #ARGV - 3)])
+if step <= 0 or (ARGV[3] == '1' and redis.call('EXISTS', KEYS[2]) == 0) then
+	return {offences, 0}
+end
+redis.call('SET', KEYS[2], ARGV[2], 'PX', step)
+return {offences, step}
+`)
+
+// allowScript executa a verificação completa de janela fixa de forma atômica
+// KEYS[1]: contador, KEYS[2]: bloqueio
+// ARGV[1]: limite, ARGV[2]: janela (ms), ARGV[3]: bloqueio (ms), ARGV[4]: custo, ARGV[5]: limite gravado no bloqueio
+// Retorna {contador, bloqueado, retry after (ms), excedido nesta verificação, reset after (ms)}
+var allowScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[2]) == 1 then
+	local block_ttl = math.max(redis.call('PTTL', KEYS[2]), 0)
+	return {0, 1, block_ttl, 0, block_ttl}
+end
+local count = redis.call('INCRBY', KEYS[1], ARGV[4])
+local ttl = redis.call('PTTL', KEYS[1])
+if ttl < 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+	ttl = tonumber(ARGV[2])
+end
+if count > tonumber(ARGV[1]) then
+	local block = tonumber(ARGV[3])
+	if block > 0 then
+		redis.call('SET', KEYS[2], ARGV[5], 'PX', block)
+	end
+	return {count, 1, math.max(ttl, block), 1, ttl}
+end
+return {count, 0, 0, 0, ttl}
+`)
+
+// tokenBucketScript consome um token do bucket de forma atômica
+// O relógio do próprio Redis evita divergência entre réplicas da aplicação
+// KEYS[1]: bucket, KEYS[2]: bloqueio
+// ARGV[1]: capacidade, ARGV[2]: reposição (tokens/s), ARGV[3]: bloqueio (ms), ARGV[4]: custo, ARGV[5]: limite gravado no bloqueio
+// Retorna {tokens restantes, bloqueado, retry after (ms), excedido nesta verificação, reset after (ms)}
+var tokenBucketScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[2]) == 1 then
+	local block_ttl = math.max(redis.call('PTTL', KEYS[2]), 0)
+	return {0, 1, block_ttl, 0, block_ttl}
+end
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local cost = tonumber(ARGV[4])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = capacity
+	ts = now
+end
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * rate / 1000)
+local blocked = 1
+if tokens >= cost then
+	tokens = tokens - cost
+	blocked = 0
+end
+redis.call('HSET', KEYS[1], 'tokens', tokens, 'ts', now)
+local ttl = 86400000
+local reset = 0
+if rate > 0 then
+	reset = math.ceil((capacity - tokens) * 1000 / rate)
+	ttl = reset + 1000
+end
+redis.call('PEXPIRE', KEYS[1], ttl)
+local retry = 0
+if blocked == 1 then
+	if rate > 0 then
+		retry = math.ceil((cost - tokens) * 1000 / rate)
+	end
+	local block = tonumber(ARGV[3])
+	if block > 0 then
+		redis.call('SET', KEYS[2], ARGV[5], 'PX', block)
+		retry = math.max(retry, block)
+	end
+end
+return {math.floor(tokens), blocked, retry, blocked, reset}
+`)
+
+// slidingWindowScript aplica a janela deslizante por contador em um único hash
+// O hash guarda o índice da janela atual e os contadores atual e anterior
+// KEYS[1]: estado, KEYS[2]: bloqueio
+// ARGV[1]: limite, ARGV[2]: janela (ms), ARGV[3]: bloqueio (ms), ARGV[4]: custo, ARGV[5]: limite gravado no bloqueio
+// Retorna {contagem estimada, bloqueado, retry after (ms), excedido nesta verificação, reset after (ms)}
+var slidingWindowScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[2]) == 1 then
+	local block_ttl = math.max(redis.call('PTTL', KEYS[2]), 0)
+	return {0, 1, block_ttl, 0, block_ttl}
+end
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local cost = tonumber(ARGV[4])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local idx = math.floor(now / window)
+local state = redis.call('HMGET', KEYS[1], 'idx', 'cur', 'prev')
+local stored = tonumber(state[1])
+local cur = tonumber(state[2]) or 0
+local prev = tonumber(state[3]) or 0
+if stored == idx - 1 then
+	prev = cur
+	cur = 0
+elseif stored ~= idx then
+	prev = 0
+	cur = 0
+end
+local elapsed = now - idx * window
+local weight = (window - elapsed) / window
+local blocked = 1
+if prev * weight + cur + cost <= limit then
+	cur = cur + cost
+	blocked = 0
+end
+redis.call('HSET', KEYS[1], 'idx', idx, 'cur', cur, 'prev', prev)
+redis.call('PEXPIRE', KEYS[1], window * 2)
+local retry = 0
+if blocked == 1 then
+	-- Instante em que o peso da janela anterior cai o suficiente, ou o fim da janela atual
+	retry = window - elapsed
+	if cur + cost <= limit then
+		retry = math.ceil(window * (1 - (limit - cur - cost) / prev) - elapsed)
+	end
+	local block = tonumber(ARGV[3])
+	if block > 0 then
+		redis.call('SET', KEYS[2], ARGV[5], 'PX', block)
+		retry = math.max(retry, block)
+	end
+end
+-- A contagem zera quando as janelas atual e anterior deixam de pesar
+local reset = 0
+if cur > 0 then
+	reset = 2 * window - elapsed
+elseif prev > 0 then
+	reset = window - elapsed
+end
+return {math.ceil(prev * weight) + cur, blocked, retry, blocked, reset}
+`)
+
+// slidingLogScript aplica a janela deslizante por log em um sorted set
+// KEYS[1]: log, KEYS[2]: bloqueio
+// ARGV[1]: limite, ARGV[2]: janela (ms), ARGV[3]: bloqueio (ms), ARGV[4]: custo,
+// ARGV[5]: limite gravado no bloqueio, ARGV[6]: prefixo único dos membros (um membro por unidade de custo)
+// Retorna {unidades na janela, bloqueado, retry after (ms), excedido nesta verificação, reset after (ms)}
+var slidingLogScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[2]) == 1 then
+	local block_ttl = math.max(redis.call('PTTL', KEYS[2]), 0)
+	return {0, 1, block_ttl, 0, block_ttl}
+end
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local cost = tonumber(ARGV[4])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1])
+local blocked = 1
+if count + cost <= limit then
+	-- Um ZADD com vários membros por bloco (unpack tem limite de argumentos)
+	local members = {}
+	for i = 1, cost do
+		members[#members + 1] = now
+		members[#members + 1] = ARGV[6] .. ':' .. i
+		if #members == 2000 or i == cost then
+			redis.call('ZADD', KEYS[1], unpack(members))
+			members = {}
+		end
+	end
+	count = count + cost
+	blocked = 0
+end
+redis.call('PEXPIRE', KEYS[1], window)
+local retry = 0
+if blocked == 1 then
+	-- Espera até sair da janela o registro que libera espaço para o custo
+	retry = window
+	local excess = count + cost - limit
+	if excess <= count then
+		local entry = redis.call('ZRANGE', KEYS[1], excess - 1, excess - 1, 'WITHSCORES')
+		retry = tonumber(entry[2]) + window - now
+	end
+	local block = tonumber(ARGV[3])
+	if block > 0 then
+		redis.call('SET', KEYS[2], ARGV[5], 'PX', block)
+		retry = math.max(retry, block)
+	end
+end
+-- A janela esvazia quando o registro mais recente sai dela
+local reset = 0
+if count > 0 then
+	local newest = redis.call('ZRANGE', KEYS[1], -1, -1, 'WITHSCORES')
+	reset = tonumber(newest[2]) + window - now
+end
+return {count, blocked, retry, blocked, reset}
+`)
+
+// gcraScript aplica o GCRA guardando apenas o TAT (em µs) da chave
+// KEYS[1]: TAT, KEYS[2]: bloqueio
+// ARGV[1]: intervalo de emissão (µs), ARGV[2]: tolerância (µs), ARGV[3]: bloqueio (ms), ARGV[4]: custo, ARGV[5]: limite gravado no bloqueio
+// Retorna {bloqueado, restantes, retry after (µs), reset after (µs), excedido nesta verificação}
+var gcraScript = redis.NewScript(`
+local block_ttl = redis.call('PTTL', KEYS[2])
+if block_ttl > 0 then
+	return {1, 0, block_ttl * 1000, block_ttl * 1000, 0}
+end
+local interval = tonumber(ARGV[1])
+local tolerance = tonumber(ARGV[2])
+local block = tonumber(ARGV[3])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+local tat = now
+local stored = redis.call('GET', KEYS[1])
+if stored then
+	tat = math.max(tonumber(stored), now)
+end
+local new_tat = tat + interval * tonumber(ARGV[4])
+local allow_at = new_tat - tolerance
+if now < allow_at then
+	if block > 0 then
+		redis.call('SET', KEYS[2], ARGV[5], 'PX', block)
+		return {1, 0, math.max(allow_at - now, block * 1000), math.max(tat - now, block * 1000), 1}
+	end
+	return {1, 0, allow_at - now, tat - now, 1}
+end
+redis.call('SET', KEYS[1], string.format('%d', new_tat), 'PX', math.ceil((new_tat - now) / 1000))
+return {0, math.floor((now - allow_at) / interval), 0, new_tat - now, 0}
+`)
+
+// multiAllowScript verifica várias janelas fixas antes de incrementar qualquer uma
+// KEYS[1]: bloqueio, KEYS[2..n+1]: contadores
+// ARGV[1]: custo; em seguida, para cada contador: limite, janela (ms), bloqueio (ms), descrição
+// Retorna {bloqueado, índice excedido (0 se nenhum), retry after (ms), contagem, reset after (ms), ...}
+// com um par contagem/reset por contador, ou {1, 0, retry after (ms), descrição} se a chave já estava bloqueada
+var multiAllowScript = redis.NewScript(`
+local blocked_by = redis.call('GET', KEYS[1])
+if blocked_by then
+	return {1, 0, math.max(redis.call('PTTL', KEYS[1]), 0), blocked_by}
+end
+local n = #KEYS - 1
+local cost = tonumber(ARGV[1])
+local counts = {}
+local tripped = 0
+for i = 1, n do
+	counts[i] = tonumber(redis.call('GET', KEYS[i + 1]) or '0') + cost
+	if tripped == 0 and counts[i] > tonumber(ARGV[(i - 1) * 4 + 2]) then
+		tripped = i
+	end
+end
+if tripped > 0 then
+	-- Sem contador existente, a janela inteira precisa passar
+	local retry = redis.call('PTTL', KEYS[tripped + 1])
+	if retry < 0 then
+		retry = tonumber(ARGV[(tripped - 1) * 4 + 3])
+	end
+	local block = tonumber(ARGV[(tripped - 1) * 4 + 4])
+	if block > 0 then
+		redis.call('SET', KEYS[1], ARGV[(tripped - 1) * 4 + 5], 'PX', block)
+		retry = math.max(retry, block)
+	end
+	local res = {1, tripped, retry}
+	for i = 1, n do
+		res[#res + 1] = counts[i]
+		res[#res + 1] = math.max(redis.call('PTTL', KEYS[i + 1]), 0)
+	end
+	return res
+end
+local res = {0, 0, 0}
+for i = 1, n do
+	res[#res + 1] = redis.call('INCRBY', KEYS[i + 1], cost)
+	local ttl = redis.call('PTTL', KEYS[i + 1])
+	if ttl < 0 then
+		ttl = tonumber(ARGV[(i - 1) * 4 + 3])
+		redis.call('PEXPIRE', KEYS[i + 1], ttl)
+	end
+	res[#res + 1] = ttl
+end
+return res
+`)
+
+// tokenBucketPeekScript consulta o bucket aplicando a reposição sem alterá-lo
+// KEYS[1]: bucket
+// ARGV[1]: capacidade, ARGV[2]: reposição (tokens/s)
+// Retorna {tokens restantes, reset after (ms)}
+var tokenBucketPeekScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	return {capacity, 0}
+end
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * rate / 1000)
+local reset = 0
+if rate > 0 then
+	reset = math.ceil((capacity - tokens) * 1000 / rate)
+end
+return {math.floor(tokens), reset}
+`)
+
+// slidingWindowPeekScript consulta a contagem estimada da janela deslizante
+// KEYS[1]: estado
+// ARGV[1]: janela (ms)
+// Retorna {contagem estimada, reset after (ms)}
+var slidingWindowPeekScript = redis.NewScript(`
+local window = tonumber(ARGV[1])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local idx = math.floor(now / window)
+local state = redis.call('HMGET', KEYS[1], 'idx', 'cur', 'prev')
+local stored = tonumber(state[1])
+local cur = tonumber(state[2]) or 0
+local prev = tonumber(state[3]) or 0
+if stored == idx - 1 then
+	prev = cur
+	cur = 0
+elseif stored ~= idx then
+	prev = 0
+	cur = 0
+end
+local elapsed = now - idx * window
+local reset = 0
+if cur > 0 then
+	reset = 2 * window - elapsed
+elseif prev > 0 then
+	reset = window - elapsed
+end
+return {math.ceil(prev * (window - elapsed) / window) + cur, reset}
+`)
+
+// slidingLogPeekScript conta os registros dentro da janela sem removê-los
+// KEYS[1]: log
+// ARGV[1]: janela (ms)
+// Retorna {requisições na janela, reset after (ms)}
+var slidingLogPeekScript = redis.NewScript(`
+local window = tonumber(ARGV[1])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local count = redis.call('ZCOUNT', KEYS[1], '(' .. (now - window), '+inf')
+local reset = 0
+if count > 0 then
+	local newest = redis.call('ZRANGE', KEYS[1], -1, -1, 'WITHSCORES')
+	reset = tonumber(newest[2]) + window - now
+end
+return {count, reset}
+`)
+
+// gcraPeekScript calcula quantas requisições o TAT atual ainda permite
+// KEYS[1]: TAT
+// ARGV[1]: intervalo de emissão (µs), ARGV[2]: tolerância (µs)
+// Retorna {restantes, reset after (µs)}
+var gcraPeekScript = redis.NewScript(`
+local interval = tonumber(ARGV[1])
+local tolerance = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+local tat = now
+local stored = redis.call('GET', KEYS[1])
+if stored then
+	tat = math.max(tonumber(stored), now)
+end
+return {math.max(math.floor((now - tat + tolerance) / interval), 0), tat - now}
+`)
+
 // RedisStore implementa LimiterStoreStrategy usando Redis
 type RedisStore struct {
 	client *redis.Client
 }
 
+var (
+	_ AtomicStore        = (*RedisStore)(nil)
+	_ TokenBucketStore   = (*RedisStore)(nil)
+	_ SlidingWindowStore = (*RedisStore)(nil)
+	_ SlidingLogStore    = (*RedisStore)(nil)
+	_ GCRAStore          = (*RedisStore)(nil)
+	_ MultiAtomicStore   = (*RedisStore)(nil)
+	_ OffenceStore       = (*RedisStore)(nil)
+	_ PeekStore          = (*RedisStore)(nil)
+	_ KeyScanner         = (*RedisStore)(nil)
+)
+
 // NewRedisStore cria uma nova instância de RedisStore
 func NewRedisStore(addr string) (*RedisStore, error) {
 	client := redis.NewClient(&redis.Options{
@@ -30,19 +434,141 @@ func NewRedisStore(addr string) (*RedisStore, error) {
 }
 
 // Increment incrementa atomicamente o contador e define expiração
+// A expiração só é definida no primeiro incremento da janela
 func (r *RedisStore) Increment(ctx context.Context, key string, expiry time.Duration) (int64, error) {
-	// Pipeline garante operações atômicas
-	pipe := r.client.Pipeline()
-	
-	incrCmd := pipe.Incr(ctx, key)
-	pipe.Expire(ctx, key, expiry)
-	
-	_, err := pipe.Exec(ctx)
+	return r.IncrementBy(ctx, key, 1, expiry)
+}
+
+// IncrementBy incrementa atomicamente o contador em n e define expiração
+// A expiração só é definida no primeiro incremento da janela
+func (r *RedisStore) IncrementBy(ctx context.Context, key string, n int64, expiry time.Duration) (int64, error) {
+	// Script.Run usa EVALSHA e recorre a EVAL quando o script não está em cache
+	return incrementScript.Run(ctx, r.client, []string{key}, expiry.Milliseconds(), n).Int64()
+}
+
+// Escalate registra a infração e grava o bloqueio escalonado em um único script
+func (r *RedisStore) Escalate(ctx context.Context, req OffenceRequest) (OffenceResult, error) {
+	keepUnblocked := 0
+	if req.KeepUnblocked {
+		keepUnblocked = 1
+	}
+	args := []interface{}{req.Lookback.Milliseconds(), blockValue(req.BlockReason), keepUnblocked}
+	for _, step := range req.Steps {
+		args = append(args, step.Milliseconds())
+	}
+	vals, err := offenceScript.Run(ctx, r.client, []string{req.OffenceKey, req.BlockKey}, args...).Int64Slice()
 	if err != nil {
-		return 0, err
+		return OffenceResult{}, err
+	}
+	return OffenceResult{Offences: vals[0], BlockDuration: time.Duration(vals[1]) * time.Millisecond}, nil
+}
+
+// AllowAtomic executa verificação de bloqueio, incremento e bloqueio em um único script
+func (r *RedisStore) AllowAtomic(ctx context.Context, req AtomicRequest) (AtomicResult, error) {
+	vals, err := allowScript.Run(ctx, r.client,
+		[]string{req.CounterKey, req.BlockKey},
+		req.Limit, req.Window.Milliseconds(), req.BlockDuration.Milliseconds(), costOf(req.Cost), blockValue(req.BlockReason),
+	).Int64Slice()
+	if err != nil {
+		return AtomicResult{}, err
+	}
+
+	return AtomicResult{Count: vals[0], Blocked: vals[1] == 1, Tripped: vals[3] == 1, RetryAfter: time.Duration(vals[2]) * time.Millisecond, ResetAfter: time.Duration(vals[4]) * time.Millisecond}, nil
+}
+
+// TakeToken consome um token do bucket em um único script
+func (r *RedisStore) TakeToken(ctx context.Context, req TokenBucketRequest) (TokenBucketResult, error) {
+	vals, err := tokenBucketScript.Run(ctx, r.client,
+		[]string{req.BucketKey, req.BlockKey},
+		req.Capacity, req.RefillRate, req.BlockDuration.Milliseconds(), costOf(req.Cost), blockValue(req.BlockReason),
+	).Int64Slice()
+	if err != nil {
+		return TokenBucketResult{}, err
+	}
+
+	return TokenBucketResult{Remaining: vals[0], Blocked: vals[1] == 1, Tripped: vals[3] == 1, RetryAfter: time.Duration(vals[2]) * time.Millisecond, ResetAfter: time.Duration(vals[4]) * time.Millisecond}, nil
+}
+
+// AllowAtomicMulti verifica e incrementa várias janelas fixas em um único script
+func (r *RedisStore) AllowAtomicMulti(ctx context.Context, req MultiAtomicRequest) (MultiAtomicResult, error) {
+	keys := make([]string, 0, len(req.Counters)+1)
+	args := make([]interface{}, 0, len(req.Counters)*4+1)
+	keys = append(keys, req.BlockKey)
+	args = append(args, costOf(req.Cost))
+	for _, c := range req.Counters {
+		keys = append(keys, c.Key)
+		args = append(args, c.Limit, c.Window.Milliseconds(), c.BlockDuration.Milliseconds(), c.Description)
+	}
+
+	vals, err := multiAllowScript.Run(ctx, r.client, keys, args...).Slice()
+	if err != nil {
+		return MultiAtomicResult{}, err
+	}
+	if len(vals) < 3 {
+		return MultiAtomicResult{}, fmt.Errorf("resposta inesperada do script: %v", vals)
+	}
+
+	res := MultiAtomicResult{
+		Blocked:    vals[0].(int64) == 1,
+		Tripped:    int(vals[1].(int64)) - 1,
+		RetryAfter: time.Duration(vals[2].(int64)) * time.Millisecond,
+	}
+	for i, v := range vals[3:] {
+		switch v := v.(type) {
+		case int64:
+			// Pares contagem/reset por contador
+			if i%2 == 0 {
+				res.Counts = append(res.Counts, v)
+			} else {
+				res.Resets = append(res.Resets, time.Duration(v)*time.Millisecond)
+			}
+		case string:
+			res.BlockedBy = v
+		}
+	}
+	return res, nil
+}
+
+// AllowSlidingWindow aplica a janela deslizante por contador em um único script
+func (r *RedisStore) AllowSlidingWindow(ctx context.Context, req AtomicRequest) (AtomicResult, error) {
+	return r.runWindowScript(ctx, slidingWindowScript, req)
+}
+
+// AllowSlidingLog aplica a janela deslizante por log em um único script
+func (r *RedisStore) AllowSlidingLog(ctx context.Context, req AtomicRequest) (AtomicResult, error) {
+	// Prefixo aleatório evita colisão entre requisições no mesmo milissegundo
+	member := strconv.FormatUint(rand.Uint64(), 36)
+	return r.runWindowScript(ctx, slidingLogScript, req, member)
+}
+
+// runWindowScript executa um script de janela com os argumentos comuns
+func (r *RedisStore) runWindowScript(ctx context.Context, script *redis.Script, req AtomicRequest, extra ...interface{}) (AtomicResult, error) {
+	args := append([]interface{}{req.Limit, req.Window.Milliseconds(), req.BlockDuration.Milliseconds(), costOf(req.Cost), blockValue(req.BlockReason)}, extra...)
+	vals, err := script.Run(ctx, r.client, []string{req.CounterKey, req.BlockKey}, args...).Int64Slice()
+	if err != nil {
+		return AtomicResult{}, err
+	}
+
+	return AtomicResult{Count: vals[0], Blocked: vals[1] == 1, Tripped: vals[3] == 1, RetryAfter: time.Duration(vals[2]) * time.Millisecond, ResetAfter: time.Duration(vals[4]) * time.Millisecond}, nil
+}
+
+// AllowGCRA aplica o GCRA em um único script
+func (r *RedisStore) AllowGCRA(ctx context.Context, req GCRARequest) (GCRAResult, error) {
+	vals, err := gcraScript.Run(ctx, r.client,
+		[]string{req.Key, req.BlockKey},
+		req.EmissionInterval.Microseconds(), req.Tolerance.Microseconds(), req.BlockDuration.Milliseconds(), costOf(req.Cost), blockValue(req.BlockReason),
+	).Int64Slice()
+	if err != nil {
+		return GCRAResult{}, err
 	}
 
-	return incrCmd.Val(), nil
+	return GCRAResult{
+		Blocked:    vals[0] == 1,
+		Tripped:    vals[4] == 1,
+		Remaining:  vals[1],
+		RetryAfter: time.Duration(vals[2]) * time.Microsecond,
+		ResetAfter: time.Duration(vals[3]) * time.Microsecond,
+	}, nil
 }
 
 // GetCount retorna o contador atual
@@ -68,6 +594,132 @@ func (r *RedisStore) SetExpiring(ctx context.Context, key string, value string,
 	return r.client.Set(ctx, key, value, expiry).Err()
 }
 
+// TTL retorna o tempo restante até a chave expirar (zero se não existe ou não expira)
+func (r *RedisStore) TTL(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := r.client.PTTL(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	// PTTL retorna -2 para chave inexistente e -1 para chave sem expiração
+	if ttl < 0 {
+		return 0, nil
+	}
+	return ttl, nil
+}
+
+// ScanKeys percorre as chaves com o prefixo via SCAN, sem bloquear o Redis como KEYS
+// Valor e TTL de cada chave são lidos em um único pipeline
+func (r *RedisStore) ScanKeys(ctx context.Context, prefix string, cursor string, count int64) ([]ScannedKey, string, error) {
+	var start uint64
+	if cursor != "" {
+		var err error
+		if start, err = strconv.ParseUint(cursor, 10, 64); err != nil {
+			return nil, "", fmt.Errorf("%w: %s", ErrInvalidCursor, cursor)
+		}
+	}
+
+	keys, next, err := r.client.Scan(ctx, start, escapeGlob(prefix)+"*", count).Result()
+	if err != nil {
+		return nil, "", err
+	}
+	nextCursor := ""
+	if next != 0 {
+		nextCursor = strconv.FormatUint(next, 10)
+	}
+	if len(keys) == 0 {
+		return nil, nextCursor, nil
+	}
+
+	pipe := r.client.Pipeline()
+	values := make([]*redis.StringCmd, len(keys))
+	ttls := make([]*redis.DurationCmd, len(keys))
+	for i, key := range keys {
+		values[i] = pipe.Get(ctx, key)
+		ttls[i] = pipe.PTTL(ctx, key)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, "", err
+	}
+
+	scanned := make([]ScannedKey, 0, len(keys))
+	for i, key := range keys {
+		// Chaves que expiraram entre o SCAN e a leitura são ignoradas
+		value, err := values[i].Result()
+		if err != nil {
+			continue
+		}
+		scanned = append(scanned, ScannedKey{Key: key, Value: value, TTL: max(ttls[i].Val(), 0)})
+	}
+	return scanned, nextCursor, nil
+}
+
+// escapeGlob escapa os caracteres especiais do padrão MATCH do Redis
+func escapeGlob(s string) string {
+	var b strings.Builder
+	for _, c := range s {
+		if strings.ContainsRune(`*?[]\`, c) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// PeekTokenBucket consulta o bucket sem consumir tokens
+func (r *RedisStore) PeekTokenBucket(ctx context.Context, req TokenBucketRequest) (PeekResult, error) {
+	vals, err := tokenBucketPeekScript.Run(ctx, r.client, []string{req.BucketKey}, req.Capacity, req.RefillRate).Int64Slice()
+	if err != nil {
+		return PeekResult{}, err
+	}
+	return PeekResult{Count: req.Capacity - vals[0], Remaining: vals[0], ResetAfter: time.Duration(vals[1]) * time.Millisecond}, nil
+}
+
+// PeekSlidingWindow consulta a janela deslizante por contador sem incrementá-la
+func (r *RedisStore) PeekSlidingWindow(ctx context.Context, req AtomicRequest) (PeekResult, error) {
+	return r.peekWindowScript(ctx, slidingWindowPeekScript, req)
+}
+
+// PeekSlidingLog consulta o log deslizante sem registrar requisições
+func (r *RedisStore) PeekSlidingLog(ctx context.Context, req AtomicRequest) (PeekResult, error) {
+	return r.peekWindowScript(ctx, slidingLogPeekScript, req)
+}
+
+// peekWindowScript executa um script de consulta de janela
+func (r *RedisStore) peekWindowScript(ctx context.Context, script *redis.Script, req AtomicRequest) (PeekResult, error) {
+	vals, err := script.Run(ctx, r.client, []string{req.CounterKey}, req.Window.Milliseconds()).Int64Slice()
+	if err != nil {
+		return PeekResult{}, err
+	}
+	return PeekResult{Count: vals[0], Remaining: max(req.Limit-vals[0], 0), ResetAfter: time.Duration(vals[1]) * time.Millisecond}, nil
+}
+
+// PeekGCRA consulta o TAT sem avançá-lo
+func (r *RedisStore) PeekGCRA(ctx context.Context, req GCRARequest) (PeekResult, error) {
+	vals, err := gcraPeekScript.Run(ctx, r.client,
+		[]string{req.Key},
+		req.EmissionInterval.Microseconds(), req.Tolerance.Microseconds(),
+	).Int64Slice()
+	if err != nil {
+		return PeekResult{}, err
+	}
+
+	burst := int64(req.Tolerance / req.EmissionInterval)
+	return PeekResult{Count: max(burst-vals[0], 0), Remaining: vals[0], ResetAfter: time.Duration(vals[1]) * time.Microsecond}, nil
+}
+
+// Delete remove as chaves e retorna quantas existiam
+func (r *RedisStore) Delete(ctx context.Context, keys ...string) (int64, error) {
+	if len(keys) == 0 {
+		return 0, nil
+	}
+	return r.client.Del(ctx, keys...).Result()
+}
+
+// Client retorna o cliente Redis, compartilhado com outros componentes (ex.: registro de tokens)
+func (r *RedisStore) Client() *redis.Client {
+	return r.client
+}
+
 // Close fecha a conexão com o Redis
 func (r *RedisStore) Close() error {
 	return r.client.Close()
@@ -0,0 +1,101 @@
+package limiter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrCostExceedsLimit indica que o custo solicitado nunca cabe na capacidade de uma regra
+var ErrCostExceedsLimit = errors.New("custo excede a capacidade do limite")
+
+// Reservation é o resultado de uma tentativa de reservar capacidade
+type Reservation struct {
+	// OK indica que a capacidade foi consumida e o chamador pode prosseguir
+	OK bool
+	// Delay é o tempo até uma nova tentativa ter capacidade disponível (zero quando OK)
+	Delay time.Duration
+	// Status é o resultado da verificação que originou a reserva
+	Status *BlockStatus
+}
+
+// Reserve tenta consumir n unidades dos limites da chave sem penalidade: uma
+// tentativa negada nunca cria bloqueio nem conta como infração do escalonamento,
+// e não consome os limites que ainda tinham capacidade (atomicamente com janelas
+// fixas em um store MultiAtomicStore; nos demais casos, os limites são consultados
+// antes do consumo, como em AllowRules). Quando negada, Delay informa quanto
+// esperar antes de tentar novamente. Destina-se a clientes internos (ex.: workers
+// em lote) que preferem aguardar a receber 429 do orçamento compartilhado.
+// Em caso de erro do store a reserva é concedida (fail-open) e o erro é retornado.
+func (c *CoreLimiter) Reserve(ctx context.Context, key string, rules []Rule, n int) (*Reservation, error) {
+	n = int(costOf(int64(n)))
+
+	normalized := make([]Rule, len(rules))
+	allFixed := true
+	for i, rule := range rules {
+		rule = rule.withDefaults()
+		rule.BlockDuration = 0
+		rule.Escalation = Escalation{}
+		normalized[i] = rule
+		allFixed = allFixed && rule.Algorithm == AlgorithmFixedWindow
+	}
+
+	if len(normalized) == 0 {
+		return &Reservation{OK: true, Status: &BlockStatus{Allowed: true}}, nil
+	}
+	if _, err := costExceeded(normalized, n); err != nil {
+		return nil, err
+	}
+
+	// Com janelas fixas em um MultiAtomicStore, verifica antes de incrementar:
+	// a tentativa negada não consome as janelas
+	status, err := c.allowRules(ctx, key, normalized, allFixed, n)
+	if err != nil {
+		// A política de falha do limiter não se aplica às reservas
+		return &Reservation{OK: true, Status: failOpen(normalized[0])}, err
+	}
+
+	res := &Reservation{OK: status.Allowed, Status: status}
+	if !status.Allowed {
+		res.Delay = status.RetryAfter
+		if res.Delay <= 0 {
+			// Espera desconhecida: tenta novamente após o intervalo entre requisições da regra
+			res.Delay = status.Rule.Window / time.Duration(max(status.Rule.Limit, 1))
+		}
+	}
+	return res, nil
+}
+
+// Wait bloqueia até consumir n unidades dos limites da chave, respeitando o contexto
+// Retorna ErrCostExceedsLimit se o custo nunca couber nos limites, ctx.Err() se o
+// contexto terminar durante a espera, ou um erro context.DeadlineExceeded imediato
+// quando o prazo do contexto termina antes da próxima capacidade disponível.
+// Falhas do store não bloqueiam o chamador (fail-open).
+func (c *CoreLimiter) Wait(ctx context.Context, key string, rules []Rule, n int) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		res, err := c.Reserve(ctx, key, rules, n)
+		if res == nil {
+			return err
+		}
+		if res.OK {
+			return nil
+		}
+
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < res.Delay {
+			return fmt.Errorf("%w: capacidade disponível em %v", context.DeadlineExceeded, res.Delay)
+		}
+
+		timer := time.NewTimer(res.Delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package limiter
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCoreLimiter_Reserve_NoPenalty(t *testing.T) {
+	// Setup
+	clock := newFakeClock()
+	limiter := NewCoreLimiter(newMemoryStore(clock.Now))
+	defer limiter.Close()
+	ctx := context.Background()
+	rules := []Rule{{Limit: 2, Window: time.Second, BlockDuration: time.Minute}}
+
+	// Execute - consome o limite
+	for i := 0; i < 2; i++ {
+		res, err := limiter.Reserve(ctx, "test:worker:export", rules, 1)
+		if err != nil {
+			t.Fatalf("Erro inesperado: %v", err)
+		}
+		if !res.OK {
+			t.Errorf("Reserva %d deveria ser concedida", i+1)
+		}
+	}
+
+	clock.Advance(250 * time.Millisecond)
+	res, _ := limiter.Reserve(ctx, "test:worker:export", rules, 1)
+
+	// Assert - negada com a espera até o fim da janela
+	if res.OK {
+		t.Fatal("Reserva deveria ser negada com o limite consumido")
+	}
+	if res.Delay != 750*time.Millisecond {
+		t.Errorf("Delay esperado 750ms, obtido %v", res.Delay)
+	}
+
+	// Sem bloqueio de 1 minuto: na janela seguinte a reserva é concedida
+	clock.Advance(res.Delay)
+	if res, _ = limiter.Reserve(ctx, "test:worker:export", rules, 1); !res.OK {
+		t.Error("Reserva deveria ser concedida após o Delay informado")
+	}
+}
+
+func TestCoreLimiter_Reserve_NoEscalation(t *testing.T) {
+	// Setup
+	clock := newFakeClock()
+	store := newMemoryStore(clock.Now)
+	limiter := NewCoreLimiter(store)
+	defer limiter.Close()
+	ctx := context.Background()
+	escalation := Escalation{Steps: []time.Duration{time.Minute, time.Hour}, Lookback: time.Hour}
+	rules := []Rule{{Limit: 1, Window: time.Second, BlockDuration: 10 * time.Second, Escalation: escalation}}
+
+	// Execute - a segunda reserva é negada
+	limiter.Reserve(ctx, "test:worker:escalation", rules, 1)
+	res, _ := limiter.Reserve(ctx, "test:worker:escalation", rules, 1)
+
+	// Assert - sem infração registrada nem bloqueio
+	if res.OK {
+		t.Fatal("Reserva deveria ser negada com o limite consumido")
+	}
+	if offences, _ := limiter.Offences(ctx, "test:worker:escalation"); offences != 0 {
+		t.Errorf("Reserva negada não deveria contar como infração, obtido %d", offences)
+	}
+	if ttl, _ := store.TTL(ctx, blockKeyFor("test:worker:escalation")); ttl > 0 {
+		t.Errorf("Reserva negada não deveria criar bloqueio, TTL %v", ttl)
+	}
+	if res.Delay != time.Second {
+		t.Errorf("Delay esperado 1s, obtido %v", res.Delay)
+	}
+}
+
+func TestCoreLimiter_Reserve_DeniedDoesNotConsume(t *testing.T) {
+	// Setup
+	clock := newFakeClock()
+	limiter := NewCoreLimiter(newMemoryStore(clock.Now))
+	defer limiter.Close()
+	ctx := context.Background()
+	rules := []Rule{{Limit: 10, Window: time.Second}}
+
+	// Execute - custo 6, depois 6 negado e 4 ainda cabe
+	limiter.Reserve(ctx, "test:worker:batch", rules, 6)
+	if res, _ := limiter.Reserve(ctx, "test:worker:batch", rules, 6); res.OK {
+		t.Error("Reserva de custo 6 deveria ser negada")
+	}
+
+	// Assert
+	if res, _ := limiter.Reserve(ctx, "test:worker:batch", rules, 4); !res.OK {
+		t.Error("Reserva negada não deveria consumir capacidade")
+	}
+}
+
+func TestCoreLimiter_Reserve_CostExceedsLimit(t *testing.T) {
+	// Setup
+	limiter := NewCoreLimiter(NewMemoryStore())
+	defer limiter.Close()
+
+	// Execute
+	_, err := limiter.Reserve(context.Background(), "test:worker:big", []Rule{{Limit: 10}}, 11)
+
+	// Assert
+	if !errors.Is(err, ErrCostExceedsLimit) {
+		t.Errorf("Esperado ErrCostExceedsLimit, obtido %v", err)
+	}
+}
+
+func TestCoreLimiter_Wait(t *testing.T) {
+	// Setup
+	limiter := NewCoreLimiter(NewMemoryStore())
+	defer limiter.Close()
+	ctx := context.Background()
+	rules := []Rule{{Algorithm: AlgorithmGCRA, Limit: 1, Window: 50 * time.Millisecond, Burst: 1}}
+
+	// Execute
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := limiter.Wait(ctx, "test:worker:wait", rules, 1); err != nil {
+			t.Fatalf("Erro inesperado: %v", err)
+		}
+	}
+	elapsed := time.Since(start)
+
+	// Assert - a 2ª e a 3ª esperam um intervalo cada
+	if elapsed < 90*time.Millisecond {
+		t.Errorf("Wait deveria aguardar a capacidade, decorrido %v", elapsed)
+	}
+}
+
+func TestCoreLimiter_Wait_TokenBucketTiers(t *testing.T) {
+	// Setup - o primeiro limite praticamente não recarrega; o segundo libera 1 a cada 100ms
+	limiter := NewCoreLimiter(NewMemoryStore())
+	defer limiter.Close()
+	rules := []Rule{
+		{Algorithm: AlgorithmTokenBucket, Limit: 3, Window: time.Hour},
+		{Algorithm: AlgorithmTokenBucket, Limit: 1, Window: 100 * time.Millisecond},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	// Execute - as esperas pelo segundo limite não consomem o primeiro
+	for i := 0; i < 3; i++ {
+		if err := limiter.Wait(ctx, "test:worker:tiers", rules, 1); err != nil {
+			t.Fatalf("Wait %d: erro inesperado: %v", i+1, err)
+		}
+	}
+
+	// Assert
+	first, _ := limiter.Status(context.Background(), "test:worker:tiers", rules[0])
+	if first.Remaining != 0 || first.Count != 3 {
+		t.Errorf("O primeiro limite deveria ser consumido apenas pelas 3 reservas concedidas: %+v", first)
+	}
+}
+
+func TestCoreLimiter_Wait_ContextDeadline(t *testing.T) {
+	// Setup
+	limiter := NewCoreLimiter(NewMemoryStore())
+	defer limiter.Close()
+	rules := []Rule{{Limit: 1, Window: time.Hour}}
+	limiter.Wait(context.Background(), "test:worker:deadline", rules, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// Execute - a capacidade só volta em 1h
+	start := time.Now()
+	err := limiter.Wait(ctx, "test:worker:deadline", rules, 1)
+
+	// Assert - retorna imediatamente sem esperar o prazo
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Esperado context.DeadlineExceeded, obtido %v", err)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Errorf("Wait deveria retornar antes do prazo, decorrido %v", time.Since(start))
+	}
+}
+
+func TestCoreLimiter_Wait_ContextCanceled(t *testing.T) {
+	// Setup
+	limiter := NewCoreLimiter(NewMemoryStore())
+	defer limiter.Close()
+	rules := []Rule{{Limit: 1, Window: time.Hour}}
+	limiter.Wait(context.Background(), "test:worker:cancel", rules, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	// Execute
+	err := limiter.Wait(ctx, "test:worker:cancel", rules, 1)
+
+	// Assert
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Esperado context.Canceled, obtido %v", err)
+	}
+}
@@ -0,0 +1,160 @@
+package limiter
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// LimitStatus é o estado de uma chave em uma regra, consultado sem consumir capacidade
+type LimitStatus struct {
+	Rule Rule
+	// Count é o uso atual da regra
+	Count int64
+	// Remaining é o número de requisições ainda permitidas na regra
+	Remaining int64
+	// ResetAt é o instante em que a regra volta à capacidade total (zero se já está)
+	ResetAt time.Time
+	// Blocked indica que a chave está bloqueada, independentemente do uso da regra
+	Blocked bool
+	// BlockedUntil é o fim do bloqueio (zero se não bloqueada ou sem expiração)
+	BlockedUntil time.Time
+}
+
+// LimitInspector é implementado por algoritmos capazes de consultar o estado de
+// uma chave sem consumir capacidade. Todos os algoritmos embutidos o implementam.
+type LimitInspector interface {
+	Status(ctx context.Context, key string, rule Rule) (*LimitStatus, error)
+}
+
+// Status consulta o estado da chave na regra sem consumir capacidade nem criar bloqueio
+// Diferente de Allow, erros do store são retornados sem fail-open
+func (c *CoreLimiter) Status(ctx context.Context, key string, rule Rule) (*LimitStatus, error) {
+	rule = rule.withDefaults()
+
+	algorithm, ok := c.algorithms[rule.Algorithm]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, rule.Algorithm)
+	}
+	inspector, ok := algorithm.(LimitInspector)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s não permite consulta", ErrUnsupportedAlgorithm, rule.Algorithm)
+	}
+
+	status, err := inspector.Status(ctx, key, rule)
+	if err != nil {
+		return nil, err
+	}
+	status.Rule = rule
+
+	// Bloqueio compartilhado por todas as regras da chave
+	blockKey := blockKeyFor(key)
+	blocked, err := c.store.Exists(ctx, blockKey)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao verificar bloqueio: %w", err)
+	}
+	if blocked {
+		ttl, err := c.store.TTL(ctx, blockKey)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao consultar bloqueio: %w", err)
+		}
+		status.Blocked = true
+		if ttl > 0 {
+			status.BlockedUntil = time.Now().Add(ttl)
+		}
+	}
+	return status, nil
+}
+
+// StatusRules consulta o estado da chave em cada uma das regras
+func (c *CoreLimiter) StatusRules(ctx context.Context, key string, rules []Rule) ([]*LimitStatus, error) {
+	statuses := make([]*LimitStatus, 0, len(rules))
+	for _, rule := range rules {
+		status, err := c.Status(ctx, key, rule)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// peekStatus converte o resultado de uma consulta do store em LimitStatus
+func peekStatus(res PeekResult) *LimitStatus {
+	status := &LimitStatus{Count: res.Count, Remaining: res.Remaining}
+	if res.ResetAfter > 0 {
+		status.ResetAt = time.Now().Add(res.ResetAfter)
+	}
+	return status
+}
+
+func (f *fixedWindow) Status(ctx context.Context, key string, rule Rule) (*LimitStatus, error) {
+	counterKey := stateKey("rl:cnt:", key, rule)
+
+	count, err := f.store.GetCount(ctx, counterKey)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consultar contador: %w", err)
+	}
+	ttl, err := f.store.TTL(ctx, counterKey)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consultar janela: %w", err)
+	}
+
+	return peekStatus(PeekResult{Count: count, Remaining: max(int64(rule.Limit)-count, 0), ResetAfter: ttl}), nil
+}
+
+func (t *tokenBucket) Status(ctx context.Context, key string, rule Rule) (*LimitStatus, error) {
+	peekStore, ok := t.store.(PeekStore)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, AlgorithmTokenBucket)
+	}
+
+	res, err := peekStore.PeekTokenBucket(ctx, tokenBucketRequest(key, rule, 0))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consultar bucket: %w", err)
+	}
+	return peekStatus(res), nil
+}
+
+func (s *slidingWindow) Status(ctx context.Context, key string, rule Rule) (*LimitStatus, error) {
+	peekStore, ok := s.store.(PeekStore)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, AlgorithmSlidingWindow)
+	}
+
+	res, err := peekStore.PeekSlidingWindow(ctx, windowRequest("rl:sw:", key, rule, 0))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consultar janela deslizante: %w", err)
+	}
+	return peekStatus(res), nil
+}
+
+func (s *slidingLog) Status(ctx context.Context, key string, rule Rule) (*LimitStatus, error) {
+	peekStore, ok := s.store.(PeekStore)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, AlgorithmSlidingLog)
+	}
+
+	res, err := peekStore.PeekSlidingLog(ctx, windowRequest("rl:sl:", key, rule, 0))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consultar log deslizante: %w", err)
+	}
+	return peekStatus(res), nil
+}
+
+func (g *gcra) Status(ctx context.Context, key string, rule Rule) (*LimitStatus, error) {
+	peekStore, ok := g.store.(PeekStore)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, AlgorithmGCRA)
+	}
+	if rule.Limit <= 0 {
+		return &LimitStatus{}, nil
+	}
+
+	req, _ := gcraRequest(key, rule, 0)
+	res, err := peekStore.PeekGCRA(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consultar GCRA: %w", err)
+	}
+	return peekStatus(res), nil
+}
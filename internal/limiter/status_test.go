@@ -0,0 +1,121 @@
+package limiter
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCoreLimiter_Status_DoesNotConsume(t *testing.T) {
+	algorithms := []Algorithm{
+		AlgorithmFixedWindow,
+		AlgorithmTokenBucket,
+		AlgorithmSlidingWindow,
+		AlgorithmSlidingLog,
+		AlgorithmGCRA,
+	}
+
+	for _, algorithm := range algorithms {
+		t.Run(string(algorithm), func(t *testing.T) {
+			// Setup
+			clock := newFakeClock()
+			limiter := NewCoreLimiter(newMemoryStore(clock.Now))
+			defer limiter.Close()
+			ctx := context.Background()
+			rule := Rule{Algorithm: algorithm, Limit: 10, Window: time.Minute}
+
+			for i := 0; i < 3; i++ {
+				limiter.AllowRule(ctx, "test:ip:status", rule)
+			}
+
+			// Execute - consultas repetidas não alteram o estado
+			var status *LimitStatus
+			for i := 0; i < 3; i++ {
+				var err error
+				status, err = limiter.Status(ctx, "test:ip:status", rule)
+				if err != nil {
+					t.Fatalf("Erro inesperado: %v", err)
+				}
+			}
+
+			// Assert
+			if status.Count != 3 {
+				t.Errorf("Count esperado 3, obtido %d", status.Count)
+			}
+			if status.Remaining != 7 {
+				t.Errorf("Remaining esperado 7, obtido %d", status.Remaining)
+			}
+			if status.ResetAt.IsZero() {
+				t.Error("ResetAt deveria estar definido com uso na janela")
+			}
+			if status.Blocked {
+				t.Error("Chave não deveria estar bloqueada")
+			}
+
+			allowed, _ := limiter.AllowRule(ctx, "test:ip:status", rule)
+			if allowed.CurrentCount != 4 {
+				t.Errorf("Próxima requisição deveria ser a 4ª, CurrentCount %d", allowed.CurrentCount)
+			}
+		})
+	}
+}
+
+func TestCoreLimiter_Status_Unused(t *testing.T) {
+	// Setup
+	limiter := NewCoreLimiter(NewMemoryStore())
+	defer limiter.Close()
+
+	// Execute
+	status, err := limiter.Status(context.Background(), "test:ip:unused", Rule{Limit: 5})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if status.Count != 0 || status.Remaining != 5 {
+		t.Errorf("Esperado 0 usado e 5 restantes, obtido %d e %d", status.Count, status.Remaining)
+	}
+	if !status.ResetAt.IsZero() {
+		t.Errorf("ResetAt deveria ser zero sem uso, obtido %v", status.ResetAt)
+	}
+}
+
+func TestCoreLimiter_Status_Blocked(t *testing.T) {
+	// Setup
+	limiter := NewCoreLimiter(NewMemoryStore())
+	defer limiter.Close()
+	ctx := context.Background()
+	rule := Rule{Limit: 1, BlockDuration: time.Minute}
+
+	limiter.AllowRule(ctx, "test:ip:blocked", rule)
+	limiter.AllowRule(ctx, "test:ip:blocked", rule)
+
+	// Execute
+	status, err := limiter.Status(ctx, "test:ip:blocked", rule)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if !status.Blocked {
+		t.Fatal("Chave deveria estar bloqueada")
+	}
+	if remaining := time.Until(status.BlockedUntil); remaining < 59*time.Second || remaining > time.Minute {
+		t.Errorf("Bloqueio deveria expirar em ~1m, obtido %v", remaining)
+	}
+}
+
+func TestCoreLimiter_Status_StoreError(t *testing.T) {
+	// Setup
+	mockStore := NewMockStore()
+	mockStore.SetShouldFail(true)
+	limiter := NewCoreLimiter(mockStore)
+
+	// Execute - consulta não aplica fail-open
+	_, err := limiter.Status(context.Background(), "test:ip:error", Rule{Limit: 5})
+
+	// Assert
+	if err == nil {
+		t.Error("Esperado erro do store")
+	}
+}
@@ -2,6 +2,7 @@ package limiter
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
@@ -12,6 +13,10 @@ type LimiterStoreStrategy interface {
 	// Retorna o valor atual após incremento
 	Increment(ctx context.Context, key string, expiry time.Duration) (int64, error)
 
+	// IncrementBy incrementa o contador em n e define expiração como Increment
+	// Retorna o valor atual após incremento
+	IncrementBy(ctx context.Context, key string, n int64, expiry time.Duration) (int64, error)
+
 	// GetCount retorna o contador atual para a chave
 	GetCount(ctx context.Context, key string) (int64, error)
 
@@ -21,6 +26,260 @@ type LimiterStoreStrategy interface {
 	// SetExpiring seta um valor com expiração
 	SetExpiring(ctx context.Context, key string, value string, expiry time.Duration) error
 
+	// TTL retorna o tempo restante até a chave expirar
+	// Retorna zero se a chave não existe ou não expira
+	TTL(ctx context.Context, key string) (time.Duration, error)
+
+	// Delete remove as chaves (contadores, buckets, bloqueios)
+	// Retorna quantas existiam
+	Delete(ctx context.Context, keys ...string) (int64, error)
+
 	// Close fecha a conexão com o backend
 	Close() error
 }
+
+// AtomicStore é uma capacidade opcional do store.
+// Executa em uma única operação atômica a verificação de bloqueio, o incremento
+// do contador, a expiração da janela e a criação do bloqueio.
+// O CoreLimiter usa essa capacidade quando o store a implementa.
+type AtomicStore interface {
+	AllowAtomic(ctx context.Context, req AtomicRequest) (AtomicResult, error)
+}
+
+// AtomicRequest descreve uma verificação atômica de janela fixa
+type AtomicRequest struct {
+	CounterKey    string
+	BlockKey      string
+	Limit         int64
+	Window        time.Duration
+	BlockDuration time.Duration
+	// Cost é o número de unidades consumidas pela requisição (zero: 1)
+	Cost int64
+	// BlockReason é o limite gravado no bloqueio (ex.: 5/1s; vazio: "1")
+	BlockReason string
+}
+
+// AtomicResult é o resultado de uma verificação atômica
+type AtomicResult struct {
+	// Count é o valor do contador após o incremento (0 se já estava bloqueado)
+	Count int64
+	// Blocked indica que a requisição foi negada
+	Blocked bool
+	// Tripped indica que esta verificação excedeu o limite (false se a chave já estava bloqueada)
+	Tripped bool
+	// RetryAfter é o tempo até haver capacidade para a requisição (zero se permitida)
+	RetryAfter time.Duration
+	// ResetAfter é o tempo até a chave voltar à capacidade total (o bloqueio restante
+	// se já estava bloqueada)
+	ResetAfter time.Duration
+}
+
+// TokenBucketStore é uma capacidade opcional do store para o algoritmo token bucket.
+// Verificação de bloqueio, reposição, consumo e bloqueio ocorrem atomicamente.
+type TokenBucketStore interface {
+	TakeToken(ctx context.Context, req TokenBucketRequest) (TokenBucketResult, error)
+}
+
+// TokenBucketRequest descreve o consumo de um token de um bucket
+type TokenBucketRequest struct {
+	BucketKey     string
+	BlockKey      string
+	Capacity      int64
+	RefillRate    float64 // tokens por segundo
+	BlockDuration time.Duration
+	// Cost é o número de tokens consumidos pela requisição (zero: 1)
+	Cost int64
+	// BlockReason é o limite gravado no bloqueio (ex.: 5/1s; vazio: "1")
+	BlockReason string
+}
+
+// TokenBucketResult é o resultado do consumo de um token
+type TokenBucketResult struct {
+	// Remaining é o número de tokens inteiros restantes no bucket
+	Remaining int64
+	// Blocked indica que a requisição foi negada
+	Blocked bool
+	// Tripped indica que esta verificação excedeu o limite (false se a chave já estava bloqueada)
+	Tripped bool
+	// RetryAfter é o tempo até haver tokens suficientes (zero se permitida)
+	RetryAfter time.Duration
+	// ResetAfter é o tempo até o bucket voltar a ficar cheio (o bloqueio restante
+	// se já estava bloqueada)
+	ResetAfter time.Duration
+}
+
+// SlidingWindowStore é uma capacidade opcional do store para o algoritmo de
+// janela deslizante por contador (janela anterior ponderada + janela atual).
+// O campo CounterKey do request identifica o estado da janela.
+type SlidingWindowStore interface {
+	AllowSlidingWindow(ctx context.Context, req AtomicRequest) (AtomicResult, error)
+}
+
+// SlidingLogStore é uma capacidade opcional do store para o algoritmo de
+// janela deslizante por log, que registra o instante de cada requisição.
+// O campo CounterKey do request identifica o log.
+type SlidingLogStore interface {
+	AllowSlidingLog(ctx context.Context, req AtomicRequest) (AtomicResult, error)
+}
+
+// GCRAStore é uma capacidade opcional do store para o algoritmo GCRA.
+// Apenas o instante teórico de chegada (TAT) é armazenado por chave.
+type GCRAStore interface {
+	AllowGCRA(ctx context.Context, req GCRARequest) (GCRAResult, error)
+}
+
+// GCRARequest descreve uma verificação GCRA
+type GCRARequest struct {
+	Key      string
+	BlockKey string
+	// EmissionInterval é o intervalo entre requisições na taxa sustentada
+	EmissionInterval time.Duration
+	// Tolerance é a variação permitida (intervalo × rajada)
+	Tolerance     time.Duration
+	BlockDuration time.Duration
+	// Cost é o número de unidades consumidas pela requisição (zero: 1)
+	Cost int64
+	// BlockReason é o limite gravado no bloqueio (ex.: 5/1s; vazio: "1")
+	BlockReason string
+}
+
+// GCRAResult é o resultado de uma verificação GCRA
+type GCRAResult struct {
+	Blocked bool
+	// Tripped indica que esta verificação excedeu o limite (false se a chave já estava bloqueada)
+	Tripped bool
+	// Remaining é o número de requisições ainda permitidas imediatamente
+	Remaining int64
+	// RetryAfter é o tempo até a próxima requisição ser permitida (zero se permitida)
+	RetryAfter time.Duration
+	// ResetAfter é o tempo até a chave voltar à capacidade total
+	ResetAfter time.Duration
+}
+
+// MultiAtomicStore é uma capacidade opcional do store para várias janelas fixas
+// aplicadas à mesma chave (ex.: por segundo, por minuto e por dia).
+// Todos os contadores são verificados antes de qualquer incremento: se algum
+// limite for excedido, nenhum contador é incrementado e o bloqueio é criado.
+type MultiAtomicStore interface {
+	AllowAtomicMulti(ctx context.Context, req MultiAtomicRequest) (MultiAtomicResult, error)
+}
+
+// OffenceStore é uma capacidade opcional do store para o escalonamento de bloqueios.
+// Escalate incrementa a contagem de infrações, renova sua expiração e grava o bloqueio
+// do degrau correspondente em uma única operação, sem perder infrações simultâneas de
+// outras réplicas nem recriar um bloqueio removido (ex.: /admin/unblock) após a verificação.
+type OffenceStore interface {
+	Escalate(ctx context.Context, req OffenceRequest) (OffenceResult, error)
+}
+
+// OffenceRequest descreve a infração de uma chave com escalonamento
+type OffenceRequest struct {
+	OffenceKey string
+	BlockKey   string
+	// Lookback é o período sem infrações após o qual a contagem é zerada
+	Lookback time.Duration
+	// Steps são os degraus do bloqueio (ver Escalation.Steps)
+	Steps []time.Duration
+	// BlockReason é gravada como valor do bloqueio
+	BlockReason string
+	// KeepUnblocked grava o bloqueio apenas se ele ainda existir: a verificação que
+	// excedeu o limite já o criou e sua remoção desde então deve ser respeitada
+	KeepUnblocked bool
+}
+
+// OffenceResult é o resultado de OffenceStore.Escalate
+type OffenceResult struct {
+	Offences int64
+	// BlockDuration é o bloqueio gravado (zero se nenhum foi gravado)
+	BlockDuration time.Duration
+}
+
+// AtomicCounter descreve um dos contadores de uma verificação múltipla
+type AtomicCounter struct {
+	Key           string
+	Limit         int64
+	Window        time.Duration
+	BlockDuration time.Duration
+	// Description identifica o limite e é gravada como valor do bloqueio
+	Description string
+}
+
+// MultiAtomicRequest descreve uma verificação atômica de múltiplas janelas fixas
+type MultiAtomicRequest struct {
+	BlockKey string
+	Counters []AtomicCounter
+	// Cost é o número de unidades somadas a cada contador (zero: 1)
+	Cost int64
+}
+
+// MultiAtomicResult é o resultado de uma verificação múltipla
+type MultiAtomicResult struct {
+	// Blocked indica que a requisição foi negada
+	Blocked bool
+	// Tripped é o índice do contador excedido nesta verificação (-1 se nenhum)
+	Tripped int
+	// Counts são os contadores após a verificação (vazio se já estava bloqueado)
+	Counts []int64
+	// Resets são os tempos até cada contador expirar (vazio se já estava bloqueado)
+	Resets []time.Duration
+	// BlockedBy é a descrição do limite que criou um bloqueio já existente
+	BlockedBy string
+	// RetryAfter é o tempo até haver capacidade para a requisição (zero se permitida)
+	RetryAfter time.Duration
+}
+
+// costOf normaliza o custo de uma requisição: valores menores que 1 valem 1
+func costOf(cost int64) int64 {
+	if cost < 1 {
+		return 1
+	}
+	return cost
+}
+
+// ErrInvalidCursor indica um cursor de ScanKeys que não foi retornado pelo store
+var ErrInvalidCursor = errors.New("cursor inválido")
+
+// KeyScanner é uma capacidade opcional do store para percorrer as chaves com um
+// prefixo em páginas, sem bloquear o backend (SCAN no Redis)
+type KeyScanner interface {
+	// ScanKeys retorna cerca de count chaves a partir do cursor (vazio: início) e o
+	// próximo cursor (vazio: fim). O cursor é opaco e específico do store; uma página
+	// pode vir vazia antes do fim.
+	ScanKeys(ctx context.Context, prefix string, cursor string, count int64) ([]ScannedKey, string, error)
+}
+
+// ScannedKey é uma chave encontrada por ScanKeys
+type ScannedKey struct {
+	Key   string
+	Value string
+	// TTL é o tempo restante até a chave expirar (zero se não expira)
+	TTL time.Duration
+}
+
+// blockValue é o valor gravado na chave de bloqueio: o limite excedido ou "1"
+func blockValue(reason string) string {
+	if reason == "" {
+		return "1"
+	}
+	return reason
+}
+
+// PeekStore é uma capacidade opcional do store para consultar o estado dos
+// algoritmos sem consumir capacidade nem criar bloqueios. A janela fixa é
+// consultada com GetCount e TTL e não depende dessa capacidade.
+type PeekStore interface {
+	PeekTokenBucket(ctx context.Context, req TokenBucketRequest) (PeekResult, error)
+	PeekSlidingWindow(ctx context.Context, req AtomicRequest) (PeekResult, error)
+	PeekSlidingLog(ctx context.Context, req AtomicRequest) (PeekResult, error)
+	PeekGCRA(ctx context.Context, req GCRARequest) (PeekResult, error)
+}
+
+// PeekResult é o estado atual de um algoritmo
+type PeekResult struct {
+	// Count é o uso atual (tokens consumidos no token bucket)
+	Count int64
+	// Remaining é o número de requisições ainda permitidas imediatamente
+	Remaining int64
+	// ResetAfter é o tempo até a chave voltar à capacidade total
+	ResetAfter time.Duration
+}
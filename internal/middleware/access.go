@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"expvar"
+	"net/http"
+	"net/netip"
+
+	"github.com/marfebr/go_ratelimit/internal/config"
+)
+
+// accessListHits conta as requisições que casaram com as listas (expvar "access_list")
+// Chaves: allow_ip, allow_token, deny_ip e deny_token
+var accessListHits = expvar.NewMap("access_list")
+
+// access é o resultado da verificação das listas de permissão e bloqueio
+type access int
+
+const (
+	// accessDefault segue para o rate limiting
+	accessDefault access = iota
+	// accessAllowed dispensa o rate limiting
+	accessAllowed
+	// accessDenied rejeita a requisição com 403
+	accessDenied
+)
+
+// accessList identifica clientes por rede do IP ou pelo token exato
+type accessList struct {
+	networks []netip.Prefix
+	tokens   map[string]bool
+}
+
+func newAccessList(list config.AccessList) accessList {
+	l := accessList{networks: list.Networks, tokens: make(map[string]bool, len(list.Tokens))}
+	for _, token := range list.Tokens {
+		l.tokens[token] = true
+	}
+	return l
+}
+
+func (l accessList) empty() bool {
+	return len(l.networks) == 0 && len(l.tokens) == 0
+}
+
+// match informa se o IP ou o token estão na lista e qual dos dois casou
+func (l accessList) match(ip netip.Addr, token string, hasToken bool) (string, bool) {
+	if ip.IsValid() {
+		for _, prefix := range l.networks {
+			if prefix.Contains(ip) {
+				return "ip", true
+			}
+		}
+	}
+	if hasToken && l.tokens[token] {
+		return "token", true
+	}
+	return "", false
+}
+
+// accessLists reúne as listas da configuração; a de bloqueio prevalece
+type accessLists struct {
+	allow accessList
+	deny  accessList
+}
+
+func newAccessLists(cfg *config.Config) accessLists {
+	return accessLists{allow: newAccessList(cfg.AllowList), deny: newAccessList(cfg.DenyList)}
+}
+
+// check avalia o IP do cliente e o token da requisição, sem consultar o store
+func (a accessLists) check(r *http.Request, cfg *config.Config, tokenExtractor KeyExtractor) access {
+	if a.allow.empty() && a.deny.empty() {
+		return accessDefault
+	}
+
+	ip, _ := netip.ParseAddr(extractIP(r, cfg.TrustedProxies))
+	token, hasToken := tokenExtractor.ExtractKey(r)
+
+	if kind, ok := a.deny.match(ip.Unmap(), token, hasToken); ok {
+		accessListHits.Add("deny_"+kind, 1)
+		return accessDenied
+	}
+	if kind, ok := a.allow.match(ip.Unmap(), token, hasToken); ok {
+		accessListHits.Add("allow_"+kind, 1)
+		return accessAllowed
+	}
+	return accessDefault
+}
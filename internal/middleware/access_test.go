@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/marfebr/go_ratelimit/internal/config"
+	"github.com/marfebr/go_ratelimit/internal/limiter"
+)
+
+func TestRateLimitMiddleware_AccessLists(t *testing.T) {
+	// Setup - limite de 1 req/s; listas por rede e por token
+	mockStore := newMockStore()
+	coreLimiter := limiter.NewCoreLimiter(mockStore)
+	cfg := &config.Config{
+		DefaultRateLimitIP:     1,
+		DefaultBlockDurationIP: 300,
+		TokenLimits:            make(map[string]config.TokenLimit),
+		AllowList: config.AccessList{
+			Networks: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")},
+			Tokens:   []string{"monitoring"},
+		},
+		DenyList: config.AccessList{
+			Networks: []netip.Prefix{netip.MustParsePrefix("203.0.113.0/24"), netip.MustParsePrefix("10.6.6.6/32")},
+			Tokens:   []string{"leaked"},
+		},
+	}
+	handler := RateLimitMiddleware(coreLimiter, cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	send := func(remoteAddr, token string) []int {
+		var codes []int
+		for i := 0; i < 3; i++ {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = remoteAddr
+			if token != "" {
+				req.Header.Set("API_KEY", token)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			codes = append(codes, w.Code)
+		}
+		return codes
+	}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		token      string
+		want       []int
+	}{
+		{"rede permitida", "10.1.2.3:1234", "", []int{200, 200, 200}},
+		{"token permitido", "192.168.1.1:1234", "monitoring", []int{200, 200, 200}},
+		{"rede bloqueada", "203.0.113.7:1234", "", []int{403, 403, 403}},
+		{"token bloqueado", "192.168.1.2:1234", "leaked", []int{403, 403, 403}},
+		{"bloqueio prevalece", "10.6.6.6:1234", "", []int{403, 403, 403}},
+		{"fora das listas", "192.168.1.3:1234", "", []int{200, 429, 429}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Execute
+			codes := send(tt.remoteAddr, tt.token)
+
+			// Assert
+			for i := range tt.want {
+				if codes[i] != tt.want[i] {
+					t.Errorf("Status esperados %v, obtidos %v", tt.want, codes)
+					break
+				}
+			}
+		})
+	}
+
+	// Listas são avaliadas sem consultar o store
+	if hits := accessListHits.Get("deny_ip"); hits == nil || hits.String() == "0" {
+		t.Error("Contador deny_ip deveria ter sido incrementado")
+	}
+	if len(mockStore.counters) != 1 {
+		t.Errorf("Apenas o cliente fora das listas deveria usar o store, contadores: %v", mockStore.counters)
+	}
+}
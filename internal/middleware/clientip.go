@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// extractIP extrai o endereço IP do cliente da requisição
+// Os headers de encaminhamento só são considerados quando a conexão vem de um
+// proxy confiável; sem proxies confiáveis, vale sempre o endereço da conexão.
+func extractIP(r *http.Request, trustedProxies []netip.Prefix) string {
+	remote, ok := parseHop(r.RemoteAddr)
+	if !ok {
+		return hostOnly(r.RemoteAddr)
+	}
+	if !isTrusted(remote, trustedProxies) {
+		return remote.String()
+	}
+
+	// Forwarded (RFC 7239) tem prioridade sobre X-Forwarded-For
+	if hops := forwardedFor(r.Header.Values("Forwarded")); len(hops) > 0 {
+		return walkHops(remote, hops, trustedProxies).String()
+	}
+	if hops := forwardedList(r.Header.Values("X-Forwarded-For")); len(hops) > 0 {
+		return walkHops(remote, hops, trustedProxies).String()
+	}
+
+	// X-Real-IP é um único endereço definido pelo proxy
+	if realIP, ok := parseHop(r.Header.Get("X-Real-IP")); ok {
+		return realIP.String()
+	}
+	return remote.String()
+}
+
+// walkHops percorre a cadeia de encaminhamento da direita para a esquerda e
+// retorna o primeiro endereço não confiável, que é o cliente. Entradas inválidas
+// interrompem o percurso no último endereço válido, pois o que está à esquerda
+// delas pode ter sido forjado. Se todos forem confiáveis, vale o mais à esquerda.
+func walkHops(remote netip.Addr, hops []string, trustedProxies []netip.Prefix) netip.Addr {
+	client := remote
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, ok := parseHop(hops[i])
+		if !ok {
+			return client
+		}
+		client = addr
+		if !isTrusted(addr, trustedProxies) {
+			return client
+		}
+	}
+	return client
+}
+
+// forwardedList separa as entradas de headers no formato X-Forwarded-For
+// Várias ocorrências do header equivalem a uma lista concatenada
+func forwardedList(values []string) []string {
+	var hops []string
+	for _, value := range values {
+		for _, hop := range strings.Split(value, ",") {
+			hops = append(hops, strings.TrimSpace(hop))
+		}
+	}
+	return hops
+}
+
+// forwardedFor extrai o parâmetro for= de cada elemento do header Forwarded (RFC 7239)
+// Ex.: Forwarded: for=192.0.2.60;proto=https, for="[2001:db8::17]:4711"
+func forwardedFor(values []string) []string {
+	var hops []string
+	for _, element := range forwardedList(values) {
+		hop := ""
+		for _, pair := range strings.Split(element, ";") {
+			name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if ok && strings.EqualFold(name, "for") {
+				hop = strings.Trim(value, `"`)
+			}
+		}
+		// Elementos sem for= contam como entradas inválidas
+		hops = append(hops, hop)
+	}
+	return hops
+}
+
+// parseHop interpreta um endereço com ou sem porta (1.2.3.4, 1.2.3.4:80, ::1, [::1]:80)
+func parseHop(value string) (netip.Addr, bool) {
+	value = strings.TrimSpace(value)
+	if addrPort, err := netip.ParseAddrPort(value); err == nil {
+		return addrPort.Addr().Unmap(), true
+	}
+	addr, err := netip.ParseAddr(strings.Trim(value, "[]"))
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
+
+// isTrusted informa se o endereço pertence a alguma rede de proxy confiável
+func isTrusted(addr netip.Addr, trustedProxies []netip.Prefix) bool {
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// hostOnly remove a porta (e os colchetes de IPv6) de endereços que não puderam ser interpretados
+func hostOnly(remoteAddr string) string {
+	if host, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		return host
+	}
+	return strings.Trim(remoteAddr, "[]")
+}
+
+// aggregateIP agrupa o endereço pelo prefixo configurado para a sua família
+// Ex.: com /64, 2001:db8::1 e 2001:db8::ffff viram 2001:db8::/64. Endereços sem
+// agrupamento (prefixo zero ou completo) e inválidos são mantidos como estão.
+func aggregateIP(ip string, ipv4PrefixLength, ipv6PrefixLength int) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ip
+	}
+	bits := ipv4PrefixLength
+	if addr.Is6() {
+		bits = ipv6PrefixLength
+	}
+	if bits <= 0 || bits >= addr.BitLen() {
+		return addr.String()
+	}
+	prefix, err := addr.WithZone("").Prefix(bits)
+	if err != nil {
+		return addr.String()
+	}
+	return prefix.String()
+}
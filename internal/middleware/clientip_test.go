@@ -0,0 +1,220 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/marfebr/go_ratelimit/internal/config"
+	"github.com/marfebr/go_ratelimit/internal/limiter"
+)
+
+func trustedProxies(t *testing.T, value string) []netip.Prefix {
+	t.Helper()
+	proxies, err := config.ParseTrustedProxies(value)
+	if err != nil {
+		t.Fatalf("Erro ao interpretar proxies: %v", err)
+	}
+	return proxies
+}
+
+func newForwardedRequest(remoteAddr string, headers map[string]string) *http.Request {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = remoteAddr
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	return req
+}
+
+func TestExtractIP_RemoteAddr(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "192.168.1.100:54321"
+
+	ip := extractIP(req, nil)
+
+	if ip != "192.168.1.100" {
+		t.Errorf("IP esperado '192.168.1.100', obtido '%s'", ip)
+	}
+}
+
+func TestExtractIP_IgnoresHeadersWithoutTrustedProxies(t *testing.T) {
+	// Sem proxies confiáveis, os headers podem ser forjados pelo cliente
+	req := newForwardedRequest("10.0.0.1:12345", map[string]string{
+		"X-Forwarded-For": "203.0.113.1",
+		"X-Real-IP":       "203.0.113.2",
+		"Forwarded":       "for=203.0.113.3",
+	})
+
+	ip := extractIP(req, nil)
+
+	if ip != "10.0.0.1" {
+		t.Errorf("IP esperado '10.0.0.1', obtido '%s'", ip)
+	}
+}
+
+func TestExtractIP_IgnoresHeadersFromUntrustedPeer(t *testing.T) {
+	req := newForwardedRequest("198.51.100.7:12345", map[string]string{"X-Forwarded-For": "203.0.113.1"})
+
+	ip := extractIP(req, trustedProxies(t, "10.0.0.0/8"))
+
+	if ip != "198.51.100.7" {
+		t.Errorf("IP esperado '198.51.100.7', obtido '%s'", ip)
+	}
+}
+
+func TestExtractIP_XForwardedFor(t *testing.T) {
+	req := newForwardedRequest("10.0.0.1:12345", map[string]string{"X-Forwarded-For": "203.0.113.1, 198.51.100.1"})
+
+	ip := extractIP(req, trustedProxies(t, "10.0.0.0/8"))
+
+	// O último salto não confiável é o cliente
+	if ip != "198.51.100.1" {
+		t.Errorf("IP esperado '198.51.100.1', obtido '%s'", ip)
+	}
+}
+
+func TestExtractIP_XForwardedForSkipsTrustedHops(t *testing.T) {
+	// O cliente forja o primeiro endereço; os dois proxies internos acrescentam os seus
+	req := newForwardedRequest("10.0.0.1:12345", map[string]string{"X-Forwarded-For": "1.1.1.1, 203.0.113.9, 10.0.0.2"})
+
+	ip := extractIP(req, trustedProxies(t, "10.0.0.0/8"))
+
+	if ip != "203.0.113.9" {
+		t.Errorf("IP esperado '203.0.113.9', obtido '%s'", ip)
+	}
+}
+
+func TestExtractIP_XForwardedForAllTrusted(t *testing.T) {
+	req := newForwardedRequest("10.0.0.1:12345", map[string]string{"X-Forwarded-For": "10.0.0.3, 10.0.0.2"})
+
+	ip := extractIP(req, trustedProxies(t, "10.0.0.0/8"))
+
+	if ip != "10.0.0.3" {
+		t.Errorf("IP esperado '10.0.0.3', obtido '%s'", ip)
+	}
+}
+
+func TestExtractIP_XForwardedForInvalidHop(t *testing.T) {
+	// Uma entrada inválida interrompe o percurso no último endereço válido
+	req := newForwardedRequest("10.0.0.1:12345", map[string]string{"X-Forwarded-For": "203.0.113.1, garbage, 10.0.0.2"})
+
+	ip := extractIP(req, trustedProxies(t, "10.0.0.0/8"))
+
+	if ip != "10.0.0.2" {
+		t.Errorf("IP esperado '10.0.0.2', obtido '%s'", ip)
+	}
+}
+
+func TestExtractIP_Forwarded(t *testing.T) {
+	req := newForwardedRequest("10.0.0.1:12345", map[string]string{
+		"Forwarded":       `for="[2001:db8:cafe::17]:4711";proto=https, for=10.0.0.2;by=10.0.0.1`,
+		"X-Forwarded-For": "203.0.113.1",
+	})
+
+	ip := extractIP(req, trustedProxies(t, "10.0.0.0/8"))
+
+	// Forwarded tem prioridade sobre X-Forwarded-For
+	if ip != "2001:db8:cafe::17" {
+		t.Errorf("IP esperado '2001:db8:cafe::17', obtido '%s'", ip)
+	}
+}
+
+func TestExtractIP_XRealIP(t *testing.T) {
+	req := newForwardedRequest("10.0.0.1:12345", map[string]string{"X-Real-IP": "203.0.113.2"})
+
+	ip := extractIP(req, trustedProxies(t, "10.0.0.0/8"))
+
+	if ip != "203.0.113.2" {
+		t.Errorf("IP esperado '203.0.113.2', obtido '%s'", ip)
+	}
+}
+
+func TestExtractIP_Priority(t *testing.T) {
+	// X-Forwarded-For tem prioridade sobre X-Real-IP
+	req := newForwardedRequest("10.0.0.1:12345", map[string]string{
+		"X-Forwarded-For": "203.0.113.1",
+		"X-Real-IP":       "203.0.113.2",
+	})
+
+	ip := extractIP(req, trustedProxies(t, "10.0.0.0/8"))
+
+	if ip != "203.0.113.1" {
+		t.Errorf("X-Forwarded-For deveria ter prioridade, obtido '%s'", ip)
+	}
+}
+
+func TestExtractIP_IPv6RemoteAddr(t *testing.T) {
+	tests := map[string]string{
+		"[2001:db8::1]:54321": "2001:db8::1",
+		"[::1]:8080":          "::1",
+		"2001:db8::2":         "2001:db8::2",
+		"[::ffff:10.0.0.1]:1": "10.0.0.1",
+	}
+
+	for remoteAddr, want := range tests {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = remoteAddr
+
+		if ip := extractIP(req, nil); ip != want {
+			t.Errorf("RemoteAddr %s: IP esperado '%s', obtido '%s'", remoteAddr, want, ip)
+		}
+	}
+}
+
+func TestAggregateIP(t *testing.T) {
+	tests := []struct {
+		ip         string
+		ipv4, ipv6 int
+		want       string
+	}{
+		{"2001:db8:1:2:aaaa::1", 32, 64, "2001:db8:1:2::/64"},
+		{"2001:db8:1:2:ffff::9", 32, 64, "2001:db8:1:2::/64"},
+		{"2001:db8:1:2::1", 32, 128, "2001:db8:1:2::1"},
+		{"203.0.113.7", 32, 64, "203.0.113.7"},
+		{"203.0.113.7", 24, 64, "203.0.113.0/24"},
+		{"203.0.113.7", 0, 0, "203.0.113.7"},
+		{"fe80::1%eth0", 32, 64, "fe80::/64"},
+		{"not-an-ip", 24, 64, "not-an-ip"},
+	}
+
+	for _, tt := range tests {
+		if got := aggregateIP(tt.ip, tt.ipv4, tt.ipv6); got != tt.want {
+			t.Errorf("aggregateIP(%s, /%d, /%d) esperado %s, obtido %s", tt.ip, tt.ipv4, tt.ipv6, tt.want, got)
+		}
+	}
+}
+
+func TestRateLimitMiddleware_IPv6PrefixSharesLimit(t *testing.T) {
+	// Setup
+	coreLimiter := limiter.NewCoreLimiter(newMockStore())
+	cfg := &config.Config{
+		DefaultRateLimitIP:     2,
+		DefaultBlockDurationIP: 300,
+		TokenLimits:            make(map[string]config.TokenLimit),
+		IPv4PrefixLength:       32,
+		IPv6PrefixLength:       64,
+	}
+	handler := RateLimitMiddleware(coreLimiter, cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	// Execute - endereços diferentes da mesma /64
+	var codes []int
+	for _, remoteAddr := range []string{"[2001:db8::1]:1000", "[2001:db8::2]:1000", "[2001:db8::3]:1000", "[2001:db8:0:1::1]:1000"} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = remoteAddr
+		handler.ServeHTTP(w, req)
+		codes = append(codes, w.Code)
+	}
+
+	// Assert - a terceira excede o limite da /64; outra /64 tem limite próprio
+	want := []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests, http.StatusOK}
+	for i := range want {
+		if codes[i] != want[i] {
+			t.Errorf("Requisição %d: status esperado %d, obtido %d", i+1, want[i], codes[i])
+		}
+	}
+}
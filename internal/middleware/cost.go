@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/marfebr/go_ratelimit/internal/config"
+)
+
+// Coster é implementado por handlers que informam o custo das próprias requisições
+// (ex.: uma exportação em lote que vale 50 requisições)
+type Coster interface {
+	RequestCost(r *http.Request) int
+}
+
+// costHandler associa um custo fixo a um handler
+type costHandler struct {
+	http.Handler
+	cost int
+}
+
+func (h costHandler) RequestCost(*http.Request) int {
+	return h.cost
+}
+
+// WithCost envolve o handler informando ao middleware que cada requisição custa n unidades
+// Ex.: mux.Handle("/export", middleware.WithCost(50, exportHandler))
+func WithCost(n int, handler http.Handler) http.Handler {
+	return costHandler{Handler: handler, cost: n}
+}
+
+// handlerLookup é implementado por roteadores capazes de resolver o handler
+// de uma requisição sem executá-lo, como http.ServeMux
+type handlerLookup interface {
+	Handler(r *http.Request) (http.Handler, string)
+}
+
+// requestCost determina o custo da requisição
+// Prioridade: custo por rota da configuração > custo informado pelo handler > 1
+func requestCost(r *http.Request, costs []config.RouteCost, next http.Handler) int {
+	if cost, ok := routeCost(r.URL.Path, costs); ok {
+		return cost
+	}
+
+	handler := next
+	if mux, ok := next.(handlerLookup); ok {
+		handler, _ = mux.Handler(r)
+	}
+	if coster, ok := handler.(Coster); ok {
+		if cost := coster.RequestCost(r); cost > 0 {
+			return cost
+		}
+	}
+
+	return 1
+}
+
+// routeCost retorna o custo do maior prefixo configurado que casa com o caminho
+func routeCost(path string, costs []config.RouteCost) (int, bool) {
+	best := -1
+	for i, c := range costs {
+		if hasPathPrefix(path, c.PathPrefix) && (best < 0 || len(c.PathPrefix) > len(costs[best].PathPrefix)) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return 0, false
+	}
+	return costs[best].Cost, true
+}
+
+// hasPathPrefix informa se o caminho começa pelo prefixo em segmentos inteiros
+// (/export casa com /export e /export/csv, mas não com /exports)
+func hasPathPrefix(path, prefix string) bool {
+	if !strings.HasPrefix(path, prefix) {
+		return false
+	}
+	return len(path) == len(prefix) || strings.HasSuffix(prefix, "/") || path[len(prefix)] == '/'
+}
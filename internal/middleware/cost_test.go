@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/marfebr/go_ratelimit/internal/config"
+	"github.com/marfebr/go_ratelimit/internal/limiter"
+)
+
+func TestRequestCost(t *testing.T) {
+	// Setup
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	mux := http.NewServeMux()
+	mux.Handle("/export", WithCost(50, ok))
+	mux.Handle("/", ok)
+	costs := []config.RouteCost{{PathPrefix: "/search", Cost: 10}, {PathPrefix: "/search/full", Cost: 30}}
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/", 1},
+		{"/export", 50},
+		{"/search", 10},
+		{"/search/full/text", 30},
+		// Prefixos valem apenas em segmentos inteiros do caminho
+		{"/searches", 1},
+		{"/search/fullname", 10},
+	}
+
+	for _, tt := range tests {
+		// Execute
+		got := requestCost(httptest.NewRequest("GET", tt.path, nil), costs, mux)
+
+		// Assert
+		if got != tt.want {
+			t.Errorf("%s: custo esperado %d, obtido %d", tt.path, tt.want, got)
+		}
+	}
+}
+
+func TestRequestCost_ConfigOverridesHandler(t *testing.T) {
+	// Setup
+	mux := http.NewServeMux()
+	mux.Handle("/export", WithCost(50, http.NotFoundHandler()))
+	costs := []config.RouteCost{{PathPrefix: "/export", Cost: 20}}
+
+	// Execute
+	got := requestCost(httptest.NewRequest("GET", "/export", nil), costs, mux)
+
+	// Assert
+	if got != 20 {
+		t.Errorf("Custo da configuração deveria prevalecer: esperado 20, obtido %d", got)
+	}
+}
+
+func TestRateLimitMiddleware_WeightedCost(t *testing.T) {
+	// Setup
+	store := limiter.NewMemoryStore()
+	coreLimiter := limiter.NewCoreLimiter(store)
+	defer coreLimiter.Close()
+	cfg := &config.Config{
+		DefaultRateLimitIP:     60,
+		DefaultBlockDurationIP: 0,
+		TokenLimits:            make(map[string]config.TokenLimit),
+	}
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux := http.NewServeMux()
+	mux.Handle("/export", WithCost(50, ok))
+	mux.Handle("/", ok)
+	handler := RateLimitMiddleware(coreLimiter, cfg)(mux)
+
+	send := func(path string) int {
+		req := httptest.NewRequest("GET", path, nil)
+		req.RemoteAddr = "192.168.1.7:12345"
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// Execute - a exportação consome 50 das 60 unidades
+	if code := send("/export"); code != http.StatusOK {
+		t.Fatalf("Exportação deveria ser permitida, status %d", code)
+	}
+
+	// Assert - requisições simples ainda cabem, mas uma segunda exportação não
+	if code := send("/"); code != http.StatusOK {
+		t.Errorf("Requisição simples deveria ser permitida, status %d", code)
+	}
+	if code := send("/export"); code != http.StatusTooManyRequests {
+		t.Errorf("Segunda exportação deveria ser bloqueada, status %d", code)
+	}
+}
+
+func TestRateLimitMiddleware_CostExceedsLimit(t *testing.T) {
+	// Setup - custo 50 com o limite padrão de 5/s e bloqueio de 300s
+	coreLimiter := limiter.NewCoreLimiter(limiter.NewMemoryStore())
+	defer coreLimiter.Close()
+	cfg := &config.Config{
+		DefaultRateLimitIP:     5,
+		DefaultBlockDurationIP: 300,
+		TokenLimits:            make(map[string]config.TokenLimit),
+		RouteCosts:             []config.RouteCost{{PathPrefix: "/export", Cost: 50}},
+	}
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := RateLimitMiddleware(coreLimiter, cfg)(ok)
+
+	send := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req.RemoteAddr = "192.168.1.8:12345"
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	// Execute
+	w := send("/export")
+
+	// Assert - negada sem bloquear a chave nem consumir o limite
+	if w.Code != http.StatusTooManyRequests {
+		t.Errorf("Custo acima do limite deveria ser negado, status %d", w.Code)
+	}
+	if retryAfter := w.Header().Get("Retry-After"); retryAfter != "" {
+		t.Errorf("Sem bloqueio, Retry-After não deveria ser enviado, obtido %q", retryAfter)
+	}
+	for i := 0; i < 5; i++ {
+		if w := send("/"); w.Code != http.StatusOK {
+			t.Fatalf("Requisição %d deveria ser permitida após a exportação negada, status %d", i+1, w.Code)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/marfebr/go_ratelimit/internal/config"
+	"github.com/marfebr/go_ratelimit/internal/limiter"
+)
+
+// limitState reúne os valores informados nos headers de rate limit
+type limitState struct {
+	rule      limiter.Rule
+	limit     int64
+	remaining int64
+	// reset é o tempo até a regra voltar à capacidade total
+	reset time.Duration
+	// retryAfter é o tempo até a próxima requisição ser permitida (apenas negadas)
+	retryAfter time.Duration
+}
+
+// limitStateOf monta os valores dos headers a partir do próprio resultado da verificação,
+// sem novas consultas ao store em respostas permitidas. Em respostas negadas, o
+// Retry-After usa o tempo restante da chave rl:blk:, exceto no estilo none.
+func limitStateOf(ctx context.Context, coreLimiter *limiter.CoreLimiter, style, key string, status *limiter.BlockStatus) limitState {
+	capacity := status.Rule.Capacity()
+	state := limitState{
+		rule:      status.Rule,
+		limit:     capacity,
+		remaining: max(capacity-status.CurrentCount, 0),
+	}
+	if !status.ResetAt.IsZero() {
+		state.reset = time.Until(status.ResetAt)
+	}
+
+	if !status.Allowed {
+		state.remaining = 0
+		state.retryAfter = status.RetryAfter
+		// Com o store indisponível o resultado veio da política de falha: não há o que consultar
+		if style != config.HeadersNone && status.Failure == "" {
+			if ttl, err := coreLimiter.BlockTTL(ctx, key); err == nil && ttl > 0 {
+				state.retryAfter = ttl
+			}
+		}
+		if state.retryAfter <= 0 {
+			state.retryAfter = state.reset
+		}
+		state.reset = max(state.reset, state.retryAfter)
+	}
+	return state
+}
+
+// writeLimitHeaders escreve os headers de rate limit no estilo configurado
+// e, em respostas negadas, o Retry-After
+func writeLimitHeaders(w http.ResponseWriter, style string, rules []limiter.Rule, state limitState, allowed bool) {
+	header := w.Header()
+
+	if style == "" || style == config.HeadersLegacy || style == config.HeadersBoth {
+		header.Set("X-RateLimit-Limit", strconv.FormatInt(state.limit, 10))
+		header.Set("X-RateLimit-Remaining", strconv.FormatInt(state.remaining, 10))
+		header.Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Unix()+ceilSeconds(state.reset), 10))
+	}
+
+	if style == config.HeadersIETF || style == config.HeadersBoth {
+		policies := make([]string, len(rules))
+		for i, rule := range rules {
+			if rule.Window <= 0 {
+				rule.Window = time.Second
+			}
+			policies[i] = strconv.Quote(rule.String()) +
+				";q=" + strconv.FormatInt(rule.Capacity(), 10) +
+				";w=" + strconv.FormatInt(ceilSeconds(rule.Window), 10)
+		}
+		header.Set("RateLimit-Policy", strings.Join(policies, ", "))
+		header.Set("RateLimit", strconv.Quote(state.rule.String())+
+			";r="+strconv.FormatInt(state.remaining, 10)+
+			";t="+strconv.FormatInt(ceilSeconds(state.reset), 10))
+	}
+
+	if !allowed {
+		header.Set("Retry-After", strconv.FormatInt(max(ceilSeconds(state.retryAfter), 1), 10))
+	}
+}
+
+// ceilSeconds arredonda a duração para cima em segundos inteiros
+func ceilSeconds(d time.Duration) int64 {
+	if d <= 0 {
+		return 0
+	}
+	return int64((d + time.Second - 1) / time.Second)
+}
@@ -0,0 +1,186 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/marfebr/go_ratelimit/internal/config"
+	"github.com/marfebr/go_ratelimit/internal/limiter"
+)
+
+func newHeadersHandler(style string) http.Handler {
+	coreLimiter := limiter.NewCoreLimiter(newMockStore())
+	cfg := &config.Config{
+		DefaultRateLimitIP:     3,
+		DefaultWindowIP:        time.Minute,
+		DefaultBlockDurationIP: 300,
+		TokenLimits:            make(map[string]config.TokenLimit),
+		RateLimitHeaders:       style,
+	}
+	return RateLimitMiddleware(coreLimiter, cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func sendFrom(handler http.Handler, remoteAddr string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = remoteAddr
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimitHeaders_Legacy(t *testing.T) {
+	// Setup
+	handler := newHeadersHandler(config.HeadersLegacy)
+
+	// Execute
+	w := sendFrom(handler, "192.168.1.1:12345")
+
+	// Assert
+	if got := w.Header().Get("X-RateLimit-Limit"); got != "3" {
+		t.Errorf("X-RateLimit-Limit esperado 3, obtido %q", got)
+	}
+	if got := w.Header().Get("X-RateLimit-Remaining"); got != "2" {
+		t.Errorf("X-RateLimit-Remaining esperado 2, obtido %q", got)
+	}
+	reset, err := strconv.ParseInt(w.Header().Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil || reset <= time.Now().Unix() || reset > time.Now().Add(time.Minute+time.Second).Unix() {
+		t.Errorf("X-RateLimit-Reset deveria estar no próximo minuto, obtido %q", w.Header().Get("X-RateLimit-Reset"))
+	}
+	if w.Header().Get("RateLimit") != "" || w.Header().Get("Retry-After") != "" {
+		t.Error("Estilo legacy não deveria emitir headers IETF nem Retry-After em resposta permitida")
+	}
+}
+
+func TestRateLimitHeaders_BlockedRetryAfter(t *testing.T) {
+	// Setup
+	handler := newHeadersHandler(config.HeadersLegacy)
+	for i := 0; i < 3; i++ {
+		sendFrom(handler, "192.168.1.2:12345")
+	}
+
+	// Execute
+	w := sendFrom(handler, "192.168.1.2:12345")
+
+	// Assert - Retry-After vem do TTL do bloqueio (300s)
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Status esperado 429, obtido %d", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "300" {
+		t.Errorf("Retry-After esperado 300, obtido %q", got)
+	}
+	if got := w.Header().Get("X-RateLimit-Remaining"); got != "0" {
+		t.Errorf("X-RateLimit-Remaining esperado 0, obtido %q", got)
+	}
+	reset, _ := strconv.ParseInt(w.Header().Get("X-RateLimit-Reset"), 10, 64)
+	if reset < time.Now().Add(299*time.Second).Unix() {
+		t.Errorf("X-RateLimit-Reset deveria cobrir o bloqueio, obtido %d", reset)
+	}
+}
+
+func TestRateLimitHeaders_IETF(t *testing.T) {
+	// Setup
+	handler := newHeadersHandler(config.HeadersIETF)
+
+	// Execute
+	w := sendFrom(handler, "192.168.1.3:12345")
+
+	// Assert
+	if got := w.Header().Get("RateLimit-Policy"); got != `"3/1m";q=3;w=60` {
+		t.Errorf("RateLimit-Policy inesperado: %q", got)
+	}
+	if got := w.Header().Get("RateLimit"); got != `"3/1m";r=2;t=60` {
+		t.Errorf("RateLimit inesperado: %q", got)
+	}
+	if w.Header().Get("X-RateLimit-Limit") != "" {
+		t.Error("Estilo ietf não deveria emitir headers X-RateLimit")
+	}
+}
+
+func TestRateLimitHeaders_None(t *testing.T) {
+	// Setup
+	handler := newHeadersHandler(config.HeadersNone)
+	for i := 0; i < 3; i++ {
+		sendFrom(handler, "192.168.1.4:12345")
+	}
+
+	// Execute
+	w := sendFrom(handler, "192.168.1.4:12345")
+
+	// Assert - apenas o Retry-After permanece
+	if w.Header().Get("X-RateLimit-Limit") != "" || w.Header().Get("RateLimit") != "" {
+		t.Error("Estilo none não deveria emitir headers de rate limit")
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("Retry-After deveria ser emitido no 429")
+	}
+}
+
+// readCountingStore conta as leituras avulsas do store, fora das verificações atômicas
+type readCountingStore struct {
+	*limiter.MemoryStore
+	reads int
+}
+
+func (s *readCountingStore) GetCount(ctx context.Context, key string) (int64, error) {
+	s.reads++
+	return s.MemoryStore.GetCount(ctx, key)
+}
+
+func (s *readCountingStore) Exists(ctx context.Context, key string) (bool, error) {
+	s.reads++
+	return s.MemoryStore.Exists(ctx, key)
+}
+
+func (s *readCountingStore) TTL(ctx context.Context, key string) (time.Duration, error) {
+	s.reads++
+	return s.MemoryStore.TTL(ctx, key)
+}
+
+func TestRateLimitHeaders_StoreReads(t *testing.T) {
+	tests := []struct {
+		style string
+		// leituras esperadas na resposta negada (tempo restante do bloqueio)
+		deniedReads int
+	}{
+		{config.HeadersLegacy, 1},
+		{config.HeadersIETF, 1},
+		{config.HeadersNone, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.style, func(t *testing.T) {
+			// Setup
+			store := &readCountingStore{MemoryStore: limiter.NewMemoryStore()}
+			coreLimiter := limiter.NewCoreLimiter(store)
+			defer coreLimiter.Close()
+			cfg := &config.Config{DefaultRateLimitIP: 2, DefaultWindowIP: time.Minute, DefaultBlockDurationIP: 300, RateLimitHeaders: tt.style}
+			handler := RateLimitMiddleware(coreLimiter, cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			// Execute & Assert - respostas permitidas usam apenas o resultado da verificação
+			for i := 0; i < 2; i++ {
+				if w := sendFrom(handler, "192.168.1.5:12345"); w.Code != http.StatusOK {
+					t.Fatalf("Requisição %d: status esperado 200, obtido %d", i+1, w.Code)
+				}
+			}
+			if store.reads != 0 {
+				t.Errorf("Respostas permitidas não deveriam consultar o store, obtido %d leituras", store.reads)
+			}
+
+			w := sendFrom(handler, "192.168.1.5:12345")
+			if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "300" {
+				t.Errorf("Esperado 429 com Retry-After 300, obtido %d e %q", w.Code, w.Header().Get("Retry-After"))
+			}
+			if store.reads != tt.deniedReads {
+				t.Errorf("Resposta negada: esperado %d leituras, obtido %d", tt.deniedReads, store.reads)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/marfebr/go_ratelimit/internal/config"
+	"github.com/marfebr/go_ratelimit/internal/jwtauth"
+	"github.com/marfebr/go_ratelimit/internal/limiter"
+)
+
+// WithJWTVerifier habilita a identificação por tokens JWT em Authorization: Bearer
+// A chave vem das claims cfg.JWT.KeyClaims e os limites do plano em cfg.JWT.PlanClaim
+func WithJWTVerifier(verifier *jwtauth.Verifier) Option {
+	return func(o *options) {
+		o.jwtVerifier = verifier
+	}
+}
+
+// jwtIdentity é o cliente identificado por um token JWT válido
+type jwtIdentity struct {
+	key   string
+	rules []limiter.Rule
+	quota config.Quota
+}
+
+// identifyJWT verifica o bearer token da requisição
+// Retorna ok=false sem token ou sem as claims da chave; err indica um token inválido
+func identifyJWT(r *http.Request, verifier *jwtauth.Verifier, cfg *config.Config) (identity jwtIdentity, ok bool, err error) {
+	token, ok := BearerKey().ExtractKey(r)
+	if !ok {
+		return jwtIdentity{}, false, nil
+	}
+
+	claims, err := verifier.Verify(token)
+	if err != nil {
+		return jwtIdentity{}, false, err
+	}
+
+	parts := make([]string, 0, len(cfg.JWT.KeyClaims))
+	for _, name := range cfg.JWT.KeyClaims {
+		value, ok := claims.String(name)
+		if !ok {
+			return jwtIdentity{}, false, nil
+		}
+		parts = append(parts, value)
+	}
+	if len(parts) == 0 {
+		return jwtIdentity{}, false, nil
+	}
+
+	identity = jwtIdentity{key: "jwt:" + strings.Join(parts, ":"), rules: ipRules(cfg)}
+	if plan, ok := claims.String(cfg.JWT.PlanClaim); ok {
+		planLimit, exists := cfg.JWT.Plans[plan]
+		if !exists {
+			planLimit, exists = cfg.ResolvePlan(plan, config.TokenOverrides{})
+		}
+		if exists {
+			identity.rules = tokenRules(cfg, planLimit)
+			identity.quota = planLimit.Quota
+		}
+	}
+	return identity, true, nil
+}
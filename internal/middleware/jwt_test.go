@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/marfebr/go_ratelimit/internal/config"
+	"github.com/marfebr/go_ratelimit/internal/jwtauth"
+	"github.com/marfebr/go_ratelimit/internal/limiter"
+)
+
+// signHS256 monta um token JWT HS256 para os testes
+func signHS256(claims map[string]any, secret string) string {
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+	payload, _ := json.Marshal(claims)
+	signed := header + "." + base64.RawURLEncoding.EncodeToString(payload)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func newJWTHandler(action string) http.Handler {
+	coreLimiter := limiter.NewCoreLimiter(newMockStore())
+	cfg := &config.Config{
+		DefaultRateLimitIP:     1,
+		DefaultBlockDurationIP: 300,
+		TokenLimits:            make(map[string]config.TokenLimit),
+		JWT: config.JWTConfig{
+			KeyClaims:          []string{"tenant_id", "sub"},
+			PlanClaim:          "plan",
+			Plans:              map[string]config.TokenLimit{"pro": {Limit: 3, BlockDurationSecs: 300}},
+			InvalidTokenAction: action,
+		},
+	}
+	verifier := jwtauth.NewVerifier()
+	verifier.AddHMACKey("", []byte("s3cr3t"))
+
+	return RateLimitMiddleware(coreLimiter, cfg, WithJWTVerifier(verifier))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func sendBearer(handler http.Handler, token string) int {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "192.168.1.1:12345"
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestRateLimitMiddleware_JWTPlan(t *testing.T) {
+	// Setup
+	handler := newJWTHandler(config.JWTInvalidFallback)
+	pro := signHS256(map[string]any{"sub": "u1", "tenant_id": "acme", "plan": "pro"}, "s3cr3t")
+	free := signHS256(map[string]any{"sub": "u2", "tenant_id": "acme", "plan": "free"}, "s3cr3t")
+
+	// Execute / Assert - o plano pro permite 3 requisições
+	for i := 0; i < 3; i++ {
+		if code := sendBearer(handler, pro); code != http.StatusOK {
+			t.Errorf("Requisição %d do plano pro deveria ser permitida, status %d", i+1, code)
+		}
+	}
+	if code := sendBearer(handler, pro); code != http.StatusTooManyRequests {
+		t.Errorf("Quarta requisição do plano pro deveria ser bloqueada, status %d", code)
+	}
+
+	// Plano sem limites configurados usa o limite padrão, com chave própria
+	if code := sendBearer(handler, free); code != http.StatusOK {
+		t.Errorf("Primeira requisição do plano free deveria ser permitida, status %d", code)
+	}
+	if code := sendBearer(handler, free); code != http.StatusTooManyRequests {
+		t.Errorf("Segunda requisição do plano free deveria ser bloqueada, status %d", code)
+	}
+}
+
+func TestRateLimitMiddleware_JWTInvalidFallback(t *testing.T) {
+	// Setup
+	handler := newJWTHandler(config.JWTInvalidFallback)
+	forged := signHS256(map[string]any{"sub": "u1", "tenant_id": "acme", "plan": "pro"}, "wrong")
+
+	// Execute / Assert - token inválido cai no limite por IP (1 requisição)
+	if code := sendBearer(handler, forged); code != http.StatusOK {
+		t.Errorf("Primeira requisição deveria ser permitida pelo limite por IP, status %d", code)
+	}
+	if code := sendBearer(handler, forged); code != http.StatusTooManyRequests {
+		t.Errorf("Segunda requisição deveria ser bloqueada pelo limite por IP, status %d", code)
+	}
+}
+
+func TestRateLimitMiddleware_JWTInvalidReject(t *testing.T) {
+	// Setup
+	handler := newJWTHandler(config.JWTInvalidReject)
+
+	// Execute / Assert
+	if code := sendBearer(handler, "not.a.jwt"); code != http.StatusUnauthorized {
+		t.Errorf("Token inválido deveria ser rejeitado com 401, status %d", code)
+	}
+	valid := signHS256(map[string]any{"sub": "u1", "tenant_id": "acme"}, "s3cr3t")
+	if code := sendBearer(handler, valid); code != http.StatusOK {
+		t.Errorf("Token válido deveria ser permitido, status %d", code)
+	}
+}
@@ -0,0 +1,146 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/marfebr/go_ratelimit/internal/config"
+)
+
+// KeyExtractor identifica o cliente de uma requisição para o rate limit
+// Retorna ok=false quando a requisição não possui essa identidade (ex.: header ausente)
+type KeyExtractor interface {
+	ExtractKey(r *http.Request) (key string, ok bool)
+}
+
+// KeyExtractorFunc adapta uma função ao KeyExtractor
+type KeyExtractorFunc func(r *http.Request) (string, bool)
+
+func (f KeyExtractorFunc) ExtractKey(r *http.Request) (string, bool) {
+	return f(r)
+}
+
+// HeaderKey extrai a chave do header informado
+func HeaderKey(name string) KeyExtractor {
+	return KeyExtractorFunc(func(r *http.Request) (string, bool) {
+		value := r.Header.Get(name)
+		return value, value != ""
+	})
+}
+
+// BearerKey extrai o token do header Authorization: Bearer <token>
+func BearerKey() KeyExtractor {
+	return KeyExtractorFunc(func(r *http.Request) (string, bool) {
+		scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+		token = strings.TrimSpace(token)
+		return token, ok && strings.EqualFold(scheme, "Bearer") && token != ""
+	})
+}
+
+// QueryKey extrai a chave do parâmetro de query informado
+func QueryKey(name string) KeyExtractor {
+	return KeyExtractorFunc(func(r *http.Request) (string, bool) {
+		value := r.URL.Query().Get(name)
+		return value, value != ""
+	})
+}
+
+// CookieKey extrai a chave do cookie informado
+func CookieKey(name string) KeyExtractor {
+	return KeyExtractorFunc(func(r *http.Request) (string, bool) {
+		cookie, err := r.Cookie(name)
+		if err != nil || cookie.Value == "" {
+			return "", false
+		}
+		return cookie.Value, true
+	})
+}
+
+// IPKey extrai o IP do cliente, respeitando proxies confiáveis e o agrupamento por prefixo
+func IPKey(cfg *config.Config) KeyExtractor {
+	return KeyExtractorFunc(func(r *http.Request) (string, bool) {
+		return aggregateIP(extractIP(r, cfg.TrustedProxies), cfg.IPv4PrefixLength, cfg.IPv6PrefixLength), true
+	})
+}
+
+// RouteKey extrai o caminho da requisição (ex.: para limitar por tenant e rota)
+func RouteKey() KeyExtractor {
+	return KeyExtractorFunc(func(r *http.Request) (string, bool) {
+		return r.URL.Path, true
+	})
+}
+
+// Prefixed acrescenta um prefixo às chaves extraídas (ex.: "tenant:")
+func Prefixed(prefix string, extractor KeyExtractor) KeyExtractor {
+	return KeyExtractorFunc(func(r *http.Request) (string, bool) {
+		key, ok := extractor.ExtractKey(r)
+		if !ok {
+			return "", false
+		}
+		return prefix + key, true
+	})
+}
+
+// Combine junta as chaves de todos os extratores com ":" (ex.: tenant e rota)
+// A requisição só possui a chave combinada se possuir todas as partes
+func Combine(extractors ...KeyExtractor) KeyExtractor {
+	return KeyExtractorFunc(func(r *http.Request) (string, bool) {
+		parts := make([]string, 0, len(extractors))
+		for _, extractor := range extractors {
+			key, ok := extractor.ExtractKey(r)
+			if !ok {
+				return "", false
+			}
+			parts = append(parts, key)
+		}
+		return strings.Join(parts, ":"), len(parts) > 0
+	})
+}
+
+// FirstOf usa o primeiro extrator que identificar a requisição
+func FirstOf(extractors ...KeyExtractor) KeyExtractor {
+	return KeyExtractorFunc(func(r *http.Request) (string, bool) {
+		for _, extractor := range extractors {
+			if key, ok := extractor.ExtractKey(r); ok {
+				return key, true
+			}
+		}
+		return "", false
+	})
+}
+
+// NewKeyExtractor monta o extrator descrito na configuração
+// Com labeled, cada parte recebe o tipo da fonte como prefixo (ex.: ip:1.2.3.4,
+// header:acme:route:/api), evitando colisões entre chaves de fontes diferentes.
+func NewKeyExtractor(spec config.KeySpec, cfg *config.Config, labeled bool) KeyExtractor {
+	alternatives := make([]KeyExtractor, len(spec))
+	for i, sources := range spec {
+		parts := make([]KeyExtractor, len(sources))
+		for j, source := range sources {
+			parts[j] = sourceExtractor(source, cfg)
+			if labeled {
+				parts[j] = Prefixed(source.Kind+":", parts[j])
+			}
+		}
+		alternatives[i] = Combine(parts...)
+	}
+	return FirstOf(alternatives...)
+}
+
+// sourceExtractor retorna o extrator embutido de uma fonte da configuração
+func sourceExtractor(source config.KeySource, cfg *config.Config) KeyExtractor {
+	switch source.Kind {
+	case "header":
+		return HeaderKey(source.Name)
+	case "query":
+		return QueryKey(source.Name)
+	case "cookie":
+		return CookieKey(source.Name)
+	case "bearer":
+		return BearerKey()
+	case "route":
+		return RouteKey()
+	default:
+		return IPKey(cfg)
+	}
+}
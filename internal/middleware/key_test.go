@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/marfebr/go_ratelimit/internal/config"
+	"github.com/marfebr/go_ratelimit/internal/limiter"
+)
+
+func TestKeyExtractors(t *testing.T) {
+	// Setup
+	req := httptest.NewRequest("GET", "/api/orders?api_key=q123", nil)
+	req.RemoteAddr = "203.0.113.7:4000"
+	req.Header.Set("Authorization", "Bearer b456")
+	req.Header.Set("X-Tenant-ID", "acme")
+	req.AddCookie(&http.Cookie{Name: "session", Value: "c789"})
+
+	tests := []struct {
+		name      string
+		extractor KeyExtractor
+		want      string
+		wantOK    bool
+	}{
+		{"header", HeaderKey("X-Tenant-ID"), "acme", true},
+		{"header ausente", HeaderKey("X-Missing"), "", false},
+		{"bearer", BearerKey(), "b456", true},
+		{"query", QueryKey("api_key"), "q123", true},
+		{"cookie", CookieKey("session"), "c789", true},
+		{"cookie ausente", CookieKey("other"), "", false},
+		{"ip", IPKey(&config.Config{}), "203.0.113.7", true},
+		{"rota", RouteKey(), "/api/orders", true},
+		{"prefixo", Prefixed("tenant:", HeaderKey("X-Tenant-ID")), "tenant:acme", true},
+		{"combinação", Combine(HeaderKey("X-Tenant-ID"), RouteKey()), "acme:/api/orders", true},
+		{"combinação incompleta", Combine(HeaderKey("X-Missing"), RouteKey()), "", false},
+		{"primeira presente", FirstOf(HeaderKey("X-Missing"), BearerKey()), "b456", true},
+	}
+
+	for _, tt := range tests {
+		// Execute
+		key, ok := tt.extractor.ExtractKey(req)
+
+		// Assert
+		if key != tt.want || ok != tt.wantOK {
+			t.Errorf("%s: esperado (%q, %v), obtido (%q, %v)", tt.name, tt.want, tt.wantOK, key, ok)
+		}
+	}
+}
+
+func TestNewKeyExtractor(t *testing.T) {
+	// Setup
+	spec, err := config.ParseKeySpec("header:X-Tenant-ID+route|ip")
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	extractor := NewKeyExtractor(spec, &config.Config{}, true)
+
+	withTenant := httptest.NewRequest("GET", "/api/orders", nil)
+	withTenant.Header.Set("X-Tenant-ID", "acme")
+	withoutTenant := httptest.NewRequest("GET", "/api/orders", nil)
+	withoutTenant.RemoteAddr = "203.0.113.7:4000"
+
+	// Execute / Assert
+	if key, _ := extractor.ExtractKey(withTenant); key != "header:acme:route:/api/orders" {
+		t.Errorf("Chave com tenant inesperada: %q", key)
+	}
+	if key, _ := extractor.ExtractKey(withoutTenant); key != "ip:203.0.113.7" {
+		t.Errorf("Chave sem tenant deveria usar o IP, obtida %q", key)
+	}
+}
+
+func TestRateLimitMiddleware_CustomExtractors(t *testing.T) {
+	// Setup - token via Authorization: Bearer e demais clientes por tenant
+	coreLimiter := limiter.NewCoreLimiter(newMockStore())
+	cfg := &config.Config{
+		DefaultRateLimitIP:     1,
+		DefaultBlockDurationIP: 300,
+		TokenLimits: map[string]config.TokenLimit{
+			"premium": {Limit: 2, BlockDurationSecs: 300},
+		},
+	}
+	handler := RateLimitMiddleware(coreLimiter, cfg,
+		WithTokenExtractor(BearerKey()),
+		WithKeyExtractor(Prefixed("tenant:", HeaderKey("X-Tenant-ID"))),
+	)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	send := func(headers map[string]string) int {
+		req := httptest.NewRequest("GET", "/", nil)
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// Execute / Assert - o token usa o próprio limite
+	for i := 0; i < 2; i++ {
+		if code := send(map[string]string{"Authorization": "Bearer premium"}); code != http.StatusOK {
+			t.Errorf("Requisição %d do token deveria ser permitida, status %d", i+1, code)
+		}
+	}
+
+	// Cada tenant tem limite próprio, mesmo vindo do mesmo IP
+	if code := send(map[string]string{"X-Tenant-ID": "acme"}); code != http.StatusOK {
+		t.Errorf("Primeira requisição do tenant acme deveria ser permitida, status %d", code)
+	}
+	if code := send(map[string]string{"X-Tenant-ID": "acme"}); code != http.StatusTooManyRequests {
+		t.Errorf("Segunda requisição do tenant acme deveria ser bloqueada, status %d", code)
+	}
+	if code := send(map[string]string{"X-Tenant-ID": "globex"}); code != http.StatusOK {
+		t.Errorf("Tenant globex deveria ter limite próprio, status %d", code)
+	}
+}
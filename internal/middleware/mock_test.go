@@ -25,6 +25,10 @@ func newMockStore() *mockStore {
 }
 
 func (m *mockStore) Increment(ctx context.Context, key string, expiry time.Duration) (int64, error) {
+	return m.IncrementBy(ctx, key, 1, expiry)
+}
+
+func (m *mockStore) IncrementBy(ctx context.Context, key string, n int64, expiry time.Duration) (int64, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -39,7 +43,7 @@ func (m *mockStore) Increment(ctx context.Context, key string, expiry time.Durat
 		}
 	}
 
-	m.counters[key]++
+	m.counters[key] += n
 	m.expiries[key] = time.Now().Add(expiry)
 	return m.counters[key], nil
 }
@@ -92,3 +96,35 @@ func (m *mockStore) SetExpiring(ctx context.Context, key string, _ string, expir
 	m.expiries[key] = time.Now().Add(expiry)
 	return nil
 }
+
+func (m *mockStore) Delete(ctx context.Context, keys ...string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.shouldFail {
+		return 0, fmt.Errorf("mock error")
+	}
+	var deleted int64
+	for _, key := range keys {
+		_, counted := m.counters[key]
+		_, expiring := m.expiries[key]
+		if counted || expiring {
+			deleted++
+		}
+		delete(m.counters, key)
+		delete(m.expiries, key)
+	}
+	return deleted, nil
+}
+
+func (m *mockStore) TTL(ctx context.Context, key string) (time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.shouldFail {
+		return 0, fmt.Errorf("mock error")
+	}
+	exp, ok := m.expiries[key]
+	if !ok || time.Now().After(exp) {
+		return 0, nil
+	}
+	return time.Until(exp), nil
+}
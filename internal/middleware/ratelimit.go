@@ -2,90 +2,358 @@ package middleware
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/marfebr/go_ratelimit/internal/config"
+	"github.com/marfebr/go_ratelimit/internal/jwtauth"
 	"github.com/marfebr/go_ratelimit/internal/limiter"
 )
 
+// Option configura recursos opcionais do middleware
+type Option func(*options)
+
+type options struct {
+	quotaManager   *limiter.QuotaManager
+	tokenExtractor KeyExtractor
+	keyExtractor   KeyExtractor
+	jwtVerifier    *jwtauth.Verifier
+	configSource   func() *config.Config
+	tokenRegistry  TokenRegistry
+}
+
+// WithQuotaManager habilita as cotas de longo prazo dos tokens (opção quota=)
+func WithQuotaManager(quotaManager *limiter.QuotaManager) Option {
+	return func(o *options) {
+		o.quotaManager = quotaManager
+	}
+}
+
+// WithTokenExtractor define de onde vem o token procurado nos limites por token
+// (padrão: TOKEN_KEY_SOURCE ou o header API_KEY)
+func WithTokenExtractor(extractor KeyExtractor) Option {
+	return func(o *options) {
+		o.tokenExtractor = extractor
+	}
+}
+
+// WithKeyExtractor define a chave dos clientes sujeitos aos limites padrão
+// (padrão: DEFAULT_KEY_SOURCE ou o IP do cliente). Requisições que o extrator
+// não identifica são limitadas por IP.
+func WithKeyExtractor(extractor KeyExtractor) Option {
+	return func(o *options) {
+		o.keyExtractor = extractor
+	}
+}
+
+// TokenRegistry fornece limites por token definidos em tempo de execução
+// (ex.: registry.Registry), consultados antes dos tokens da configuração
+type TokenRegistry interface {
+	Get(token string) (config.TokenLimit, bool)
+}
+
+// WithTokenRegistry consulta o registro dinâmico de tokens antes de API_KEY_<TOKEN>
+func WithTokenRegistry(tokenRegistry TokenRegistry) Option {
+	return func(o *options) {
+		o.tokenRegistry = tokenRegistry
+	}
+}
+
+// WithConfigSource faz o middleware consultar a configuração a cada requisição
+// (ex.: config.Reloader.Current), aplicando recargas sem reiniciar o servidor
+func WithConfigSource(source func() *config.Config) Option {
+	return func(o *options) {
+		o.configSource = source
+	}
+}
+
+// activeConfig é uma configuração com os extratores e rotas derivados dela,
+// reconstruídos quando a configuração é recarregada
+type activeConfig struct {
+	cfg            *config.Config
+	tokenExtractor KeyExtractor
+	keyExtractor   KeyExtractor
+	routes         routeTable
+	access         accessLists
+}
+
+func newActiveConfig(cfg *config.Config, o *options) *activeConfig {
+	active := &activeConfig{
+		cfg:            cfg,
+		tokenExtractor: o.tokenExtractor,
+		keyExtractor:   o.keyExtractor,
+		routes:         newRouteTable(cfg),
+		access:         newAccessLists(cfg),
+	}
+	if active.tokenExtractor == nil {
+		active.tokenExtractor = HeaderKey("API_KEY")
+		if len(cfg.TokenKeySource) > 0 {
+			active.tokenExtractor = NewKeyExtractor(cfg.TokenKeySource, cfg, false)
+		}
+	}
+	if active.keyExtractor == nil {
+		active.keyExtractor = Prefixed("ip:", IPKey(cfg))
+		if len(cfg.DefaultKeySource) > 0 {
+			active.keyExtractor = NewKeyExtractor(cfg.DefaultKeySource, cfg, true)
+		}
+	}
+	return active
+}
+
 // RateLimitMiddleware cria um middleware de rate limiting
-func RateLimitMiddleware(coreLimiter *limiter.CoreLimiter, cfg *config.Config) func(http.Handler) http.Handler {
+func RateLimitMiddleware(coreLimiter *limiter.CoreLimiter, cfg *config.Config, opts ...Option) func(http.Handler) http.Handler {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.configSource == nil {
+		o.configSource = func() *config.Config { return cfg }
+	}
+
+	var active atomic.Pointer[activeConfig]
+	active.Store(newActiveConfig(o.configSource(), o))
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			ctx := context.Background()
 
-			// Extrai token do header API_KEY
-			apiKey := r.Header.Get("API_KEY")
-			
+			// Reconstrói extratores e rotas quando a configuração foi recarregada
+			current := active.Load()
+			if latest := o.configSource(); latest != current.cfg {
+				current = newActiveConfig(latest, o)
+				active.Store(current)
+			}
+			cfg, routes := current.cfg, current.routes
+
+			// Listas de bloqueio e permissão, avaliadas antes de consultar o store
+			switch current.access.check(r, cfg, current.tokenExtractor) {
+			case accessDenied:
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusForbidden)
+				w.Write([]byte(`{"message": "access denied"}`))
+				return
+			case accessAllowed:
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Rotas excluídas (ex.: health check) não passam pelo rate limiting
+			if routes.isExcluded(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
 			var key string
-			var limit int
-			var blockDuration time.Duration
-
-			// Prioridade: Token > IP
-			if apiKey != "" {
-				// Verifica se existe configuração para este token
-				if tokenLimit, exists := cfg.GetTokenLimit(apiKey); exists {
-					key = "token:" + apiKey
-					limit = tokenLimit.Limit
-					blockDuration = time.Duration(tokenLimit.BlockDurationSecs) * time.Second
-				} else {
-					// Token não configurado, usa limite de IP
-					key = "ip:" + extractIP(r)
-					limit = cfg.DefaultRateLimitIP
-					blockDuration = time.Duration(cfg.DefaultBlockDurationIP) * time.Second
+			var rules []limiter.Rule
+			var quota config.Quota
+
+			// Rotas com limite próprio usam apenas a regra da rota
+			// Demais: Token configurado > JWT válido > chave padrão (IP)
+			if route, ok := routes.find(r); ok {
+				extractor := route.extractor
+				if extractor == nil {
+					extractor = current.keyExtractor
+				}
+				clientKey, ok := extractor.ExtractKey(r)
+				if !ok {
+					clientKey = ipKey(r, cfg)
+				}
+				key = routeKey(route.limit.RoutePattern, clientKey)
+				rules = tokenRules(cfg, route.limit.Limits)
+				quota = route.limit.Limits.Quota
+			} else if token, ok := current.tokenExtractor.ExtractKey(r); ok {
+				if tokenLimit, exists := lookupToken(cfg, o.tokenRegistry, token); exists {
+					key = "token:" + token
+					rules = tokenRules(cfg, tokenLimit)
+					quota = tokenLimit.Quota
+				}
+			}
+			if key == "" && o.jwtVerifier != nil {
+				identity, ok, err := identifyJWT(r, o.jwtVerifier, cfg)
+				if err != nil && cfg.JWT.InvalidTokenAction == config.JWTInvalidReject {
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(http.StatusUnauthorized)
+					w.Write([]byte(`{"message": "invalid token"}`))
+					return
+				}
+				// Token inválido no modo fallback: segue para o limite padrão
+				if ok {
+					key, rules, quota = identity.key, identity.rules, identity.quota
+				}
+			}
+			if key == "" {
+				// Token ausente ou não configurado, usa os limites padrão
+				defaultKey, ok := current.keyExtractor.ExtractKey(r)
+				if !ok {
+					defaultKey = ipKey(r, cfg)
 				}
-			} else {
-				// Sem token, usa limite de IP
-				key = "ip:" + extractIP(r)
-				limit = cfg.DefaultRateLimitIP
-				blockDuration = time.Duration(cfg.DefaultBlockDurationIP) * time.Second
+				key = defaultKey
+				rules = ipRules(cfg)
 			}
 
-			// Verifica rate limit
-			status, err := coreLimiter.Allow(ctx, key, limit, blockDuration)
-			if err != nil {
-				// Fail-open: em caso de erro, permite requisição
-				next.ServeHTTP(w, r)
+			// Verifica rate limit consumindo o custo da requisição
+			cost := requestCost(r, cfg.RouteCosts, next)
+			status, err := coreLimiter.AllowRulesN(ctx, key, rules, cost)
+			if errors.Is(err, limiter.ErrCostExceedsLimit) {
+				// Nunca caberia no limite: negada sem consumir capacidade nem bloquear a chave
+				w.Header().Set("X-RateLimit-Tier", status.Rule.String())
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusTooManyRequests)
+				w.Write([]byte(`{"message": "the request cost exceeds the rate limit"}`))
 				return
 			}
+			if err != nil {
+				// O resultado com o store indisponível segue a política de falha do limiter
+				switch status.Failure {
+				case limiter.FailClosed:
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(http.StatusServiceUnavailable)
+					w.Write([]byte(`{"message": "rate limiter unavailable"}`))
+					return
+				case limiter.FailLocal:
+					// Segue com o resultado do limiter local
+				default:
+					// Fail-open: em caso de erro, permite requisição
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			// Headers de limite, restante e reinício em respostas permitidas e negadas
+			writeLimitHeaders(w, cfg.RateLimitHeaders, rules, limitStateOf(ctx, coreLimiter, cfg.RateLimitHeaders, key, status), status.Allowed)
 
 			// Se bloqueado, retorna 429
 			if !status.Allowed {
+				// Informa qual dos limites simultâneos foi excedido
+				w.Header().Set("X-RateLimit-Tier", status.Rule.String())
 				w.Header().Set("Content-Type", "application/json")
 				w.WriteHeader(http.StatusTooManyRequests)
 				w.Write([]byte(`{"message": "you have reached the maximum number of requests or actions allowed within a certain time frame"}`))
 				return
 			}
 
+			// Consome a cota de longo prazo do token, se configurada
+			if o.quotaManager != nil && quota.Limit > 0 {
+				quotaStatus, err := o.quotaManager.Consume(ctx, key, limiter.Quota{
+					Limit:  quota.Limit,
+					Period: limiter.QuotaPeriod(quota.Period),
+				})
+				// Com o store indisponível, o resultado segue a política de falha do QuotaManager
+				if err != nil && quotaStatus.Failure == limiter.FailClosed {
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(http.StatusServiceUnavailable)
+					w.Write([]byte(`{"message": "rate limiter unavailable"}`))
+					return
+				}
+				if err == nil {
+					w.Header().Set("X-Quota-Limit", strconv.FormatInt(quotaStatus.Limit, 10))
+					w.Header().Set("X-Quota-Remaining", strconv.FormatInt(quotaStatus.Remaining, 10))
+					w.Header().Set("X-Quota-Reset", strconv.FormatInt(quotaStatus.ResetAt.Unix(), 10))
+				}
+				if !quotaStatus.Allowed {
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(cfg.QuotaExceededStatus)
+					w.Write([]byte(`{"message": "you have exhausted your request quota for the current period"}`))
+					return
+				}
+			}
+
 			// Permite requisição
 			next.ServeHTTP(w, r)
 		})
 	}
 }
 
-// extractIP extrai o endereço IP da requisição
-func extractIP(r *http.Request) string {
-	// Verifica header X-Forwarded-For (comum em reverse proxies)
-	forwarded := r.Header.Get("X-Forwarded-For")
-	if forwarded != "" {
-		// Pega o primeiro IP da lista
-		ips := strings.Split(forwarded, ",")
-		return strings.TrimSpace(ips[0])
+// ErrUnresolvedKey indica uma chave cujas regras não podem ser deduzidas do nome
+var ErrUnresolvedKey = errors.New("regras da chave não podem ser determinadas")
+
+// KeyRules retorna as regras aplicadas a uma chave de rate limit (route:<rota>:<cliente>, token:<token> ou ip:<ip>)
+// Tokens sem configuração usam as regras padrão por IP, como no middleware; tokenRegistry pode ser nil.
+// Com planos configurados, chaves jwt: retornam ErrUnresolvedKey: o plano vem da claim do token, e não da chave.
+func KeyRules(cfg *config.Config, tokenRegistry TokenRegistry, key string) ([]limiter.Rule, error) {
+	for _, route := range cfg.RouteLimits {
+		if strings.HasPrefix(key, routeKey(route.RoutePattern, "")) {
+			return tokenRules(cfg, route.Limits), nil
+		}
+	}
+	if token, ok := strings.CutPrefix(key, "token:"); ok {
+		if tokenLimit, exists := lookupToken(cfg, tokenRegistry, token); exists {
+			return tokenRules(cfg, tokenLimit), nil
+		}
+	}
+	if strings.HasPrefix(key, "jwt:") && (len(cfg.JWT.Plans) > 0 || len(cfg.Plans) > 0) {
+		return nil, fmt.Errorf("%w: %s usa o plano da claim %s do token", ErrUnresolvedKey, key, cfg.JWT.PlanClaim)
+	}
+	return ipRules(cfg), nil
+}
+
+// KeyStateRules retorna as regras cujo estado deve ser zerado no reinício da chave.
+// Para chaves jwt: são as regras de todos os planos e as padrão, já que o plano não faz parte da chave.
+func KeyStateRules(cfg *config.Config, tokenRegistry TokenRegistry, key string) []limiter.Rule {
+	if rules, err := KeyRules(cfg, tokenRegistry, key); err == nil {
+		return rules
+	}
+	rules := ipRules(cfg)
+	for _, planLimit := range cfg.JWT.Plans {
+		rules = append(rules, tokenRules(cfg, planLimit)...)
 	}
+	for name := range cfg.Plans {
+		if planLimit, exists := cfg.ResolvePlan(name, config.TokenOverrides{}); exists {
+			rules = append(rules, tokenRules(cfg, planLimit)...)
+		}
+	}
+	return rules
+}
 
-	// Verifica X-Real-IP
-	realIP := r.Header.Get("X-Real-IP")
-	if realIP != "" {
-		return realIP
+// lookupToken procura o token no registro dinâmico e depois na configuração
+// Planos referenciados pelo registro são resolvidos pela configuração ativa
+func lookupToken(cfg *config.Config, tokenRegistry TokenRegistry, token string) (config.TokenLimit, bool) {
+	if tokenRegistry != nil {
+		if tokenLimit, exists := tokenRegistry.Get(token); exists {
+			if tokenLimit.Plan == "" {
+				return tokenLimit, true
+			}
+			return cfg.ResolvePlan(tokenLimit.Plan, tokenLimit.Overrides)
+		}
 	}
+	return cfg.GetTokenLimit(token)
+}
+
+// tokenRules converte os limites configurados de um token em regras do limiter
+func tokenRules(cfg *config.Config, tokenLimit config.TokenLimit) []limiter.Rule {
+	escalation := limiter.Escalation{Steps: tokenLimit.Escalation, Lookback: cfg.EscalationLookback}
+	return buildRules(tokenLimit.Rates(), tokenLimit.Algorithm, tokenLimit.Burst, tokenLimit.BlockDurationSecs, escalation)
+}
+
+// ipRules retorna as regras padrão aplicadas por IP
+func ipRules(cfg *config.Config) []limiter.Rule {
+	escalation := limiter.Escalation{Steps: cfg.DefaultBlockEscalationIP, Lookback: cfg.EscalationLookback}
+	return buildRules(cfg.DefaultRatesIP(), cfg.DefaultAlgorithmIP, cfg.DefaultBurstIP, cfg.DefaultBlockDurationIP, escalation)
+}
 
-	// Fallback para RemoteAddr
-	ip := r.RemoteAddr
-	// Remove porta se presente
-	if idx := strings.LastIndex(ip, ":"); idx != -1 {
-		ip = ip[:idx]
+// buildRules cria uma regra por limite, compartilhando algoritmo, bloqueio e escalonamento
+// A rajada configurada vale para o limite principal; os adicionais usam o próprio limite
+func buildRules(rates []config.Rate, algorithm string, burst, blockDurationSecs int, escalation limiter.Escalation) []limiter.Rule {
+	rules := make([]limiter.Rule, len(rates))
+	for i, rate := range rates {
+		rules[i] = limiter.Rule{
+			Algorithm:     limiter.Algorithm(algorithm),
+			Limit:         rate.Limit,
+			Window:        rate.Window,
+			BlockDuration: time.Duration(blockDurationSecs) * time.Second,
+			Escalation:    escalation,
+		}
 	}
-	return ip
+	rules[0].Burst = burst
+	return rules
+}
+
+// ipKey retorna a chave de rate limit do cliente, agrupada pelo prefixo de rede configurado
+func ipKey(r *http.Request, cfg *config.Config) string {
+	return "ip:" + aggregateIP(extractIP(r, cfg.TrustedProxies), cfg.IPv4PrefixLength, cfg.IPv6PrefixLength)
 }
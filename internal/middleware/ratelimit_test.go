@@ -1,9 +1,13 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/marfebr/go_ratelimit/internal/config"
 	"github.com/marfebr/go_ratelimit/internal/limiter"
@@ -163,51 +167,474 @@ func TestRateLimitMiddleware_UnconfiguredToken(t *testing.T) {
 	}
 }
 
-func TestExtractIP_RemoteAddr(t *testing.T) {
+func TestRateLimitMiddleware_TokenBucket(t *testing.T) {
+	// Setup - token bucket exige store com suporte ao algoritmo
+	store := limiter.NewMemoryStore()
+	coreLimiter := limiter.NewCoreLimiter(store)
+	defer coreLimiter.Close()
+	cfg := &config.Config{
+		DefaultRateLimitIP:     1,
+		DefaultBlockDurationIP: 0,
+		DefaultAlgorithmIP:     "token_bucket",
+		DefaultBurstIP:         4,
+		TokenLimits:            make(map[string]config.TokenLimit),
+	}
+
+	handler := RateLimitMiddleware(coreLimiter, cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	// Execute - rajada de 4 permitida apesar do limite de 1 req/s
+	for i := 0; i < 4; i++ {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = "192.168.1.6:12345"
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Errorf("Requisição %d da rajada deveria ser permitida, status %d", i+1, w.Code)
+		}
+	}
+
+	// Assert - bucket vazio
 	req := httptest.NewRequest("GET", "/", nil)
-	req.RemoteAddr = "192.168.1.100:54321"
+	req.RemoteAddr = "192.168.1.6:12345"
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusTooManyRequests {
+		t.Errorf("Requisição além da capacidade deveria ser bloqueada, status %d", w.Code)
+	}
+}
 
-	ip := extractIP(req)
+func TestRateLimitMiddleware_ReportsTrippedTier(t *testing.T) {
+	// Setup
+	store := limiter.NewMemoryStore()
+	coreLimiter := limiter.NewCoreLimiter(store)
+	defer coreLimiter.Close()
+	cfg := &config.Config{
+		DefaultRateLimitIP:     10,
+		DefaultWindowIP:        time.Second,
+		DefaultBlockDurationIP: 0,
+		TokenLimits: map[string]config.TokenLimit{
+			"tiered": {
+				Limit:  10,
+				Window: time.Second,
+				Tiers:  []config.Rate{{Limit: 2, Window: time.Minute}},
+			},
+		},
+	}
+
+	handler := RateLimitMiddleware(coreLimiter, cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	var w *httptest.ResponseRecorder
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("API_KEY", "tiered")
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+	}
 
-	if ip != "192.168.1.100" {
-		t.Errorf("IP esperado '192.168.1.100', obtido '%s'", ip)
+	// Assert - a 3ª requisição excede o limite por minuto
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Status esperado 429, obtido %d", w.Code)
+	}
+	if tier := w.Header().Get("X-RateLimit-Tier"); tier != "2/1m" {
+		t.Errorf("X-RateLimit-Tier esperado '2/1m', obtido '%s'", tier)
 	}
 }
 
-func TestExtractIP_XForwardedFor(t *testing.T) {
-	req := httptest.NewRequest("GET", "/", nil)
-	req.RemoteAddr = "10.0.0.1:12345"
-	req.Header.Set("X-Forwarded-For", "203.0.113.1, 198.51.100.1")
+func TestRateLimitMiddleware_QuotaExceeded(t *testing.T) {
+	// Setup
+	store := limiter.NewMemoryStore()
+	coreLimiter := limiter.NewCoreLimiter(store)
+	defer coreLimiter.Close()
+	cfg := &config.Config{
+		DefaultRateLimitIP:  10,
+		QuotaExceededStatus: http.StatusForbidden,
+		TokenLimits: map[string]config.TokenLimit{
+			"billing": {
+				Limit:  100,
+				Window: time.Second,
+				Quota:  config.Quota{Limit: 2, Period: "month"},
+			},
+		},
+	}
+
+	handler := RateLimitMiddleware(coreLimiter, cfg, WithQuotaManager(limiter.NewQuotaManager(store, time.UTC)))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
 
-	ip := extractIP(req)
+	// Execute
+	var codes []int
+	var w *httptest.ResponseRecorder
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("API_KEY", "billing")
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		codes = append(codes, w.Code)
+	}
 
-	if ip != "203.0.113.1" {
-		t.Errorf("IP esperado '203.0.113.1', obtido '%s'", ip)
+	// Assert - a 3ª requisição esgota a cota mensal
+	if codes[0] != http.StatusOK || codes[1] != http.StatusOK {
+		t.Errorf("Requisições dentro da cota deveriam ser permitidas, obtido %v", codes)
+	}
+	if codes[2] != http.StatusForbidden {
+		t.Errorf("Status esperado 403 com a cota esgotada, obtido %d", codes[2])
+	}
+	if remaining := w.Header().Get("X-Quota-Remaining"); remaining != "0" {
+		t.Errorf("X-Quota-Remaining esperado '0', obtido '%s'", remaining)
+	}
+	if w.Header().Get("X-Quota-Reset") == "" {
+		t.Error("X-Quota-Reset deveria estar presente")
 	}
 }
 
-func TestExtractIP_XRealIP(t *testing.T) {
-	req := httptest.NewRequest("GET", "/", nil)
-	req.RemoteAddr = "10.0.0.1:12345"
-	req.Header.Set("X-Real-IP", "203.0.113.2")
+func TestRateLimitMiddleware_QuotaStoreFailure(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy limiter.FailurePolicy
+		want   int
+	}{
+		{"open", limiter.FailOpen, http.StatusOK},
+		{"closed", limiter.FailClosed, http.StatusServiceUnavailable},
+	}
 
-	ip := extractIP(req)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup - apenas o store das cotas está indisponível
+			coreLimiter := limiter.NewCoreLimiter(limiter.NewMemoryStore())
+			defer coreLimiter.Close()
+			quotaStore := newMockStore()
+			quotaStore.shouldFail = true
+			quotaManager := limiter.NewQuotaManager(quotaStore, time.UTC)
+			if err := quotaManager.SetFailurePolicy(tt.policy, 50); err != nil {
+				t.Fatalf("Erro inesperado: %v", err)
+			}
+			cfg := &config.Config{
+				DefaultRateLimitIP:  10,
+				QuotaExceededStatus: http.StatusForbidden,
+				TokenLimits: map[string]config.TokenLimit{
+					"billing": {Limit: 100, Window: time.Second, Quota: config.Quota{Limit: 2, Period: "month"}},
+				},
+			}
+			handler := RateLimitMiddleware(coreLimiter, cfg, WithQuotaManager(quotaManager))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			// Execute
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("API_KEY", "billing")
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			// Assert
+			if w.Code != tt.want {
+				t.Errorf("Status esperado %d, obtido %d", tt.want, w.Code)
+			}
+		})
+	}
+}
+
+func TestKeyRules(t *testing.T) {
+	// Setup
+	cfg := &config.Config{
+		DefaultRateLimitIP:     10,
+		DefaultBlockDurationIP: 300,
+		TokenLimits: map[string]config.TokenLimit{
+			"abc123": {Limit: 100, Window: time.Minute, BlockDurationSecs: 60},
+		},
+	}
 
-	if ip != "203.0.113.2" {
-		t.Errorf("IP esperado '203.0.113.2', obtido '%s'", ip)
+	// Execute / Assert
+	if rules, _ := KeyRules(cfg, nil, "token:abc123"); len(rules) != 1 || rules[0].Limit != 100 || rules[0].Window != time.Minute {
+		t.Errorf("Regras do token inesperadas: %+v", rules)
+	}
+	if rules, _ := KeyRules(cfg, nil, "token:unknown"); len(rules) != 1 || rules[0].Limit != 10 {
+		t.Errorf("Token sem configuração deveria usar as regras por IP: %+v", rules)
+	}
+	if rules, _ := KeyRules(cfg, nil, "ip:1.2.3.4"); len(rules) != 1 || rules[0].Limit != 10 {
+		t.Errorf("Regras por IP inesperadas: %+v", rules)
+	}
+	// Sem planos, chaves jwt: sempre usam as regras padrão
+	if rules, err := KeyRules(cfg, nil, "jwt:alice"); err != nil || len(rules) != 1 || rules[0].Limit != 10 {
+		t.Errorf("Regras da chave jwt: sem planos inesperadas: %+v (%v)", rules, err)
 	}
 }
 
-func TestExtractIP_Priority(t *testing.T) {
-	// X-Forwarded-For tem prioridade sobre X-Real-IP
-	req := httptest.NewRequest("GET", "/", nil)
-	req.RemoteAddr = "10.0.0.1:12345"
-	req.Header.Set("X-Forwarded-For", "203.0.113.1")
-	req.Header.Set("X-Real-IP", "203.0.113.2")
+func TestKeyRules_JWTPlans(t *testing.T) {
+	// Setup
+	cfg := &config.Config{
+		DefaultRateLimitIP:     5,
+		DefaultBlockDurationIP: 300,
+		Plans: map[string]config.TokenLimit{
+			"free": {Limit: 10, Window: time.Minute, BlockDurationSecs: 60},
+		},
+		JWT: config.JWTConfig{
+			PlanClaim: "plan",
+			Plans: map[string]config.TokenLimit{
+				"pro": {Limit: 1000, Window: time.Hour, BlockDurationSecs: 60},
+			},
+		},
+	}
+
+	// Execute
+	rules, err := KeyRules(cfg, nil, "jwt:alice")
+	stateRules := KeyStateRules(cfg, nil, "jwt:alice")
+
+	// Assert - o plano vem do token: sem as regras por IP no lugar das do plano
+	if !errors.Is(err, ErrUnresolvedKey) || rules != nil {
+		t.Errorf("Esperado ErrUnresolvedKey para chave jwt: com planos, obtido %+v (%v)", rules, err)
+	}
+	limits := map[int]bool{}
+	for _, rule := range stateRules {
+		limits[rule.Limit] = true
+	}
+	if len(stateRules) != 3 || !limits[5] || !limits[10] || !limits[1000] {
+		t.Errorf("O reinício deveria incluir as regras padrão e de todos os planos: %+v", stateRules)
+	}
+	// Demais chaves continuam resolvidas pelo nome
+	if rules := KeyStateRules(cfg, nil, "ip:1.2.3.4"); len(rules) != 1 || rules[0].Limit != 5 {
+		t.Errorf("Regras por IP inesperadas: %+v", rules)
+	}
+}
+
+func TestRateLimitMiddleware_TokenPlan(t *testing.T) {
+	// Setup - dois tokens do mesmo plano, um deles com limite redefinido
+	coreLimiter := limiter.NewCoreLimiter(newMockStore())
+	cfg := &config.Config{
+		DefaultRateLimitIP:     1,
+		DefaultBlockDurationIP: 300,
+		Plans: map[string]config.TokenLimit{
+			"pro": {Limit: 2, BlockDurationSecs: 300},
+		},
+		TokenLimits: map[string]config.TokenLimit{
+			"basic":  {Plan: "pro"},
+			"custom": {Plan: "pro", Overrides: config.TokenOverrides{Rates: []config.Rate{{Limit: 3}}}},
+		},
+	}
+	handler := RateLimitMiddleware(coreLimiter, cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	allowed := func(token string) int {
+		count := 0
+		for i := 0; i < 5; i++ {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("API_KEY", token)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			if w.Code == http.StatusOK {
+				count++
+			}
+		}
+		return count
+	}
+
+	// Execute / Assert
+	if got := allowed("basic"); got != 2 {
+		t.Errorf("Token do plano pro deveria permitir 2 requisições, permitiu %d", got)
+	}
+	if got := allowed("custom"); got != 3 {
+		t.Errorf("Token com redefinição deveria permitir 3 requisições, permitiu %d", got)
+	}
+}
+
+func TestRateLimitMiddleware_ConfigSource(t *testing.T) {
+	// Setup
+	coreLimiter := limiter.NewCoreLimiter(newMockStore())
+	cfg := &config.Config{
+		DefaultRateLimitIP:     1,
+		DefaultBlockDurationIP: 0,
+		TokenLimits:            make(map[string]config.TokenLimit),
+	}
+	var current atomic.Pointer[config.Config]
+	current.Store(cfg)
+
+	handler := RateLimitMiddleware(coreLimiter, cfg, WithConfigSource(current.Load))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	send := func() int {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("API_KEY", "abc123")
+		req.RemoteAddr = "192.168.1.50:12345"
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// Execute: o token ainda não está configurado e usa o limite por IP
+	send()
+	beforeReload := send()
+
+	// Recarga adiciona o token
+	reloaded := *cfg
+	reloaded.TokenLimits = map[string]config.TokenLimit{"abc123": {Limit: 10, BlockDurationSecs: 0}}
+	current.Store(&reloaded)
+	afterReload := send()
+
+	// Assert
+	if beforeReload != http.StatusTooManyRequests {
+		t.Errorf("Status esperado 429 antes da recarga, obtido %d", beforeReload)
+	}
+	if afterReload != http.StatusOK {
+		t.Errorf("Status esperado 200 após a recarga, obtido %d", afterReload)
+	}
+}
+
+// staticRegistry é um registro de tokens fixo para testes
+type staticRegistry map[string]config.TokenLimit
 
-	ip := extractIP(req)
+func (s staticRegistry) Get(token string) (config.TokenLimit, bool) {
+	limit, exists := s[token]
+	return limit, exists
+}
+
+func TestRateLimitMiddleware_TokenRegistry(t *testing.T) {
+	// Setup - o registro dinâmico prevalece sobre API_KEY_<TOKEN>
+	coreLimiter := limiter.NewCoreLimiter(newMockStore())
+	cfg := &config.Config{
+		DefaultRateLimitIP:     1,
+		DefaultBlockDurationIP: 300,
+		Plans: map[string]config.TokenLimit{
+			"pro": {Limit: 4, BlockDurationSecs: 300},
+		},
+		TokenLimits: map[string]config.TokenLimit{
+			"static":  {Limit: 2, BlockDurationSecs: 300},
+			"dynamic": {Limit: 2, BlockDurationSecs: 300},
+		},
+	}
+	tokens := staticRegistry{
+		"dynamic": {Limit: 3, BlockDurationSecs: 300},
+		"planned": {Plan: "pro"},
+	}
+	handler := RateLimitMiddleware(coreLimiter, cfg, WithTokenRegistry(tokens))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	allowed := func(token string) int {
+		count := 0
+		for i := 0; i < 5; i++ {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("API_KEY", token)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			if w.Code == http.StatusOK {
+				count++
+			}
+		}
+		return count
+	}
+
+	// Execute / Assert
+	if got := allowed("static"); got != 2 {
+		t.Errorf("Token estático: esperado 2 permitidas, obtido %d", got)
+	}
+	if got := allowed("dynamic"); got != 3 {
+		t.Errorf("Token do registro: esperado 3 permitidas, obtido %d", got)
+	}
+	if got := allowed("planned"); got != 4 {
+		t.Errorf("Token do registro com plano: esperado 4 permitidas, obtido %d", got)
+	}
+	if rules, _ := KeyRules(cfg, tokens, "token:dynamic"); len(rules) != 1 || rules[0].Limit != 3 {
+		t.Errorf("KeyRules deveria consultar o registro: %+v", rules)
+	}
+}
+
+func TestRateLimitMiddleware_Escalation(t *testing.T) {
+	// Setup - bloqueios progressivos por IP e por token
+	store := limiter.NewMemoryStore()
+	coreLimiter := limiter.NewCoreLimiter(store)
+	defer coreLimiter.Close()
+	cfg := &config.Config{
+		DefaultRateLimitIP:       1,
+		DefaultBlockDurationIP:   10,
+		DefaultBlockEscalationIP: []time.Duration{time.Minute, 5 * time.Minute},
+		EscalationLookback:       time.Hour,
+		TokenLimits: map[string]config.TokenLimit{
+			"repeat": {Limit: 1, BlockDurationSecs: 10, Escalation: []time.Duration{time.Hour}},
+		},
+	}
+	handler := RateLimitMiddleware(coreLimiter, cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	// trip envia requisições até a primeira negada e retorna seu Retry-After
+	trip := func(token string) string {
+		for i := 0; i < 5; i++ {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = "192.168.1.7:12345"
+			if token != "" {
+				req.Header.Set("API_KEY", token)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			if w.Code == http.StatusTooManyRequests {
+				return w.Header().Get("Retry-After")
+			}
+		}
+		t.Fatal("Limite deveria ter sido excedido")
+		return ""
+	}
+
+	// Execute
+	first := trip("")
+	coreLimiter.Unblock(context.Background(), "ip:192.168.1.7")
+	second := trip("")
+	token := trip("repeat")
+
+	// Assert
+	if first != "60" || second != "300" {
+		t.Errorf("Retry-After por IP esperado 60 e 300, obtido %s e %s", first, second)
+	}
+	if token != "3600" {
+		t.Errorf("Retry-After do token esperado 3600, obtido %s", token)
+	}
+}
+
+func TestRateLimitMiddleware_StoreFailure(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy limiter.FailurePolicy
+		want   []int
+	}{
+		{"open", limiter.FailOpen, []int{200, 200, 200, 200}},
+		{"closed", limiter.FailClosed, []int{503, 503, 503, 503}},
+		// O limite de 4 req/s reduzido a 50% no limiter local
+		{"local", limiter.FailLocal, []int{200, 200, 429, 429}},
+	}
 
-	if ip != "203.0.113.1" {
-		t.Errorf("X-Forwarded-For deveria ter prioridade, obtido '%s'", ip)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup - store indisponível
+			store := newMockStore()
+			store.shouldFail = true
+			coreLimiter := limiter.NewCoreLimiter(store)
+			defer coreLimiter.Close()
+			if err := coreLimiter.SetFailurePolicy(tt.policy, 50); err != nil {
+				t.Fatalf("Erro inesperado: %v", err)
+			}
+			cfg := &config.Config{DefaultRateLimitIP: 4, DefaultBlockDurationIP: 10}
+			handler := RateLimitMiddleware(coreLimiter, cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			for i, want := range tt.want {
+				// Execute
+				req := httptest.NewRequest("GET", "/", nil)
+				req.RemoteAddr = "192.168.1.8:12345"
+				w := httptest.NewRecorder()
+				handler.ServeHTTP(w, req)
+
+				// Assert
+				if w.Code != want {
+					t.Errorf("Requisição %d: esperado status %d, obtido %d", i+1, want, w.Code)
+				}
+				if w.Code == http.StatusTooManyRequests && w.Header().Get("Retry-After") != "10" {
+					t.Errorf("Requisição %d: Retry-After esperado 10, obtido %q", i+1, w.Header().Get("Retry-After"))
+				}
+			}
+		})
 	}
 }
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"net/http"
+	"path"
+
+	"github.com/marfebr/go_ratelimit/internal/config"
+)
+
+// routeMatcher verifica se uma requisição casa com um padrão de rota
+type routeMatcher struct {
+	pattern config.RoutePattern
+	// mux resolve padrões no estilo do http.ServeMux (nil para globs)
+	mux *http.ServeMux
+}
+
+func newRouteMatcher(pattern config.RoutePattern) routeMatcher {
+	m := routeMatcher{pattern: pattern}
+	if !pattern.IsGlob() {
+		m.mux = http.NewServeMux()
+		m.mux.Handle(pattern.String(), http.NotFoundHandler())
+	}
+	return m
+}
+
+func (m routeMatcher) match(r *http.Request) bool {
+	if m.mux != nil {
+		_, pattern := m.mux.Handler(r)
+		return pattern != ""
+	}
+
+	// Como no ServeMux, GET também vale para HEAD
+	if m.pattern.Method != "" && m.pattern.Method != r.Method && !(m.pattern.Method == http.MethodGet && r.Method == http.MethodHead) {
+		return false
+	}
+	ok, _ := path.Match(m.pattern.Pattern, r.URL.Path)
+	return ok
+}
+
+// routeRule é um limite por rota pronto para uso pelo middleware
+type routeRule struct {
+	matcher routeMatcher
+	limit   config.RouteLimit
+	// extractor identifica os clientes da rota (nil: a chave padrão)
+	extractor KeyExtractor
+}
+
+// routeTable reúne as exclusões e os limites por rota da configuração
+type routeTable struct {
+	excluded []routeMatcher
+	rules    []routeRule
+}
+
+func newRouteTable(cfg *config.Config) routeTable {
+	var table routeTable
+	for _, pattern := range cfg.RateLimitExclude {
+		table.excluded = append(table.excluded, newRouteMatcher(pattern))
+	}
+	for _, limit := range cfg.RouteLimits {
+		rule := routeRule{matcher: newRouteMatcher(limit.RoutePattern), limit: limit}
+		if len(limit.KeySource) > 0 {
+			rule.extractor = NewKeyExtractor(limit.KeySource, cfg, true)
+		}
+		table.rules = append(table.rules, rule)
+	}
+	return table
+}
+
+// isExcluded informa se a requisição não passa pelo rate limiting
+func (t routeTable) isExcluded(r *http.Request) bool {
+	for _, m := range t.excluded {
+		if m.match(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// find retorna a primeira regra por rota que casa com a requisição
+func (t routeTable) find(r *http.Request) (routeRule, bool) {
+	for _, rule := range t.rules {
+		if rule.matcher.match(r) {
+			return rule, true
+		}
+	}
+	return routeRule{}, false
+}
+
+// routeKey separa o orçamento da rota do orçamento geral do cliente
+func routeKey(pattern config.RoutePattern, clientKey string) string {
+	return "route:" + pattern.String() + ":" + clientKey
+}
@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/marfebr/go_ratelimit/internal/config"
+	"github.com/marfebr/go_ratelimit/internal/limiter"
+)
+
+func mustRoutePattern(t *testing.T, value string) config.RoutePattern {
+	t.Helper()
+	pattern, err := config.ParseRoutePattern(value)
+	if err != nil {
+		t.Fatalf("Erro ao interpretar padrão %q: %v", value, err)
+	}
+	return pattern
+}
+
+func TestRouteMatcher(t *testing.T) {
+	tests := []struct {
+		pattern, method, path string
+		want                  bool
+	}{
+		{"/login", "POST", "/login", true},
+		{"/login", "POST", "/login/extra", false},
+		{"POST /login", "GET", "/login", false},
+		{"/api/", "GET", "/api/orders/1", true},
+		{"/api/", "GET", "/apiary", false},
+		{"GET /users/{id}", "GET", "/users/42", true},
+		{"GET /users/{id}", "HEAD", "/users/42", true},
+		{"GET /users/{id}", "GET", "/users/42/orders", false},
+		{"/files/*.zip", "GET", "/files/backup.zip", true},
+		{"/files/*.zip", "GET", "/files/a/backup.zip", false},
+		{"PUT /files/*.zip", "GET", "/files/backup.zip", false},
+	}
+
+	for _, tt := range tests {
+		// Setup
+		matcher := newRouteMatcher(mustRoutePattern(t, tt.pattern))
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+
+		// Execute / Assert
+		if got := matcher.match(req); got != tt.want {
+			t.Errorf("%s casando com %s %s: esperado %v, obtido %v", tt.pattern, tt.method, tt.path, tt.want, got)
+		}
+	}
+}
+
+func TestRateLimitMiddleware_RouteLimits(t *testing.T) {
+	// Setup - limite geral de 10 req/s, /login com 1 req/s e /health excluída
+	routes, err := config.ParseRouteLimits("POST /login=1,300")
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	coreLimiter := limiter.NewCoreLimiter(newMockStore())
+	cfg := &config.Config{
+		DefaultRateLimitIP:     10,
+		DefaultBlockDurationIP: 300,
+		TokenLimits:            make(map[string]config.TokenLimit),
+		RouteLimits:            routes,
+		RateLimitExclude:       []config.RoutePattern{mustRoutePattern(t, "/health")},
+	}
+	handler := RateLimitMiddleware(coreLimiter, cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	send := func(method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.RemoteAddr = "192.168.1.1:12345"
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	// Execute / Assert - a rota de login tem limite próprio
+	if w := send("POST", "/login"); w.Code != http.StatusOK {
+		t.Errorf("Primeiro login deveria ser permitido, status %d", w.Code)
+	}
+	if w := send("POST", "/login"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("Segundo login deveria ser bloqueado, status %d", w.Code)
+	}
+
+	// O bloqueio do login não afeta as demais rotas do cliente
+	if w := send("GET", "/"); w.Code != http.StatusOK {
+		t.Errorf("Rota geral deveria ser permitida, status %d", w.Code)
+	}
+
+	// Rotas excluídas não são limitadas nem recebem headers
+	for i := 0; i < 20; i++ {
+		w := send("GET", "/health")
+		if w.Code != http.StatusOK || w.Header().Get("X-RateLimit-Limit") != "" {
+			t.Fatalf("Health check não deveria ser limitado (requisição %d, status %d)", i+1, w.Code)
+		}
+	}
+
+	// A API administrativa encontra as regras da rota pela chave
+	if rules, _ := KeyRules(cfg, nil, "route:POST /login:ip:192.168.1.1"); len(rules) != 1 || rules[0].Limit != 1 {
+		t.Errorf("Regras da rota inesperadas: %+v", rules)
+	}
+}
@@ -0,0 +1,64 @@
+package registry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	tcredis "github.com/testcontainers/testcontainers-go/modules/redis"
+)
+
+func TestRedisStore_Integration_Notifications(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Pulando teste de integração em modo short")
+	}
+
+	// Setup
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	redisContainer, err := tcredis.Run(ctx, "redis:7-alpine")
+	if err != nil {
+		t.Fatalf("Erro ao iniciar container Redis: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := redisContainer.Terminate(context.Background()); err != nil {
+			t.Logf("Erro ao terminar container Redis: %v", err)
+		}
+	})
+	endpoint, err := redisContainer.Endpoint(ctx, "")
+	if err != nil {
+		t.Fatalf("Erro ao obter endpoint do Redis: %v", err)
+	}
+
+	// Duas instâncias com clientes próprios
+	writer := newTestRegistry(NewRedisStore(redis.NewClient(&redis.Options{Addr: endpoint})))
+	reader := newTestRegistry(NewRedisStore(redis.NewClient(&redis.Options{Addr: endpoint})))
+	go reader.Watch(ctx)
+	time.Sleep(200 * time.Millisecond)
+
+	// Execute
+	if err := writer.Set(ctx, "abc123", "10/1m,60"); err != nil {
+		t.Fatalf("Erro ao gravar token: %v", err)
+	}
+
+	// Assert
+	waitFor(t, func() bool { _, exists := reader.Get("abc123"); return exists })
+
+	if _, err := writer.Delete(ctx, "abc123"); err != nil {
+		t.Fatalf("Erro ao remover token: %v", err)
+	}
+	waitFor(t, func() bool { _, exists := reader.Get("abc123"); return !exists })
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("Condição não satisfeita a tempo")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
@@ -0,0 +1,93 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// DefaultHashKey é o hash do Redis com os tokens (campo: token, valor: limites)
+	DefaultHashKey = "rl:tokens"
+	// DefaultChannel é o canal pub/sub que notifica os tokens alterados
+	DefaultChannel = "rl:tokens:changed"
+)
+
+// RedisStore implementa Store em um hash do Redis, com notificações via pub/sub
+type RedisStore struct {
+	client  *redis.Client
+	hashKey string
+	channel string
+}
+
+var _ Store = (*RedisStore)(nil)
+
+// NewRedisStore cria um store de tokens sobre o cliente Redis informado
+func NewRedisStore(client *redis.Client) *RedisStore {
+	return &RedisStore{client: client, hashKey: DefaultHashKey, channel: DefaultChannel}
+}
+
+func (r *RedisStore) All(ctx context.Context) (map[string]string, error) {
+	return r.client.HGetAll(ctx, r.hashKey).Result()
+}
+
+func (r *RedisStore) Get(ctx context.Context, token string) (string, bool, error) {
+	value, err := r.client.HGet(ctx, r.hashKey, token).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return value, true, nil
+}
+
+// Set grava o token e publica a alteração na mesma transação
+func (r *RedisStore) Set(ctx context.Context, token, value string) error {
+	pipe := r.client.TxPipeline()
+	pipe.HSet(ctx, r.hashKey, token, value)
+	pipe.Publish(ctx, r.channel, token)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Delete remove o token e publica a alteração na mesma transação
+func (r *RedisStore) Delete(ctx context.Context, token string) (bool, error) {
+	pipe := r.client.TxPipeline()
+	deleted := pipe.HDel(ctx, r.hashKey, token)
+	pipe.Publish(ctx, r.channel, token)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false, err
+	}
+	return deleted.Val() > 0, nil
+}
+
+// Subscribe escuta o canal de alterações. A cada (re)inscrição, inclusive após
+// uma reconexão em que notificações podem ter sido perdidas, pede a recarga completa.
+func (r *RedisStore) Subscribe(ctx context.Context, onChange func(token string)) error {
+	pubsub := r.client.Subscribe(ctx, r.channel)
+	defer pubsub.Close()
+
+	for {
+		msg, err := pubsub.Receive(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			// Conexão perdida: o próximo Receive reconecta e se inscreve novamente
+			time.Sleep(time.Second)
+			continue
+		}
+
+		switch m := msg.(type) {
+		case *redis.Subscription:
+			if m.Kind == "subscribe" {
+				onChange("")
+			}
+		case *redis.Message:
+			onChange(m.Payload)
+		}
+	}
+}
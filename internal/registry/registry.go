@@ -0,0 +1,220 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"sync"
+
+	"github.com/marfebr/go_ratelimit/internal/config"
+)
+
+// ErrInvalidLimit indica um valor de token inválido ou que referencia um plano inexistente
+var ErrInvalidLimit = errors.New("limite inválido")
+
+// Store persiste os limites dinâmicos por token e notifica alterações entre instâncias
+// Os valores usam o formato de API_KEY_<TOKEN> (ex.: 100/1m,60 ou plan=pro,burst=200)
+type Store interface {
+	// All retorna todos os tokens registrados
+	All(ctx context.Context) (map[string]string, error)
+	// Get retorna o valor de um token
+	Get(ctx context.Context, token string) (string, bool, error)
+	// Set grava o valor do token e notifica as demais instâncias
+	Set(ctx context.Context, token, value string) error
+	// Delete remove o token e notifica as demais instâncias; retorna false se não existia
+	Delete(ctx context.Context, token string) (bool, error)
+	// Subscribe chama onChange a cada token alterado em qualquer instância, até ctx ser
+	// cancelado. Token vazio indica que todos devem ser recarregados (ex.: após reconexão).
+	Subscribe(ctx context.Context, onChange func(token string)) error
+}
+
+// entry é um token do registro com o valor original e os limites interpretados
+type entry struct {
+	value string
+	limit config.TokenLimit
+}
+
+// Registry mantém em cache local os limites dinâmicos por token, consultados pelo
+// middleware antes da configuração estática. O cache é atualizado pelas notificações do store.
+type Registry struct {
+	store Store
+	// config retorna a configuração ativa, usada para validar planos
+	config func() *config.Config
+
+	mu      sync.RWMutex
+	entries map[string]entry
+}
+
+// New cria um registro sobre o store; config fornece a configuração ativa
+func New(store Store, config func() *config.Config) *Registry {
+	return &Registry{
+		store:   store,
+		config:  config,
+		entries: make(map[string]entry),
+	}
+}
+
+// Load carrega todos os tokens do store para o cache local
+// Valores inválidos no store são ignorados
+func (r *Registry) Load(ctx context.Context) error {
+	values, err := r.store.All(ctx)
+	if err != nil {
+		return fmt.Errorf("erro ao carregar tokens: %w", err)
+	}
+
+	entries := make(map[string]entry, len(values))
+	for token, value := range values {
+		if limit, err := config.ParseTokenEntry(value); err == nil {
+			entries[token] = entry{value: value, limit: limit}
+		}
+	}
+
+	r.mu.Lock()
+	r.entries = entries
+	r.mu.Unlock()
+	return nil
+}
+
+// Watch mantém o cache atualizado com as alterações feitas por outras instâncias,
+// até ctx ser cancelado
+func (r *Registry) Watch(ctx context.Context) error {
+	return r.store.Subscribe(ctx, func(token string) {
+		if token == "" {
+			r.Load(ctx)
+			return
+		}
+		r.refresh(ctx, token)
+	})
+}
+
+// refresh relê um token do store
+func (r *Registry) refresh(ctx context.Context, token string) {
+	value, exists, err := r.store.Get(ctx, token)
+	if err != nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	limit, err := config.ParseTokenEntry(value)
+	if !exists || err != nil {
+		delete(r.entries, token)
+		return
+	}
+	r.entries[token] = entry{value: value, limit: limit}
+}
+
+// Get retorna os limites do token no cache local, sem consultar o store
+// Tokens com plano retornam a referência ao plano, resolvida pela configuração ativa
+func (r *Registry) Get(token string) (config.TokenLimit, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	e, exists := r.entries[token]
+	return e.limit, exists
+}
+
+// Value retorna o valor registrado do token no formato de API_KEY_<TOKEN>
+func (r *Registry) Value(token string) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	e, exists := r.entries[token]
+	return e.value, exists
+}
+
+// List retorna os valores de todos os tokens registrados
+func (r *Registry) List() map[string]string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	values := make(map[string]string, len(r.entries))
+	for token, e := range r.entries {
+		values[token] = e.value
+	}
+	return values
+}
+
+// Set valida e grava os limites do token, no formato de API_KEY_<TOKEN>
+func (r *Registry) Set(ctx context.Context, token, value string) error {
+	if token == "" {
+		return fmt.Errorf("%w: token vazio", ErrInvalidLimit)
+	}
+	limit, err := config.ParseTokenEntry(value)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidLimit, err)
+	}
+	if limit.Plan != "" {
+		if _, exists := r.config().Plans[limit.Plan]; !exists {
+			return fmt.Errorf("%w: plano desconhecido: %s", ErrInvalidLimit, limit.Plan)
+		}
+	}
+
+	if err := r.store.Set(ctx, token, value); err != nil {
+		return fmt.Errorf("erro ao gravar token: %w", err)
+	}
+
+	r.mu.Lock()
+	r.entries[token] = entry{value: value, limit: limit}
+	r.mu.Unlock()
+	return nil
+}
+
+// Delete remove o token do registro; retorna false se não existia
+func (r *Registry) Delete(ctx context.Context, token string) (bool, error) {
+	deleted, err := r.store.Delete(ctx, token)
+	if err != nil {
+		return false, fmt.Errorf("erro ao remover token: %w", err)
+	}
+
+	r.mu.Lock()
+	delete(r.entries, token)
+	r.mu.Unlock()
+	return deleted, nil
+}
+
+// MemoryStore implementa Store em memória local
+// Útil para testes e instâncias únicas; os tokens não são compartilhados entre réplicas
+type MemoryStore struct {
+	mu     sync.Mutex
+	values map[string]string
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+// NewMemoryStore cria um store de tokens em memória
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{values: make(map[string]string)}
+}
+
+func (m *MemoryStore) All(ctx context.Context) (map[string]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return maps.Clone(m.values), nil
+}
+
+func (m *MemoryStore) Get(ctx context.Context, token string) (string, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	value, exists := m.values[token]
+	return value, exists, nil
+}
+
+func (m *MemoryStore) Set(ctx context.Context, token, value string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.values[token] = value
+	return nil
+}
+
+func (m *MemoryStore) Delete(ctx context.Context, token string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, exists := m.values[token]
+	delete(m.values, token)
+	return exists, nil
+}
+
+// Subscribe aguarda o cancelamento de ctx: em memória não há outras instâncias
+func (m *MemoryStore) Subscribe(ctx context.Context, onChange func(token string)) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
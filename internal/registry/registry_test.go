@@ -0,0 +1,131 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/marfebr/go_ratelimit/internal/config"
+)
+
+func newTestRegistry(store Store) *Registry {
+	cfg := &config.Config{Plans: map[string]config.TokenLimit{"pro": {Limit: 100, BlockDurationSecs: 60}}}
+	return New(store, func() *config.Config { return cfg })
+}
+
+func TestRegistry_SetGetDelete(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	store := NewMemoryStore()
+	reg := newTestRegistry(store)
+
+	// Execute
+	if err := reg.Set(ctx, "abc123", "50/1m,30"); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	// Assert
+	limit, exists := reg.Get("abc123")
+	if !exists || limit.Limit != 50 || limit.Window != time.Minute || limit.BlockDurationSecs != 30 {
+		t.Errorf("Limite inesperado: %+v", limit)
+	}
+	if value, _, _ := store.Get(ctx, "abc123"); value != "50/1m,30" {
+		t.Errorf("Valor no store esperado '50/1m,30', obtido '%s'", value)
+	}
+
+	deleted, err := reg.Delete(ctx, "abc123")
+	if err != nil || !deleted {
+		t.Fatalf("Delete esperado true, obtido %v (%v)", deleted, err)
+	}
+	if _, exists := reg.Get("abc123"); exists {
+		t.Error("Token removido não deveria existir")
+	}
+	if deleted, _ := reg.Delete(ctx, "abc123"); deleted {
+		t.Error("Remover token inexistente deveria retornar false")
+	}
+}
+
+func TestRegistry_SetInvalid(t *testing.T) {
+	// Setup
+	reg := newTestRegistry(NewMemoryStore())
+
+	// Execute / Assert
+	for _, value := range []string{"abc", "10", "plan=gold"} {
+		err := reg.Set(context.Background(), "abc123", value)
+		if !errors.Is(err, ErrInvalidLimit) {
+			t.Errorf("Valor %q: esperado ErrInvalidLimit, obtido %v", value, err)
+		}
+	}
+	if _, exists := reg.Get("abc123"); exists {
+		t.Error("Valor inválido não deveria ser registrado")
+	}
+}
+
+func TestRegistry_Load(t *testing.T) {
+	// Setup: valores inválidos no store são ignorados
+	ctx := context.Background()
+	store := NewMemoryStore()
+	store.Set(ctx, "valid", "plan=pro,burst=10")
+	store.Set(ctx, "invalid", "abc")
+	reg := newTestRegistry(store)
+
+	// Execute
+	err := reg.Load(ctx)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	if limit, exists := reg.Get("valid"); !exists || limit.Plan != "pro" || limit.Overrides.Burst != 10 {
+		t.Errorf("Token valid inesperado: %+v", limit)
+	}
+	if _, exists := reg.Get("invalid"); exists {
+		t.Error("Token com valor inválido não deveria ser carregado")
+	}
+}
+
+// notifyingStore simula as notificações de outras instâncias
+type notifyingStore struct {
+	*MemoryStore
+	changes chan string
+}
+
+func (n *notifyingStore) Subscribe(ctx context.Context, onChange func(token string)) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case token := <-n.changes:
+			onChange(token)
+		}
+	}
+}
+
+func TestRegistry_Watch(t *testing.T) {
+	// Setup
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	store := &notifyingStore{MemoryStore: NewMemoryStore(), changes: make(chan string)}
+	reg := newTestRegistry(store)
+	go reg.Watch(ctx)
+
+	// Execute: outra instância grava e depois remove o token
+	store.MemoryStore.Set(ctx, "abc123", "10,60")
+	store.changes <- "abc123"
+	store.changes <- "sync" // a notificação anterior já foi processada
+	_, afterSet := reg.Get("abc123")
+
+	store.MemoryStore.Delete(ctx, "abc123")
+	store.changes <- "abc123"
+	store.changes <- "sync"
+	_, afterDelete := reg.Get("abc123")
+
+	// Assert
+	if !afterSet {
+		t.Error("Token gravado por outra instância deveria estar no cache")
+	}
+	if afterDelete {
+		t.Error("Token removido por outra instância não deveria estar no cache")
+	}
+}